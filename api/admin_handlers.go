@@ -0,0 +1,204 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// adminResolveOrg gets the org from the URL of an admin route
+// Unlike resolveOrg there is no API key to check it against
+func (h *Handler) adminResolveOrg(r *http.Request) (*domain.Organization, error) {
+	return h.service.GetOrganizationBySlug(mux.Vars(r)["org"])
+}
+
+// AdminCreateOrganization handles POST /admin/v1/orgs
+// It works even when public organization creation is disabled
+func (h *Handler) AdminCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateOrganizationRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	org, err := h.service.CreateOrganization(req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, org)
+}
+
+// AdminListOrganizations handles GET /admin/v1/orgs
+func (h *Handler) AdminListOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.service.ListOrganizations(domain.OrganizationListOptions{
+		Slug: r.URL.Query().Get("slug"),
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	if orgs == nil {
+		orgs = []*domain.Organization{}
+	}
+
+	h.writeJSON(w, http.StatusOK, orgs)
+}
+
+// AdminGetOrganization handles GET /admin/v1/orgs/{org}
+func (h *Handler) AdminGetOrganization(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, org)
+}
+
+// AdminUpdateOrganization handles PATCH /admin/v1/orgs/{org}
+func (h *Handler) AdminUpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.UpdateOrganizationRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	updated, err := h.service.UpdateOrganization(org.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// AdminDeleteOrganization handles DELETE /admin/v1/orgs/{org}
+// Organizations that still have projects cannot be deleted
+func (h *Handler) AdminDeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteOrganization(org.ID); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminResetAPIKeys handles POST /admin/v1/orgs/{org}/reset-api-keys
+// It revokes all keys of the org and returns a new full-access key
+func (h *Handler) AdminResetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	key, err := h.service.ResetAPIKeys(org.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, key)
+}
+
+// AdminGetResourceQuota handles GET /admin/v1/orgs/{org}/quotas
+func (h *Handler) AdminGetResourceQuota(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	usage, err := h.service.GetResourceQuotaUsage(org.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
+
+// AdminPutResourceQuota handles PUT /admin/v1/orgs/{org}/quotas
+func (h *Handler) AdminPutResourceQuota(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.ResourceQuota
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.PutResourceQuota(org.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// AdminDeleteResourceQuota handles DELETE /admin/v1/orgs/{org}/quotas
+// The org reverts to the server's default quota, which is returned
+func (h *Handler) AdminDeleteResourceQuota(w http.ResponseWriter, r *http.Request) {
+	org, err := h.adminResolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.ResetResourceQuota(org.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// AdminUpdateRegion handles PATCH /admin/v1/regions/{region}
+// Marking a region as down makes instance creation in it fail with 503
+func (h *Handler) AdminUpdateRegion(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateRegionRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	region, err := h.service.UpdateRegion(mux.Vars(r)["region"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, region)
+}
+
+// AdminGetStats handles GET /admin/v1/stats
+func (h *Handler) AdminGetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetGlobalStats()
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ListInstanceTypes handles GET /v1/instance-types
+// The catalog is the same for every org; ?region= lists the types offered in a region
+func (h *Handler) ListInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.service.ListInstanceTypes(r.URL.Query().Get("region"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, types)
+}
+
+// ListRegions handles GET /v1/regions
+func (h *Handler) ListRegions(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.service.ListRegions())
+}
+
+// GetRegion handles GET /v1/regions/{region}
+func (h *Handler) GetRegion(w http.ResponseWriter, r *http.Request) {
+	region, err := h.service.GetRegion(mux.Vars(r)["region"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, region)
+}
+
+// ListZones handles GET /v1/regions/{region}/zones
+func (h *Handler) ListZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.service.ListZones(mux.Vars(r)["region"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, zones)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CreateFirewallRule handles POST /v1/orgs/{org}/projects/{project}/firewall-rules
+func (h *Handler) CreateFirewallRule(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateFirewallRuleRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	rule, err := h.service.CreateFirewallRule(project.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, rule)
+}
+
+// ListFirewallRules handles GET /v1/orgs/{org}/projects/{project}/firewall-rules
+func (h *Handler) ListFirewallRules(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	rules, err := h.service.ListFirewallRules(project.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rules)
+}
+
+// GetFirewallRule handles GET /v1/orgs/{org}/projects/{project}/firewall-rules/{id}
+func (h *Handler) GetFirewallRule(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	rule, err := h.service.GetFirewallRule(project.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rule)
+}
+
+// UpdateFirewallRule handles PATCH /v1/orgs/{org}/projects/{project}/firewall-rules/{id}
+func (h *Handler) UpdateFirewallRule(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.UpdateFirewallRuleRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	rule, err := h.service.UpdateFirewallRule(project.ID, mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteFirewallRule handles DELETE /v1/orgs/{org}/projects/{project}/firewall-rules/{id}
+func (h *Handler) DeleteFirewallRule(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteFirewallRule(project.ID, mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -36,7 +36,7 @@ func (h *Handler) writeError(w http.ResponseWriter, err error) {
 	if domain.IsNotFound(err) {
 		status = http.StatusNotFound
 		message = err.Error()
-	} else if domain.IsAlreadyExists(err) {
+	} else if domain.IsAlreadyExists(err) || domain.IsConflict(err) {
 		status = http.StatusConflict
 		message = err.Error()
 	} else if domain.IsInvalidInput(err) {
@@ -48,6 +48,26 @@ func (h *Handler) writeError(w http.ResponseWriter, err error) {
 	} else if domain.IsUnauthorized(err) {
 		status = http.StatusUnauthorized
 		message = err.Error()
+	} else if domain.IsForbidden(err) {
+		status = http.StatusForbidden
+		message = err.Error()
+	} else if domain.IsPreconditionFailed(err) {
+		status = http.StatusPreconditionFailed
+		message = err.Error()
+	} else if domain.IsTooManyRequests(err) {
+		status = http.StatusTooManyRequests
+		message = err.Error()
+	} else if domain.IsServiceUnavailable(err) {
+		status = http.StatusServiceUnavailable
+		message = err.Error()
+	} else if domain.IsQuotaExceeded(err) {
+		// Running out of bytes is reported as 507 Insufficient Storage,
+		// any other quota (e.g. object count) as 403 Forbidden
+		status = http.StatusForbidden
+		if nahErr, ok := err.(*domain.NahError); ok && nahErr.Details["limit"] == domain.QuotaLimitMaxBytes {
+			status = http.StatusInsufficientStorage
+		}
+		message = err.Error()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -75,7 +95,7 @@ func (h *Handler) resolveOrg(r *http.Request) (*domain.Organization, error) {
 	// Verify the authenticated org (from token) matches the requested org
 	authOrg := OrgFromContext(r.Context())
 	if authOrg != nil && authOrg.ID != org.ID {
-		return nil, domain.UnauthorizedError("token does not have access to this organization")
+		return nil, domain.ForbiddenError("token does not have access to this organization", nil)
 	}
 
 	return org, nil
@@ -95,7 +115,49 @@ func (h *Handler) resolveProject(r *http.Request) (*domain.Project, error) {
 		return nil, domain.InvalidInputError("project slug is required", nil)
 	}
 
-	return h.service.GetProjectBySlug(org.ID, projectSlug)
+	project, err := h.service.GetProjectBySlug(org.ID, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys restricted to some projects cannot see the others
+	if key := APIKeyFromContext(r.Context()); key != nil && !key.AllowsProject(project.ID) {
+		return nil, domain.ForbiddenError("API key does not have access to this project", map[string]interface{}{
+			"project": project.Slug,
+		})
+	}
+
+	return project, nil
+}
+
+// resolveBucket gets org, project and bucket from URL and returns the bucket
+func (h *Handler) resolveBucket(r *http.Request) (*domain.Bucket, error) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := mux.Vars(r)
+	return h.service.GetBucketByName(project.ID, vars["bucket"])
+}
+
+// resolveObject gets org, project, bucket and object from URL and returns the object
+// Objects that exist but belong to a different bucket are reported as not found
+func (h *Handler) resolveObject(r *http.Request) (*domain.Object, error) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id := mux.Vars(r)["id"]
+	obj, err := h.service.GetObject(id)
+	if err != nil {
+		return nil, err
+	}
+	if obj.BucketID != bucket.ID {
+		return nil, domain.NotFoundError("object", id)
+	}
+	return obj, nil
 }
 
 // decodeJSON decodes JSON from the request body into the given value
@@ -110,6 +172,11 @@ func (h *Handler) decodeJSON(r *http.Request, v any) error {
 
 // CreateOrganization handles POST /v1/orgs
 func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	if !h.service.PublicOrgCreation() {
+		h.writeError(w, domain.ForbiddenError("organization creation is disabled, ask an administrator", nil))
+		return
+	}
+
 	var req domain.CreateOrganizationRequest
 	if err := h.decodeJSON(r, &req); err != nil {
 		h.writeError(w, err)
@@ -138,8 +205,6 @@ func (h *Handler) GetOrganization(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, org)
 }
 
-// TODO: Add admin controls for ListOrganizations, UpdateOrganization, DeleteOrganization
-
 // API Key handlers
 
 // CreateAPIKey handles POST /v1/orgs/{org}/api-keys
@@ -156,7 +221,7 @@ func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey, err := h.service.CreateAPIKey(org.ID, req)
+	apiKey, err := h.service.CreateAPIKey(org.ID, req, APIKeyFromContext(r.Context()))
 	if err != nil {
 		h.writeError(w, err)
 		return
@@ -193,7 +258,7 @@ func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	keyID := vars["key_id"]
 
-	if err := h.service.DeleteAPIKey(org.ID, keyID); err != nil {
+	if err := h.service.DeleteAPIKey(org.ID, keyID, APIKeyFromContext(r.Context())); err != nil {
 		h.writeError(w, err)
 		return
 	}
@@ -201,6 +266,58 @@ func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RotateAPIKey handles POST /v1/orgs/{org}/api-keys/{key_id}:rotate
+func (h *Handler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// The body is optional
+	var req domain.RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := h.decodeJSON(r, &req); err != nil {
+			h.writeError(w, err)
+			return
+		}
+	}
+
+	apiKey, err := h.service.RotateAPIKey(org.ID, mux.Vars(r)["key_id"], req, APIKeyFromContext(r.Context()))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, apiKey)
+}
+
+// CreateSessionToken handles POST /v1/orgs/{org}/session-tokens
+func (h *Handler) CreateSessionToken(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// The body is optional
+	var req domain.CreateSessionTokenRequest
+	if r.ContentLength != 0 {
+		if err := h.decodeJSON(r, &req); err != nil {
+			h.writeError(w, err)
+			return
+		}
+	}
+
+	token, err := h.service.CreateSessionToken(org.ID, req, APIKeyFromContext(r.Context()))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, token)
+}
+
 // Project handlers
 
 // CreateProject handles POST /v1/orgs/{org}/projects
@@ -211,6 +328,12 @@ func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A new project would be outside the projects a restricted key can use
+	if key := APIKeyFromContext(r.Context()); key != nil && len(key.ProjectIDs) > 0 {
+		h.writeError(w, domain.ForbiddenError("API key restricted to projects cannot create projects", nil))
+		return
+	}
+
 	var req domain.CreateProjectRequest
 	if err := h.decodeJSON(r, &req); err != nil {
 		h.writeError(w, err)
@@ -260,6 +383,16 @@ func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if key := APIKeyFromContext(r.Context()); key != nil && len(key.ProjectIDs) > 0 {
+		allowed := []*domain.Project{}
+		for _, project := range projects {
+			if key.AllowsProject(project.ID) {
+				allowed = append(allowed, project)
+			}
+		}
+		projects = allowed
+	}
+
 	h.writeJSON(w, http.StatusOK, projects)
 }
 
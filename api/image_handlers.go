@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CreateImage handles POST /v1/orgs/{org}/images
+func (h *Handler) CreateImage(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateImageRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	image, err := h.service.CreateImage(org.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, image)
+}
+
+// ListImages handles GET /v1/orgs/{org}/images
+// Lists the public images and the org's private images, of one ?family= if set
+func (h *Handler) ListImages(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	images, err := h.service.ListImages(domain.ImageListOptions{
+		OrgID:  org.ID,
+		Family: r.URL.Query().Get("family"),
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, images)
+}
+
+// GetImage handles GET /v1/orgs/{org}/images/{image}
+func (h *Handler) GetImage(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	image, err := h.service.GetImage(org.ID, mux.Vars(r)["image"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, image)
+}
+
+// DeprecateImage handles POST /v1/orgs/{org}/images/{image}:deprecate
+func (h *Handler) DeprecateImage(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	image, err := h.service.DeprecateImage(org.ID, mux.Vars(r)["image"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, image)
+}
+
+// DeleteImage handles DELETE /v1/orgs/{org}/images/{image}
+func (h *Handler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteImage(org.ID, mux.Vars(r)["image"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
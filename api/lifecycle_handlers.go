@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// GetBucketLifecycle handles GET /v1/orgs/{org}/projects/{project}/buckets/{bucket}/lifecycle
+func (h *Handler) GetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	lifecycle, err := h.service.GetBucketLifecycle(bucket.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, lifecycle)
+}
+
+// PutBucketLifecycle handles PUT /v1/orgs/{org}/projects/{project}/buckets/{bucket}/lifecycle
+func (h *Handler) PutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.BucketLifecycle
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	lifecycle, err := h.service.PutBucketLifecycle(bucket.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, lifecycle)
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hypertf/nahcloud/domain"
 	"github.com/hypertf/nahcloud/service"
@@ -14,6 +17,8 @@ type contextKey string
 const (
 	// ContextKeyOrg is the context key for the authenticated organization
 	ContextKeyOrg contextKey = "org"
+	// ContextKeyAPIKey is the context key for the API key the request authenticated with
+	ContextKeyAPIKey contextKey = "api_key"
 )
 
 // OrgFromContext retrieves the authenticated organization from the request context
@@ -22,6 +27,12 @@ func OrgFromContext(ctx context.Context) *domain.Organization {
 	return org
 }
 
+// APIKeyFromContext retrieves the API key the request authenticated with from the request context
+func APIKeyFromContext(ctx context.Context) *domain.APIKey {
+	key, _ := ctx.Value(ContextKeyAPIKey).(*domain.APIKey)
+	return key
+}
+
 // AuthMiddleware creates middleware that validates org tokens for API routes
 func AuthMiddleware(svc *service.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -32,17 +43,37 @@ func AuthMiddleware(svc *service.Service) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Terraform's HTTP state backend can only send Basic auth, so the API key is also accepted as the password
+			var token string
 			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+				token = parts[1]
+			} else if _, password, ok := r.BasicAuth(); ok {
+				token = password
+			} else {
 				writeAuthError(w, "invalid authorization header format")
 				return
 			}
 
-			token := parts[1]
-			org, err := svc.GetOrganizationByToken(token)
+			// Session tokens carry their org and access, so they are verified without a database lookup
+			var apiKey *domain.APIKey
+			var org *domain.Organization
+			var err error
+			if service.IsSessionToken(token) {
+				apiKey, err = svc.AuthenticateSessionToken(token)
+				if err == nil {
+					org = &domain.Organization{ID: apiKey.OrgID}
+				}
+			} else {
+				apiKey, org, err = svc.AuthenticateAPIKey(token)
+			}
 			if err != nil {
-				if domain.IsNotFound(err) || domain.IsUnauthorized(err) {
-					writeAuthError(w, "invalid token")
+				if domain.IsUnauthorized(err) || domain.IsNotFound(err) {
+					message := "invalid token"
+					if nahErr, ok := err.(*domain.NahError); ok && domain.IsUnauthorized(err) {
+						message = nahErr.Message
+					}
+					writeAuthError(w, message)
 					return
 				}
 				writeServerError(w)
@@ -50,11 +81,92 @@ func AuthMiddleware(svc *service.Service) func(http.Handler) http.Handler {
 			}
 
 			ctx := context.WithValue(r.Context(), ContextKeyOrg, org)
+			ctx = context.WithValue(ctx, ContextKeyAPIKey, apiKey)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// RateLimitMiddleware creates middleware that enforces the rate limits of the authenticated
+// org and API key (it must run after AuthMiddleware). Responses report the most restrictive
+// limit in X-RateLimit-* headers; requests over a limit get 429 with Retry-After
+func RateLimitMiddleware(svc *service.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var orgID, keyID string
+			if org := OrgFromContext(r.Context()); org != nil {
+				orgID = org.ID
+			}
+			if key := APIKeyFromContext(r.Context()); key != nil {
+				keyID = key.ID
+			}
+
+			result, err := svc.CheckRateLimit(orgID, keyID)
+			if result.Limit > 0 {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+				w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			}
+			if err != nil {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// AdminAuthMiddleware creates middleware that validates the admin token for admin API routes
+func AdminAuthMiddleware(svc *service.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+				writeAuthError(w, "missing admin token")
+				return
+			}
+
+			if err := svc.AuthenticateAdmin(parts[1]); err != nil {
+				writeAuthError(w, "invalid admin token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope wraps a handler so that it is only called for API keys granting scope
+// Requests without an API key in the context (not authenticated by AuthMiddleware) pass through
+func (h *Handler) RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := checkScope(r, scope); err != nil {
+			h.writeError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// checkScope returns a forbidden error if the request's API key does not grant scope
+func checkScope(r *http.Request, scope string) error {
+	if key := APIKeyFromContext(r.Context()); key != nil && !key.HasScope(scope) {
+		return domain.ForbiddenError("API key is missing scope "+scope, map[string]interface{}{
+			"scope": scope,
+		})
+	}
+	return nil
+}
+
 func writeAuthError(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CreateNetwork handles POST /v1/orgs/{org}/projects/{project}/networks
+func (h *Handler) CreateNetwork(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateNetworkRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	network, err := h.service.CreateNetwork(project.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, network)
+}
+
+// ListNetworks handles GET /v1/orgs/{org}/projects/{project}/networks
+func (h *Handler) ListNetworks(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	networks, err := h.service.ListNetworks(project.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, networks)
+}
+
+// GetNetwork handles GET /v1/orgs/{org}/projects/{project}/networks/{id}
+func (h *Handler) GetNetwork(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	network, err := h.service.GetNetwork(project.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, network)
+}
+
+// UpdateNetwork handles PATCH /v1/orgs/{org}/projects/{project}/networks/{id}
+func (h *Handler) UpdateNetwork(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.UpdateNetworkRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	network, err := h.service.UpdateNetwork(project.ID, mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, network)
+}
+
+// DeleteNetwork handles DELETE /v1/orgs/{org}/projects/{project}/networks/{id}
+func (h *Handler) DeleteNetwork(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteNetwork(project.ID, mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateSubnet handles POST /v1/orgs/{org}/projects/{project}/subnets
+func (h *Handler) CreateSubnet(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateSubnetRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	subnet, err := h.service.CreateSubnet(project.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, subnet)
+}
+
+// ListSubnets handles GET /v1/orgs/{org}/projects/{project}/subnets
+// Lists the subnets of one ?network_id= if set
+func (h *Handler) ListSubnets(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	subnets, err := h.service.ListSubnets(domain.SubnetListOptions{
+		ProjectID: project.ID,
+		NetworkID: r.URL.Query().Get("network_id"),
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, subnets)
+}
+
+// GetSubnet handles GET /v1/orgs/{org}/projects/{project}/subnets/{id}
+func (h *Handler) GetSubnet(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	subnet, err := h.service.GetSubnet(project.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, subnet)
+}
+
+// UpdateSubnet handles PATCH /v1/orgs/{org}/projects/{project}/subnets/{id}
+func (h *Handler) UpdateSubnet(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.UpdateSubnetRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	subnet, err := h.service.UpdateSubnet(project.ID, mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, subnet)
+}
+
+// DeleteSubnet handles DELETE /v1/orgs/{org}/projects/{project}/subnets/{id}
+func (h *Handler) DeleteSubnet(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteSubnet(project.ID, mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// GetBucketNotifications handles GET /v1/orgs/{org}/projects/{project}/buckets/{bucket}/notifications
+func (h *Handler) GetBucketNotifications(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	notifications, err := h.service.GetBucketNotifications(bucket.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, notifications)
+}
+
+// PutBucketNotifications handles PUT /v1/orgs/{org}/projects/{project}/buckets/{bucket}/notifications
+func (h *Handler) PutBucketNotifications(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.BucketNotifications
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	notifications, err := h.service.PutBucketNotifications(bucket.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, notifications)
+}
+
+// ListNotificationDeliveries handles GET /v1/orgs/{org}/projects/{project}/buckets/{bucket}/notifications/deliveries
+func (h *Handler) ListNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			h.writeError(w, domain.InvalidInputError("limit must be an integer", map[string]interface{}{"actual": v}))
+			return
+		}
+	}
+
+	deliveries, err := h.service.ListNotificationDeliveries(bucket.ID, limit)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CopyObject handles POST /v1/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}/copy
+func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
+	h.copyOrMoveObject(w, r, h.service.CopyObject)
+}
+
+// MoveObject handles POST /v1/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}/move
+func (h *Handler) MoveObject(w http.ResponseWriter, r *http.Request) {
+	h.copyOrMoveObject(w, r, h.service.MoveObject)
+}
+
+// copyOrMoveObject resolves the source object and applies op to it
+func (h *Handler) copyOrMoveObject(w http.ResponseWriter, r *http.Request, op func(orgID, objectID string, req domain.CopyObjectRequest) (*domain.Object, error)) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	src, err := h.resolveObject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CopyObjectRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// Keys restricted to some projects cannot write to the others
+	if key := APIKeyFromContext(r.Context()); key != nil && req.DestinationProject != "" {
+		dest, err := h.service.GetProjectBySlug(org.ID, req.DestinationProject)
+		if err == nil && !key.AllowsProject(dest.ID) {
+			err = domain.ForbiddenError("API key does not have access to the destination project", map[string]interface{}{
+				"project": dest.Slug,
+			})
+		}
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+	}
+
+	obj, err := op(org.ID, src.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, obj)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CreateOIDCTrustPolicy handles POST /v1/orgs/{org}/oidc-policies
+func (h *Handler) CreateOIDCTrustPolicy(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateOIDCTrustPolicyRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	policy, err := h.service.CreateOIDCTrustPolicy(org.ID, req, APIKeyFromContext(r.Context()))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, policy)
+}
+
+// ListOIDCTrustPolicies handles GET /v1/orgs/{org}/oidc-policies
+func (h *Handler) ListOIDCTrustPolicies(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	policies, err := h.service.ListOIDCTrustPolicies(org.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, policies)
+}
+
+// GetOIDCTrustPolicy handles GET /v1/orgs/{org}/oidc-policies/{policy_id}
+func (h *Handler) GetOIDCTrustPolicy(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	policy, err := h.service.GetOIDCTrustPolicy(org.ID, mux.Vars(r)["policy_id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, policy)
+}
+
+// DeleteOIDCTrustPolicy handles DELETE /v1/orgs/{org}/oidc-policies/{policy_id}
+func (h *Handler) DeleteOIDCTrustPolicy(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteOIDCTrustPolicy(org.ID, mux.Vars(r)["policy_id"], APIKeyFromContext(r.Context())); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExchangeOIDCToken handles POST /v1/orgs/{org}/oidc/token
+// The OIDC token in the body is the only credential, so this route is not authenticated
+func (h *Handler) ExchangeOIDCToken(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.OIDCTokenExchangeRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	apiKey, err := h.service.ExchangeOIDCToken(org.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, apiKey)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// maxPresignedUploadSize caps the body accepted by a presigned PUT
+const maxPresignedUploadSize = 32 << 20
+
+// PresignObject handles POST /v1/orgs/{org}/projects/{project}/buckets/{bucket}/presign
+func (h *Handler) PresignObject(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.PresignObjectRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// The route requires objects:read; an upload URL also needs objects:write
+	if strings.EqualFold(req.Method, http.MethodPut) {
+		if err := checkScope(r, domain.ScopeObjectsWrite); err != nil {
+			h.writeError(w, err)
+			return
+		}
+	}
+
+	presigned, err := h.service.PresignObject(bucket.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	presigned.URL = baseURL(r) + presigned.URL
+	h.writeJSON(w, http.StatusCreated, presigned)
+}
+
+// PresignedGet handles GET /v1/presigned/{token}
+// It returns the decoded object content without requiring an API key
+func (h *Handler) PresignedGet(w http.ResponseWriter, r *http.Request) {
+	obj, err := h.service.GetPresignedObject(mux.Vars(r)["token"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	content, err := base64.StdEncoding.DecodeString(obj.Content)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// PresignedPut handles PUT /v1/presigned/{token}
+// The raw request body becomes the object content
+func (h *Handler) PresignedPut(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPresignedUploadSize))
+	if err != nil {
+		h.writeError(w, domain.InvalidInputError("failed to read request body", map[string]interface{}{
+			"max_bytes": maxPresignedUploadSize,
+		}))
+		return
+	}
+
+	obj, created, err := h.service.PutPresignedObject(mux.Vars(r)["token"], body)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	h.writeJSON(w, status, obj)
+}
+
+// baseURL returns the scheme and host the request was made against
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
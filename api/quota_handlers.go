@@ -0,0 +1,150 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// GetBucketQuota handles GET /v1/orgs/{org}/projects/{project}/buckets/{bucket}/quota
+func (h *Handler) GetBucketQuota(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.GetBucketQuota(bucket.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// PutBucketQuota handles PUT /v1/orgs/{org}/projects/{project}/buckets/{bucket}/quota
+func (h *Handler) PutBucketQuota(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.StorageQuota
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.PutBucketQuota(bucket.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// GetBucketUsage handles GET /v1/orgs/{org}/projects/{project}/buckets/{bucket}/usage
+func (h *Handler) GetBucketUsage(w http.ResponseWriter, r *http.Request) {
+	bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	usage, err := h.service.GetBucketUsage(bucket.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
+
+// GetProjectStorageQuota handles GET /v1/orgs/{org}/projects/{project}/storage/quota
+func (h *Handler) GetProjectStorageQuota(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.GetProjectStorageQuota(project.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// PutProjectStorageQuota handles PUT /v1/orgs/{org}/projects/{project}/storage/quota
+func (h *Handler) PutProjectStorageQuota(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.StorageQuota
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	quota, err := h.service.PutProjectStorageQuota(project.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, quota)
+}
+
+// GetProjectStorageUsage handles GET /v1/orgs/{org}/projects/{project}/storage/usage
+func (h *Handler) GetProjectStorageUsage(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	usage, err := h.service.GetProjectStorageUsage(project.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
+
+// GetResourceQuota handles GET /v1/orgs/{org}/quotas
+// Quotas are set by administrators, so orgs can only read them
+func (h *Handler) GetResourceQuota(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	usage, err := h.service.GetResourceQuotaUsage(org.ID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// Project-restricted keys only see the usage of their projects
+	if key := APIKeyFromContext(r.Context()); key != nil && len(key.ProjectIDs) > 0 {
+		visible := []domain.ProjectInstanceUsage{}
+		for _, u := range usage.Usage {
+			if key.AllowsProject(u.ProjectID) {
+				visible = append(visible, u)
+			}
+		}
+		usage.Usage = visible
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
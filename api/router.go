@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
 	"github.com/hypertf/nahcloud/service"
 	"github.com/hypertf/nahcloud/web"
 )
@@ -46,105 +47,229 @@ func SetupRouter(handler *Handler, svc *service.Service, version string) *mux.Ro
 	// Static assets
 	webRouter.HandleFunc("/static/logo.png", webHandler.ServeLogo).Methods("GET")
 
-	// Dashboard (redirects to default org)
+	// Sign in / sign out
+	webRouter.HandleFunc("/login", webHandler.LoginPage).Methods("GET")
+	webRouter.HandleFunc("/login", webHandler.Login).Methods("POST")
+	webRouter.HandleFunc("/logout", webHandler.Logout).Methods("POST")
+
+	// Dashboard (redirects to the signed-in org, or to /login)
 	webRouter.HandleFunc("", webHandler.Dashboard).Methods("GET")
 	webRouter.HandleFunc("/", webHandler.Dashboard).Methods("GET")
 
-	// Org-scoped web routes
+	// Org-scoped web routes (require a session for the org, and a CSRF token for writes)
+	orgRouter := webRouter.PathPrefix("/org/{org}").Subrouter()
+	orgRouter.Use(webHandler.RequireSession)
+
+	// Org dashboard
+	orgRouter.HandleFunc("", webHandler.OrgDashboard).Methods("GET")
+	orgRouter.HandleFunc("/", webHandler.OrgDashboard).Methods("GET")
+
 	// Projects list
-	webRouter.HandleFunc("/org/{org}/projects", webHandler.ListProjects).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects", webHandler.CreateProject).Methods("POST")
-	webRouter.HandleFunc("/org/{org}/projects/new", webHandler.NewProjectForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/edit", webHandler.EditProjectForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}", webHandler.UpdateProject).Methods("PUT")
-	webRouter.HandleFunc("/org/{org}/projects/{project}", webHandler.DeleteProject).Methods("DELETE")
+	orgRouter.HandleFunc("/projects", webHandler.ListProjects).Methods("GET")
+	orgRouter.HandleFunc("/projects", webHandler.CreateProject).Methods("POST")
+	orgRouter.HandleFunc("/projects/new", webHandler.NewProjectForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/edit", webHandler.EditProjectForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}", webHandler.UpdateProject).Methods("PUT")
+	orgRouter.HandleFunc("/projects/{project}", webHandler.DeleteProject).Methods("DELETE")
 
 	// Instances (scoped to project)
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances", webHandler.ListInstances).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances", webHandler.CreateInstance).Methods("POST")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances/new", webHandler.NewInstanceForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances/{id}/edit", webHandler.EditInstanceForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances/{id}", webHandler.UpdateInstance).Methods("PUT")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/instances/{id}", webHandler.DeleteInstance).Methods("DELETE")
+	orgRouter.HandleFunc("/projects/{project}/instances", webHandler.ListInstances).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/instances", webHandler.CreateInstance).Methods("POST")
+	orgRouter.HandleFunc("/projects/{project}/instances/new", webHandler.NewInstanceForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/instances/{id}/edit", webHandler.EditInstanceForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/instances/{id}", webHandler.UpdateInstance).Methods("PUT")
+	orgRouter.HandleFunc("/projects/{project}/instances/{id}", webHandler.DeleteInstance).Methods("DELETE")
 
 	// Storage (scoped to project)
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage", webHandler.ListStorage).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/buckets/new", webHandler.NewBucketForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/buckets", webHandler.CreateBucket).Methods("POST")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/{bucket}", webHandler.ListBucketObjects).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/{bucket}/objects/new", webHandler.NewObjectForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/{bucket}/objects", webHandler.CreateObject).Methods("POST")
-	webRouter.HandleFunc("/org/{org}/projects/{project}/storage/{bucket}/objects/{objid}", webHandler.ViewObject).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage", webHandler.ListStorage).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/buckets/new", webHandler.NewBucketForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/buckets", webHandler.CreateBucket).Methods("POST")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}", webHandler.ListBucketObjects).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/edit", webHandler.EditBucketForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}", webHandler.UpdateBucket).Methods("PUT")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}", webHandler.DeleteBucket).Methods("DELETE")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/objects/new", webHandler.NewObjectForm).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/objects", webHandler.CreateObject).Methods("POST")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/objects/{objid}", webHandler.ViewObject).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/objects/{objid}/download", webHandler.DownloadObject).Methods("GET")
+	orgRouter.HandleFunc("/projects/{project}/storage/{bucket}/objects/{objid}", webHandler.DeleteObject).Methods("DELETE")
 
 	// Metadata (scoped to org)
-	webRouter.HandleFunc("/org/{org}/metadata", webHandler.ListMetadata).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/metadata", webHandler.CreateMetadata).Methods("POST")
-	webRouter.HandleFunc("/org/{org}/metadata/new", webHandler.NewMetadataForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/metadata/edit", webHandler.EditMetadataForm).Methods("GET")
-	webRouter.HandleFunc("/org/{org}/metadata/update", webHandler.UpdateMetadata).Methods("PUT")
-	webRouter.HandleFunc("/org/{org}/metadata/delete", webHandler.DeleteMetadata).Methods("DELETE")
+	orgRouter.HandleFunc("/metadata", webHandler.ListMetadata).Methods("GET")
+	orgRouter.HandleFunc("/metadata", webHandler.CreateMetadata).Methods("POST")
+	orgRouter.HandleFunc("/metadata/new", webHandler.NewMetadataForm).Methods("GET")
+	orgRouter.HandleFunc("/metadata/edit", webHandler.EditMetadataForm).Methods("GET")
+	orgRouter.HandleFunc("/metadata/update", webHandler.UpdateMetadata).Methods("PUT")
+	orgRouter.HandleFunc("/metadata/delete", webHandler.DeleteMetadata).Methods("DELETE")
+	orgRouter.HandleFunc("/search", webHandler.Search).Methods("GET")
+	orgRouter.HandleFunc("/events", webHandler.Events).Methods("GET")
+	orgRouter.HandleFunc("/api-keys", webHandler.ListAPIKeys).Methods("GET")
+	orgRouter.HandleFunc("/api-keys/new", webHandler.NewAPIKeyForm).Methods("GET")
+	orgRouter.HandleFunc("/api-keys", webHandler.CreateAPIKey).Methods("POST")
+	orgRouter.HandleFunc("/api-keys/{key_id}", webHandler.DeleteAPIKey).Methods("DELETE")
+	orgRouter.HandleFunc("/api-keys/{key_id}/rotate", webHandler.RotateAPIKey).Methods("POST")
+	orgRouter.HandleFunc("/tfstate", webHandler.ListTFStates).Methods("GET")
+	orgRouter.HandleFunc("/tfstate/{id}", webHandler.ViewTFState).Methods("GET")
+	orgRouter.HandleFunc("/tfstate/{id}/download", webHandler.DownloadTFState).Methods("GET")
+	orgRouter.HandleFunc("/tfstate/{id}/unlock", webHandler.UnlockTFState).Methods("POST")
 
 	// API prefix
 	api := router.PathPrefix("/v1").Subrouter()
 
-	// Public routes (no auth required)
+	// Public routes (no auth required, org creation can be disabled)
 	api.HandleFunc("/orgs", handler.CreateOrganization).Methods("POST")
 
-	// Authenticated API routes (require org token)
+	// OIDC token exchange (authenticated by the OIDC token in the body)
+	api.HandleFunc("/orgs/{org}/oidc/token", handler.ExchangeOIDCToken).Methods("POST")
+
+	// Authenticated API routes (require org token, and the scope of each route for keys with scopes)
 	authAPI := api.PathPrefix("").Subrouter()
 	authAPI.Use(AuthMiddleware(svc))
+	authAPI.Use(RateLimitMiddleware(svc))
 
 	// Organization routes (authenticated)
-	// TODO: Add admin controls for listing/updating/deleting orgs
-	authAPI.HandleFunc("/orgs/{org}", handler.GetOrganization).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}", handler.RequireScope(domain.ScopeOrgRead, handler.GetOrganization)).Methods("GET")
+
+	authAPI.HandleFunc("/orgs/{org}/summary", handler.RequireScope(domain.ScopeOrgRead, handler.GetOrgSummary)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/quotas", handler.RequireScope(domain.ScopeOrgRead, handler.GetResourceQuota)).Methods("GET")
 
 	// API Key routes (scoped to org, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/api-keys", handler.CreateAPIKey).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/api-keys", handler.ListAPIKeys).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/api-keys/{key_id}", handler.DeleteAPIKey).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/api-keys", handler.RequireScope(domain.ScopeAPIKeysWrite, handler.CreateAPIKey)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/api-keys", handler.RequireScope(domain.ScopeAPIKeysRead, handler.ListAPIKeys)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/api-keys/{key_id}", handler.RequireScope(domain.ScopeAPIKeysWrite, handler.DeleteAPIKey)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/api-keys/{key_id:[^/:]+}:rotate", handler.RequireScope(domain.ScopeAPIKeysWrite, handler.RotateAPIKey)).Methods("POST")
+
+	// Session tokens need no scope: they never have more access than the key minting them
+	authAPI.HandleFunc("/orgs/{org}/session-tokens", handler.CreateSessionToken).Methods("POST")
+
+	// OIDC trust policy routes (scoped to org, authenticated; policies mint API keys)
+	authAPI.HandleFunc("/orgs/{org}/oidc-policies", handler.RequireScope(domain.ScopeAPIKeysWrite, handler.CreateOIDCTrustPolicy)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/oidc-policies", handler.RequireScope(domain.ScopeAPIKeysRead, handler.ListOIDCTrustPolicies)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/oidc-policies/{policy_id}", handler.RequireScope(domain.ScopeAPIKeysRead, handler.GetOIDCTrustPolicy)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/oidc-policies/{policy_id}", handler.RequireScope(domain.ScopeAPIKeysWrite, handler.DeleteOIDCTrustPolicy)).Methods("DELETE")
 
 	// Project routes (scoped to org, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/projects", handler.CreateProject).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/projects", handler.ListProjects).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.GetProject).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.UpdateProject).Methods("PATCH")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.DeleteProject).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects", handler.RequireScope(domain.ScopeProjectsWrite, handler.CreateProject)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects", handler.RequireScope(domain.ScopeProjectsRead, handler.ListProjects)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.RequireScope(domain.ScopeProjectsRead, handler.GetProject)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.RequireScope(domain.ScopeProjectsWrite, handler.UpdateProject)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}", handler.RequireScope(domain.ScopeProjectsWrite, handler.DeleteProject)).Methods("DELETE")
+
+	// Region and instance type catalog (shared by all orgs, authenticated)
+	authAPI.HandleFunc("/regions", handler.RequireScope(domain.ScopeInstancesRead, handler.ListRegions)).Methods("GET")
+	authAPI.HandleFunc("/regions/{region}", handler.RequireScope(domain.ScopeInstancesRead, handler.GetRegion)).Methods("GET")
+	authAPI.HandleFunc("/regions/{region}/zones", handler.RequireScope(domain.ScopeInstancesRead, handler.ListZones)).Methods("GET")
+	authAPI.HandleFunc("/instance-types", handler.RequireScope(domain.ScopeInstancesRead, handler.ListInstanceTypes)).Methods("GET")
+
+	// Image routes (public images and the org's private images, authenticated)
+	authAPI.HandleFunc("/orgs/{org}/images", handler.RequireScope(domain.ScopeInstancesWrite, handler.CreateImage)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/images", handler.RequireScope(domain.ScopeInstancesRead, handler.ListImages)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/images/{image:[^/:]+}", handler.RequireScope(domain.ScopeInstancesRead, handler.GetImage)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/images/{image:[^/:]+}", handler.RequireScope(domain.ScopeInstancesWrite, handler.DeleteImage)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/images/{image:[^/:]+}:deprecate", handler.RequireScope(domain.ScopeInstancesWrite, handler.DeprecateImage)).Methods("POST")
+
+	// Network, subnet and firewall rule routes (scoped to org/project, authenticated)
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/networks", handler.RequireScope(domain.ScopeNetworksWrite, handler.CreateNetwork)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/networks", handler.RequireScope(domain.ScopeNetworksRead, handler.ListNetworks)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/networks/{id}", handler.RequireScope(domain.ScopeNetworksRead, handler.GetNetwork)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/networks/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.UpdateNetwork)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/networks/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.DeleteNetwork)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/subnets", handler.RequireScope(domain.ScopeNetworksWrite, handler.CreateSubnet)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/subnets", handler.RequireScope(domain.ScopeNetworksRead, handler.ListSubnets)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/subnets/{id}", handler.RequireScope(domain.ScopeNetworksRead, handler.GetSubnet)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/subnets/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.UpdateSubnet)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/subnets/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.DeleteSubnet)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/firewall-rules", handler.RequireScope(domain.ScopeNetworksWrite, handler.CreateFirewallRule)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/firewall-rules", handler.RequireScope(domain.ScopeNetworksRead, handler.ListFirewallRules)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/firewall-rules/{id}", handler.RequireScope(domain.ScopeNetworksRead, handler.GetFirewallRule)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/firewall-rules/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.UpdateFirewallRule)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/firewall-rules/{id}", handler.RequireScope(domain.ScopeNetworksWrite, handler.DeleteFirewallRule)).Methods("DELETE")
+
+	// Volume routes (scoped to org/project, authenticated)
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes", handler.RequireScope(domain.ScopeVolumesWrite, handler.CreateVolume)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes", handler.RequireScope(domain.ScopeVolumesRead, handler.ListVolumes)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes/{id:[^/:]+}", handler.RequireScope(domain.ScopeVolumesRead, handler.GetVolume)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes/{id:[^/:]+}", handler.RequireScope(domain.ScopeVolumesWrite, handler.UpdateVolume)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes/{id:[^/:]+}", handler.RequireScope(domain.ScopeVolumesWrite, handler.DeleteVolume)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes/{id:[^/:]+}:attach", handler.RequireScope(domain.ScopeVolumesWrite, handler.AttachVolume)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/volumes/{id:[^/:]+}:detach", handler.RequireScope(domain.ScopeVolumesWrite, handler.DetachVolume)).Methods("POST")
 
 	// Instance routes (scoped to org/project, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances", handler.CreateInstance).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances", handler.ListInstances).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.GetInstance).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.UpdateInstance).Methods("PATCH")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.DeleteInstance).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances", handler.RequireScope(domain.ScopeInstancesWrite, handler.CreateInstance)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances", handler.RequireScope(domain.ScopeInstancesRead, handler.ListInstances)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.RequireScope(domain.ScopeInstancesRead, handler.GetInstance)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.RequireScope(domain.ScopeInstancesWrite, handler.UpdateInstance)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/instances/{id}", handler.RequireScope(domain.ScopeInstancesWrite, handler.DeleteInstance)).Methods("DELETE")
 
 	// Bucket routes (scoped to org/project, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets", handler.CreateBucket).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets", handler.ListBuckets).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.GetBucket).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.UpdateBucket).Methods("PATCH")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.DeleteBucket).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets", handler.RequireScope(domain.ScopeBucketsWrite, handler.CreateBucket)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets", handler.RequireScope(domain.ScopeBucketsRead, handler.ListBuckets)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.RequireScope(domain.ScopeBucketsRead, handler.GetBucket)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.RequireScope(domain.ScopeBucketsWrite, handler.UpdateBucket)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}", handler.RequireScope(domain.ScopeBucketsWrite, handler.DeleteBucket)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/lifecycle", handler.RequireScope(domain.ScopeBucketsRead, handler.GetBucketLifecycle)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/lifecycle", handler.RequireScope(domain.ScopeBucketsWrite, handler.PutBucketLifecycle)).Methods("PUT")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/notifications", handler.RequireScope(domain.ScopeBucketsRead, handler.GetBucketNotifications)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/notifications", handler.RequireScope(domain.ScopeBucketsWrite, handler.PutBucketNotifications)).Methods("PUT")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/notifications/deliveries", handler.RequireScope(domain.ScopeBucketsRead, handler.ListNotificationDeliveries)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/presign", handler.RequireScope(domain.ScopeObjectsRead, handler.PresignObject)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/quota", handler.RequireScope(domain.ScopeBucketsRead, handler.GetBucketQuota)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/quota", handler.RequireScope(domain.ScopeBucketsWrite, handler.PutBucketQuota)).Methods("PUT")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/usage", handler.RequireScope(domain.ScopeBucketsRead, handler.GetBucketUsage)).Methods("GET")
+
+	// Storage quota and usage routes (scoped to project, authenticated)
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/storage/quota", handler.RequireScope(domain.ScopeProjectsRead, handler.GetProjectStorageQuota)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/storage/quota", handler.RequireScope(domain.ScopeProjectsWrite, handler.PutProjectStorageQuota)).Methods("PUT")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/storage/usage", handler.RequireScope(domain.ScopeProjectsRead, handler.GetProjectStorageUsage)).Methods("GET")
 
 	// Object routes (scoped to bucket, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects", handler.CreateObject).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects", handler.ListObjects).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.GetObject).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.UpdateObject).Methods("PATCH")
-	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.DeleteObject).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects", handler.RequireScope(domain.ScopeObjectsWrite, handler.CreateObject)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects", handler.RequireScope(domain.ScopeObjectsRead, handler.ListObjects)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.RequireScope(domain.ScopeObjectsRead, handler.GetObject)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.RequireScope(domain.ScopeObjectsWrite, handler.UpdateObject)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}", handler.RequireScope(domain.ScopeObjectsWrite, handler.DeleteObject)).Methods("DELETE")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}/copy", handler.RequireScope(domain.ScopeObjectsWrite, handler.CopyObject)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/projects/{project}/buckets/{bucket}/objects/{id}/move", handler.RequireScope(domain.ScopeObjectsWrite, handler.MoveObject)).Methods("POST")
 
 	// Metadata routes (scoped to org, authenticated)
-	authAPI.HandleFunc("/orgs/{org}/metadata", handler.CreateMetadata).Methods("POST")
-	authAPI.HandleFunc("/orgs/{org}/metadata", handler.ListMetadata).Methods("GET").Queries("prefix", "")
-	authAPI.HandleFunc("/orgs/{org}/metadata", handler.ListMetadata).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.GetMetadata).Methods("GET")
-	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.UpdateMetadata).Methods("PATCH")
-	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.DeleteMetadata).Methods("DELETE")
-
-	// Terraform state routes (public for now - used by Terraform HTTP backend)
-	api.HandleFunc("/tfstate/{id}", handler.TFStateGet).Methods("GET")
-	api.HandleFunc("/tfstate/{id}", handler.TFStatePost).Methods("POST")
-	api.HandleFunc("/tfstate/{id}", handler.TFStateDelete).Methods("DELETE")
-	api.HandleFunc("/tfstate/{id}", handler.TFStateLock).Methods("LOCK")
-	api.HandleFunc("/tfstate/{id}", handler.TFStateUnlock).Methods("UNLOCK")
+	authAPI.HandleFunc("/orgs/{org}/metadata", handler.RequireScope(domain.ScopeMetadataWrite, handler.CreateMetadata)).Methods("POST")
+	authAPI.HandleFunc("/orgs/{org}/metadata", handler.RequireScope(domain.ScopeMetadataRead, handler.ListMetadata)).Methods("GET").Queries("prefix", "")
+	authAPI.HandleFunc("/orgs/{org}/metadata", handler.RequireScope(domain.ScopeMetadataRead, handler.ListMetadata)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.RequireScope(domain.ScopeMetadataRead, handler.GetMetadata)).Methods("GET")
+	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.RequireScope(domain.ScopeMetadataWrite, handler.UpdateMetadata)).Methods("PATCH")
+	authAPI.HandleFunc("/orgs/{org}/metadata/{id}", handler.RequireScope(domain.ScopeMetadataWrite, handler.DeleteMetadata)).Methods("DELETE")
+
+	// Search (scoped to org, authenticated)
+	authAPI.HandleFunc("/orgs/{org}/search", handler.RequireScope(domain.ScopeOrgRead, handler.Search)).Methods("GET")
+
+	// Presigned object routes (public - the token itself is the credential)
+	api.HandleFunc("/presigned/{token}", handler.PresignedGet).Methods("GET")
+	api.HandleFunc("/presigned/{token}", handler.PresignedPut).Methods("PUT")
+
+	// Terraform state routes (scoped to org, authenticated - used by Terraform HTTP backend)
+	// /tfstate/{id} is the older form and stores states in the org of the API key
+	for _, route := range []string{"/orgs/{org}/tfstate/{id}", "/tfstate/{id}"} {
+		authAPI.HandleFunc(route, handler.RequireScope(domain.ScopeTFStateRead, handler.TFStateGet)).Methods("GET")
+		authAPI.HandleFunc(route, handler.RequireScope(domain.ScopeTFStateWrite, handler.TFStatePost)).Methods("POST")
+		authAPI.HandleFunc(route, handler.RequireScope(domain.ScopeTFStateWrite, handler.TFStateDelete)).Methods("DELETE")
+		authAPI.HandleFunc(route, handler.RequireScope(domain.ScopeTFStateLock, handler.TFStateLock)).Methods("LOCK")
+		authAPI.HandleFunc(route, handler.RequireScope(domain.ScopeTFStateLock, handler.TFStateUnlock)).Methods("UNLOCK")
+	}
+
+	// Admin API (requires the admin token, manages all orgs)
+	admin := router.PathPrefix("/admin/v1").Subrouter()
+	admin.Use(AdminAuthMiddleware(svc))
+	admin.HandleFunc("/orgs", handler.AdminCreateOrganization).Methods("POST")
+	admin.HandleFunc("/orgs", handler.AdminListOrganizations).Methods("GET")
+	admin.HandleFunc("/orgs/{org}", handler.AdminGetOrganization).Methods("GET")
+	admin.HandleFunc("/orgs/{org}", handler.AdminUpdateOrganization).Methods("PATCH")
+	admin.HandleFunc("/orgs/{org}", handler.AdminDeleteOrganization).Methods("DELETE")
+	admin.HandleFunc("/orgs/{org}/reset-api-keys", handler.AdminResetAPIKeys).Methods("POST")
+	admin.HandleFunc("/orgs/{org}/quotas", handler.AdminGetResourceQuota).Methods("GET")
+	admin.HandleFunc("/orgs/{org}/quotas", handler.AdminPutResourceQuota).Methods("PUT")
+	admin.HandleFunc("/orgs/{org}/quotas", handler.AdminDeleteResourceQuota).Methods("DELETE")
+	admin.HandleFunc("/regions/{region}", handler.AdminUpdateRegion).Methods("PATCH")
+	admin.HandleFunc("/stats", handler.AdminGetStats).Methods("GET")
 
 	// Add CORS middleware for development
 	router.Use(corsMiddleware)
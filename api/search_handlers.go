@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// Search handles GET /v1/orgs/{org}/search?q=...&kind=...&limit=...
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			h.writeError(w, domain.InvalidInputError("limit must be an integer", map[string]interface{}{"actual": v}))
+			return
+		}
+	}
+
+	// Results the key cannot read are left out rather than failing the search
+	results, err := h.service.Search(domain.SearchOptions{
+		OrgID: org.ID,
+		Query: query.Get("q"),
+		Kind:  query.Get("kind"),
+		Limit: limit,
+	}, APIKeyFromContext(r.Context()))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	for _, result := range results {
+		result.URL = searchResultURL(org, result)
+	}
+
+	h.writeJSON(w, http.StatusOK, results)
+}
+
+// searchResultURL returns the API URL of a search result
+func searchResultURL(org *domain.Organization, result *domain.SearchResult) string {
+	orgURL := "/v1/orgs/" + url.PathEscape(org.Slug)
+	projectURL := orgURL + "/projects/" + url.PathEscape(result.ProjectSlug)
+	switch result.Kind {
+	case domain.ResourceKindProject:
+		return projectURL
+	case domain.ResourceKindInstance:
+		return projectURL + "/instances/" + url.PathEscape(result.ID)
+	case domain.ResourceKindBucket:
+		return projectURL + "/buckets/" + url.PathEscape(result.BucketID)
+	case domain.ResourceKindObject:
+		return projectURL + "/buckets/" + url.PathEscape(result.BucketID) + "/objects/" + url.PathEscape(result.ID)
+	case domain.ResourceKindMetadata:
+		return orgURL + "/metadata/" + url.PathEscape(result.ID)
+	}
+	return ""
+}
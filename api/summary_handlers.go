@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// GetOrgSummary handles GET /v1/orgs/{org}/summary
+func (h *Handler) GetOrgSummary(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	// The summary covers every project of the org
+	key := APIKeyFromContext(r.Context())
+	if key != nil && len(key.ProjectIDs) > 0 {
+		h.writeError(w, domain.ForbiddenError("API key restricted to projects cannot read the org summary", nil))
+		return
+	}
+
+	summary, err := h.service.GetOrgSummary(org.ID, key)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	for _, resource := range summary.RecentChanges {
+		resource.URL = searchResultURL(org, &resource.SearchResult)
+	}
+
+	h.writeJSON(w, http.StatusOK, summary)
+}
@@ -9,12 +9,28 @@ import (
 	"github.com/hypertf/nahcloud/domain"
 )
 
-// TFStateGet handles GET /v1/tfstate/{state_id}
+// tfStateOrg returns the org whose states a request addresses: the org in the URL, or
+// the authenticated org for the legacy /v1/tfstate/{id} routes
+func (h *Handler) tfStateOrg(r *http.Request) (*domain.Organization, error) {
+	if _, ok := mux.Vars(r)["org"]; ok {
+		return h.resolveOrg(r)
+	}
+	if org := OrgFromContext(r.Context()); org != nil {
+		return org, nil
+	}
+	return nil, domain.UnauthorizedError("missing credentials")
+}
+
+// TFStateGet handles GET /v1/orgs/{org}/tfstate/{state_id}
 func (h *Handler) TFStateGet(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	org, err := h.tfStateOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
 
-	state, err := h.service.GetTFState(id)
+	state, err := h.service.GetTFState(org.ID, id)
 	if err != nil {
 		if domain.IsNotFound(err) {
 			w.WriteHeader(http.StatusNotFound)
@@ -28,13 +44,17 @@ func (h *Handler) TFStateGet(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(state))
 }
 
-// TFStatePost handles POST /v1/tfstate/{state_id}
+// TFStatePost handles POST /v1/orgs/{org}/tfstate/{state_id}
 func (h *Handler) TFStatePost(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	org, err := h.tfStateOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
 
 	// Enforce lock if present
-	if rawLock, lockInfo, err := h.service.GetTFStateLock(id); err == nil && lockInfo != nil {
+	if rawLock, lockInfo, err := h.service.GetTFStateLock(org.ID, id); err == nil && lockInfo != nil {
 		provided := r.URL.Query().Get("ID")
 		if provided == "" || provided != lockInfo.ID {
 			w.Header().Set("Content-Type", "application/json")
@@ -49,20 +69,24 @@ func (h *Handler) TFStatePost(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, domain.InternalError("failed to read request body"))
 		return
 	}
-	if err := h.service.SetTFState(id, string(body)); err != nil {
+	if err := h.service.SetTFState(org.ID, id, string(body)); err != nil {
 		h.writeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
-// TFStateDelete handles DELETE /v1/tfstate/{state_id}
+// TFStateDelete handles DELETE /v1/orgs/{org}/tfstate/{state_id}
 func (h *Handler) TFStateDelete(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	org, err := h.tfStateOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
 
 	// Enforce lock if present
-	if rawLock, lockInfo, err := h.service.GetTFStateLock(id); err == nil && lockInfo != nil {
+	if rawLock, lockInfo, err := h.service.GetTFStateLock(org.ID, id); err == nil && lockInfo != nil {
 		provided := r.URL.Query().Get("ID")
 		if provided == "" || provided != lockInfo.ID {
 			w.Header().Set("Content-Type", "application/json")
@@ -72,17 +96,21 @@ func (h *Handler) TFStateDelete(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := h.service.DeleteTFState(id); err != nil {
+	if err := h.service.DeleteTFState(org.ID, id); err != nil {
 		h.writeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
-// TFStateLock handles LOCK /v1/tfstate/{state_id}
+// TFStateLock handles LOCK /v1/orgs/{org}/tfstate/{state_id}
 func (h *Handler) TFStateLock(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	org, err := h.tfStateOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -97,7 +125,7 @@ func (h *Handler) TFStateLock(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to place the lock
-	locked, existing, err := h.service.TryLockTFState(id, string(body))
+	locked, existing, err := h.service.TryLockTFState(org.ID, id, string(body))
 	if err != nil {
 		h.writeError(w, err)
 		return
@@ -111,14 +139,18 @@ func (h *Handler) TFStateLock(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// TFStateUnlock handles UNLOCK /v1/tfstate/{state_id}
+// TFStateUnlock handles UNLOCK /v1/orgs/{org}/tfstate/{state_id}
 func (h *Handler) TFStateUnlock(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	org, err := h.tfStateOrg(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
 
 	provided := r.URL.Query().Get("ID")
 	// Get current lock
-	rawLock, lockInfo, err := h.service.GetTFStateLock(id)
+	rawLock, lockInfo, err := h.service.GetTFStateLock(org.ID, id)
 	if err != nil {
 		// Not locked
 		w.WriteHeader(http.StatusOK)
@@ -126,7 +158,7 @@ func (h *Handler) TFStateUnlock(w http.ResponseWriter, r *http.Request) {
 	}
 	if lockInfo == nil || provided == lockInfo.ID {
 		// No parsed info or matching ID: unlock
-		if _, _, err := h.service.UnlockTFState(id); err != nil {
+		if _, _, err := h.service.UnlockTFState(org.ID, id); err != nil {
 			h.writeError(w, err)
 			return
 		}
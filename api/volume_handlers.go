@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// CreateVolume handles POST /v1/orgs/{org}/projects/{project}/volumes
+func (h *Handler) CreateVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.CreateVolumeRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volume, err := h.service.CreateVolume(project.ID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, volume)
+}
+
+// ListVolumes handles GET /v1/orgs/{org}/projects/{project}/volumes
+// Lists the volumes attached to one ?instance_id= if set
+func (h *Handler) ListVolumes(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volumes, err := h.service.ListVolumes(domain.VolumeListOptions{
+		ProjectID:  project.ID,
+		InstanceID: r.URL.Query().Get("instance_id"),
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, volumes)
+}
+
+// GetVolume handles GET /v1/orgs/{org}/projects/{project}/volumes/{id}
+func (h *Handler) GetVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volume, err := h.service.GetVolume(project.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, volume)
+}
+
+// UpdateVolume handles PATCH /v1/orgs/{org}/projects/{project}/volumes/{id}
+func (h *Handler) UpdateVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.UpdateVolumeRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volume, err := h.service.UpdateVolume(project.ID, mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, volume)
+}
+
+// DeleteVolume handles DELETE /v1/orgs/{org}/projects/{project}/volumes/{id}
+func (h *Handler) DeleteVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteVolume(project.ID, mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AttachVolume handles POST /v1/orgs/{org}/projects/{project}/volumes/{id}:attach
+func (h *Handler) AttachVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var req domain.AttachVolumeRequest
+	if err := h.decodeJSON(r, &req); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volume, err := h.service.AttachVolume(project.ID, mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, volume)
+}
+
+// DetachVolume handles POST /v1/orgs/{org}/projects/{project}/volumes/{id}:detach
+func (h *Handler) DetachVolume(w http.ResponseWriter, r *http.Request) {
+	project, err := h.resolveProject(r)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	volume, err := h.service.DetachVolume(project.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, volume)
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/hypertf/nahcloud/domain"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Version is set at build time
@@ -13,8 +17,42 @@ var Version = "dev"
 
 // Config holds all server configuration
 type Config struct {
-	Addr      string `mapstructure:"addr"`
-	SQLiteDSN string `mapstructure:"sqlite_dsn"`
+	Addr              string         `mapstructure:"addr"`
+	SQLiteDSN         string         `mapstructure:"sqlite_dsn"`
+	LifecycleInterval time.Duration  `mapstructure:"lifecycle_interval"`  // 0 disables the bucket lifecycle worker
+	SigningSecret     string         `mapstructure:"signing_secret"`      // HMAC key for presigned URLs; random per process if empty
+	AdminToken        string         `mapstructure:"admin_token"`         // Bearer token for /admin/v1; random per process if empty
+	DisableOrgSignup  bool           `mapstructure:"disable_org_signup"`  // Only allow creating orgs through the admin API
+	RateLimitGlobal   int            `mapstructure:"rate_limit_global"`   // API requests per minute across all orgs (0 is unlimited)
+	RateLimitOrg      int            `mapstructure:"rate_limit_org"`      // API requests per minute per org (0 is unlimited)
+	RateLimitKey      int            `mapstructure:"rate_limit_key"`      // API requests per minute per API key (0 is unlimited)
+	Quota             QuotaConfig    `mapstructure:"quota"`               // Default resource quota of orgs (0 is unlimited)
+	InstanceTypesFile string         `mapstructure:"instance_types_file"` // YAML instance type catalog; built-in catalog if empty
+	OIDCIssuers       []OIDCIssuer   `mapstructure:"oidc_issuers"`        // Issuers whose tokens can be exchanged for API keys (config file only)
+	Regions           []RegionConfig `mapstructure:"regions"`             // Regions and their zones; built-in regions if empty (config file only)
+}
+
+// QuotaConfig is the default resource quota of orgs; the admin API can override it per org
+type QuotaConfig struct {
+	MaxProjects            int `mapstructure:"max_projects"`
+	MaxInstancesPerProject int `mapstructure:"max_instances_per_project"`
+	MaxCPUPerRegion        int `mapstructure:"max_cpu_per_region"`
+	MaxMemoryMBPerRegion   int `mapstructure:"max_memory_mb_per_region"`
+}
+
+// OIDCIssuer configures an OIDC issuer trusted for token exchange
+type OIDCIssuer struct {
+	Issuer   string `mapstructure:"issuer"`
+	JWKSURL  string `mapstructure:"jwks_url"`  // the jwks_uri of the issuer's discovery document
+	JWKSFile string `mapstructure:"jwks_file"` // read instead of fetching the keys, e.g. for air-gapped setups
+	Audience string `mapstructure:"audience"`  // required, tokens must be issued for it
+}
+
+// RegionConfig configures a region and its zones
+type RegionConfig struct {
+	Name  string   `mapstructure:"name"`
+	Zones []string `mapstructure:"zones"`
+	Down  bool     `mapstructure:"down"` // start with the region down, to simulate an outage
 }
 
 // setupConfig initializes viper with flags, env vars, and config file support
@@ -23,10 +61,34 @@ func setupConfig(cmd *cobra.Command) {
 	cmd.Flags().StringP("config", "c", "", "Config file path (YAML, JSON, or TOML)")
 	cmd.Flags().String("addr", ":8080", "HTTP server address")
 	cmd.Flags().String("sqlite-dsn", "", "SQLite database path")
+	cmd.Flags().Duration("lifecycle-interval", time.Hour, "How often bucket lifecycle rules are evaluated (0 disables)")
+	cmd.Flags().String("signing-secret", "", "Secret used to sign presigned URLs (random if unset)")
+	cmd.Flags().String("admin-token", "", "Token for the admin API (random and logged at startup if unset)")
+	cmd.Flags().Bool("disable-org-signup", false, "Disable public org creation (POST /v1/orgs)")
+	cmd.Flags().Int("rate-limit-global", 0, "API requests per minute across all orgs (0 is unlimited)")
+	cmd.Flags().Int("rate-limit-org", 0, "API requests per minute per org (0 is unlimited)")
+	cmd.Flags().Int("rate-limit-key", 0, "API requests per minute per API key (0 is unlimited)")
+	cmd.Flags().String("instance-types-file", "", "YAML file with the instance type catalog (built-in catalog if unset)")
+	cmd.Flags().Int("quota-max-projects", 0, "Default max projects per org (0 is unlimited)")
+	cmd.Flags().Int("quota-max-instances", 0, "Default max instances per project (0 is unlimited)")
+	cmd.Flags().Int("quota-max-cpu", 0, "Default max vCPUs per project and region (0 is unlimited)")
+	cmd.Flags().Int("quota-max-memory-mb", 0, "Default max memory (MB) per project and region (0 is unlimited)")
 
 	// Bind flags to viper
 	viper.BindPFlag("addr", cmd.Flags().Lookup("addr"))
 	viper.BindPFlag("sqlite_dsn", cmd.Flags().Lookup("sqlite-dsn"))
+	viper.BindPFlag("lifecycle_interval", cmd.Flags().Lookup("lifecycle-interval"))
+	viper.BindPFlag("signing_secret", cmd.Flags().Lookup("signing-secret"))
+	viper.BindPFlag("admin_token", cmd.Flags().Lookup("admin-token"))
+	viper.BindPFlag("disable_org_signup", cmd.Flags().Lookup("disable-org-signup"))
+	viper.BindPFlag("rate_limit_global", cmd.Flags().Lookup("rate-limit-global"))
+	viper.BindPFlag("rate_limit_org", cmd.Flags().Lookup("rate-limit-org"))
+	viper.BindPFlag("rate_limit_key", cmd.Flags().Lookup("rate-limit-key"))
+	viper.BindPFlag("instance_types_file", cmd.Flags().Lookup("instance-types-file"))
+	viper.BindPFlag("quota.max_projects", cmd.Flags().Lookup("quota-max-projects"))
+	viper.BindPFlag("quota.max_instances_per_project", cmd.Flags().Lookup("quota-max-instances"))
+	viper.BindPFlag("quota.max_cpu_per_region", cmd.Flags().Lookup("quota-max-cpu"))
+	viper.BindPFlag("quota.max_memory_mb_per_region", cmd.Flags().Lookup("quota-max-memory-mb"))
 
 	// Set up environment variable binding with NAH_ prefix
 	viper.SetEnvPrefix("NAH")
@@ -35,6 +97,7 @@ func setupConfig(cmd *cobra.Command) {
 
 	// Set defaults
 	viper.SetDefault("addr", ":8080")
+	viper.SetDefault("lifecycle_interval", time.Hour)
 }
 
 // loadConfig loads configuration from flags, env vars, and config file
@@ -65,6 +128,14 @@ Environment Variables:
 
   NAH_ADDR=:9090                    Set server address
   NAH_SQLITE_DSN=./data.db          Set database path
+  NAH_LIFECYCLE_INTERVAL=10m        Set bucket lifecycle evaluation interval
+  NAH_SIGNING_SECRET=change-me      Set presigned URL signing secret
+  NAH_ADMIN_TOKEN=change-me         Set admin API token
+  NAH_DISABLE_ORG_SIGNUP=true       Only allow creating orgs through the admin API
+  NAH_RATE_LIMIT_ORG=600            Limit each org to 600 API requests per minute
+  NAH_INSTANCE_TYPES_FILE=types.yaml Load the instance type catalog from a YAML file
+  NAH_QUOTA_MAX_INSTANCES_PER_PROJECT=100
+                                    Default max instances per project
 
 Config File:
   Use --config to specify a YAML, JSON, or TOML config file.
@@ -72,6 +143,28 @@ Config File:
 
     addr: ":8080"
     sqlite_dsn: "./nahcloud.db"
+    lifecycle_interval: "1h"
+    signing_secret: "change-me"
+    admin_token: "change-me"
+    disable_org_signup: true
+    rate_limit_org: 600
+    rate_limit_key: 120
+    instance_types_file: "./instance-types.yaml"
+    quota:
+      max_projects: 20
+      max_instances_per_project: 100
+      max_cpu_per_region: 64
+      max_memory_mb_per_region: 262144
+    oidc_issuers:
+      - issuer: "https://token.actions.githubusercontent.com"
+        jwks_url: "https://token.actions.githubusercontent.com/.well-known/jwks"
+        audience: "nahcloud"
+    regions:
+      - name: "us-east-1"
+        zones: ["us-east-1a", "us-east-1b"]
+      - name: "eu-west-1"
+        zones: ["eu-west-1a"]
+        down: true
 
 Priority (highest to lowest):
   1. Command-line flags
@@ -80,3 +173,25 @@ Priority (highest to lowest):
   4. Defaults
 `
 }
+
+// instanceTypesFile is the format of the instance type catalog file
+type instanceTypesFile struct {
+	InstanceTypes []domain.InstanceType `yaml:"instance_types"`
+}
+
+// loadInstanceTypes reads an instance type catalog from a YAML file
+func loadInstanceTypes(path string) ([]domain.InstanceType, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var file instanceTypesFile
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file.InstanceTypes, nil
+}
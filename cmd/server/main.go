@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,6 +14,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/hypertf/nahcloud/api"
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+	"github.com/hypertf/nahcloud/pkg/oidc"
 	"github.com/hypertf/nahcloud/service"
 	"github.com/hypertf/nahcloud/storage/sqlite"
 )
@@ -66,6 +70,85 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	// Initialize service layer
 	svc := service.NewService(orgRepo, apiKeyRepo, projectRepo, instanceRepo, metadataRepo, bucketRepo, objectRepo)
+	svc.SetSearchRepository(sqlite.NewSearchRepository(db))
+	svc.SetImageRepository(sqlite.NewImageRepository(db))
+	svc.SetNetworkRepositories(sqlite.NewNetworkRepository(db), sqlite.NewSubnetRepository(db))
+	svc.SetFirewallRuleRepository(sqlite.NewFirewallRuleRepository(db))
+	svc.SetVolumeRepository(sqlite.NewVolumeRepository(db))
+
+	// Configure the secret used to sign presigned URLs
+	signingSecret := []byte(config.SigningSecret)
+	if len(signingSecret) == 0 {
+		signingSecret = make([]byte, 32)
+		if _, err := rand.Read(signingSecret); err != nil {
+			return fmt.Errorf("failed to generate signing secret: %w", err)
+		}
+		log.Printf("No signing secret configured; presigned URLs will not survive a restart")
+	}
+	svc.SetSigningSecret(signingSecret)
+
+	// Configure the admin API token
+	adminToken := config.AdminToken
+	if adminToken == "" {
+		adminToken, err = endec.CreateToken(endec.PrefixAdmin, 24)
+		if err != nil {
+			return fmt.Errorf("failed to generate admin token: %w", err)
+		}
+		log.Printf("No admin token configured; generated one for this run: %s", adminToken)
+	}
+	svc.SetAdminToken(adminToken)
+	svc.SetPublicOrgCreation(!config.DisableOrgSignup)
+	if config.RateLimitGlobal < 0 || config.RateLimitOrg < 0 || config.RateLimitKey < 0 {
+		return fmt.Errorf("rate limits cannot be negative")
+	}
+	svc.SetRateLimits(domain.RateLimits{Global: config.RateLimitGlobal, PerOrg: config.RateLimitOrg, PerKey: config.RateLimitKey})
+	if err := svc.SetDefaultResourceQuota(domain.ResourceQuota{
+		MaxProjects:            config.Quota.MaxProjects,
+		MaxInstancesPerProject: config.Quota.MaxInstancesPerProject,
+		MaxCPUPerRegion:        config.Quota.MaxCPUPerRegion,
+		MaxMemoryMBPerRegion:   config.Quota.MaxMemoryMBPerRegion,
+	}); err != nil {
+		return fmt.Errorf("invalid default quota: %w", err)
+	}
+
+	// Regions come first, since instance types are checked against them
+	if len(config.Regions) > 0 {
+		var regions []domain.Region
+		for _, r := range config.Regions {
+			regions = append(regions, domain.Region{Name: r.Name, Zones: r.Zones, Down: r.Down})
+		}
+		if err := svc.SetRegions(regions); err != nil {
+			return fmt.Errorf("invalid regions: %w", err)
+		}
+	}
+
+	if config.InstanceTypesFile != "" {
+		types, err := loadInstanceTypes(config.InstanceTypesFile)
+		if err != nil {
+			return fmt.Errorf("failed to load instance types: %w", err)
+		}
+		if err := svc.SetInstanceTypes(types); err != nil {
+			return fmt.Errorf("invalid instance types: %w", err)
+		}
+	}
+
+	// Configure the OIDC issuers trusted for token exchange
+	var issuers []oidc.Issuer
+	for _, iss := range config.OIDCIssuers {
+		issuers = append(issuers, oidc.Issuer{URL: iss.Issuer, JWKSURL: iss.JWKSURL, JWKSFile: iss.JWKSFile, Audience: iss.Audience})
+	}
+	verifier, err := oidc.NewVerifier(issuers)
+	if err != nil {
+		return fmt.Errorf("invalid OIDC issuer config: %w", err)
+	}
+	svc.SetOIDC(sqlite.NewOIDCTrustPolicyRepository(db), verifier)
+
+	// Start background workers (stopped when the server exits)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if config.LifecycleInterval > 0 {
+		svc.StartLifecycleWorker(workerCtx, config.LifecycleInterval)
+	}
 
 	// Initialize API handlers
 	handler := api.NewHandler(svc)
@@ -81,6 +164,8 @@ func runServer(cmd *cobra.Command, args []string) error {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// End console event streams on shutdown, otherwise they would hold it up
+	server.RegisterOnShutdown(svc.CloseEvents)
 
 	// Start server in a goroutine
 	serverErrors := make(chan error, 1)
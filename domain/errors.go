@@ -12,6 +12,23 @@ const (
 	ErrorCodeForeignKeyViolation = "FOREIGN_KEY_VIOLATION"
 	ErrorCodeInternalError = "INTERNAL_ERROR"
 	ErrorCodeUnauthorized  = "UNAUTHORIZED"
+	ErrorCodeForbidden     = "FORBIDDEN"
+	ErrorCodeQuotaExceeded = "QUOTA_EXCEEDED"
+	ErrorCodePreconditionFailed = "PRECONDITION_FAILED"
+	ErrorCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrorCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ErrorCodeConflict           = "CONFLICT"
+)
+
+// Quota limit names reported in the "limit" detail of a quota exceeded error
+const (
+	QuotaLimitMaxBytes   = "max_bytes"
+	QuotaLimitMaxObjects = "max_objects"
+
+	QuotaLimitMaxProjects            = "max_projects"
+	QuotaLimitMaxInstancesPerProject = "max_instances_per_project"
+	QuotaLimitMaxCPUPerRegion        = "max_cpu_per_region"
+	QuotaLimitMaxMemoryMBPerRegion   = "max_memory_mb_per_region"
 )
 
 // NahError represents a domain error with structured information
@@ -79,6 +96,46 @@ func UnauthorizedError(message string) *NahError {
 	return NewError(ErrorCodeUnauthorized, message)
 }
 
+// ForbiddenError creates a forbidden error, for credentials that are valid but not
+// allowed to perform the operation
+func ForbiddenError(message string, details map[string]interface{}) *NahError {
+	return NewError(ErrorCodeForbidden, message, details)
+}
+
+// QuotaExceededError creates a quota exceeded error
+// resource is the scope the quota belongs to (e.g. "bucket", "project"),
+// limit is one of the QuotaLimit* names, and requested is the usage the operation would result in
+func QuotaExceededError(resource string, limit string, max int64, requested int64) *NahError {
+	return NewError(ErrorCodeQuotaExceeded, fmt.Sprintf("%s quota exceeded: %s", resource, limit), map[string]interface{}{
+		"resource":  resource,
+		"limit":     limit,
+		"max":       max,
+		"requested": requested,
+	})
+}
+
+// PreconditionFailedError creates a precondition failed error
+func PreconditionFailedError(message string, details map[string]interface{}) *NahError {
+	return NewError(ErrorCodePreconditionFailed, message, details)
+}
+
+// TooManyRequestsError creates a rate limit error
+func TooManyRequestsError(message string) *NahError {
+	return NewError(ErrorCodeTooManyRequests, message)
+}
+
+// ServiceUnavailableError creates an error for an operation that cannot be performed right
+// now, e.g. because a region is down
+func ServiceUnavailableError(message string) *NahError {
+	return NewError(ErrorCodeServiceUnavailable, message)
+}
+
+// ConflictError creates an error for an operation that conflicts with the current state
+// of a resource, e.g. deleting a resource that is still in use
+func ConflictError(message string, details map[string]interface{}) *NahError {
+	return NewError(ErrorCodeConflict, message, details)
+}
+
 // IsNotFound checks if error is a not found error
 func IsNotFound(err error) bool {
 	if nahErr, ok := err.(*NahError); ok {
@@ -117,4 +174,52 @@ func IsUnauthorized(err error) bool {
 		return nahErr.Code == ErrorCodeUnauthorized
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// IsForbidden checks if error is a forbidden error
+func IsForbidden(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodeForbidden
+	}
+	return false
+}
+
+// IsQuotaExceeded checks if error is a quota exceeded error
+func IsQuotaExceeded(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodeQuotaExceeded
+	}
+	return false
+}
+
+// IsPreconditionFailed checks if error is a precondition failed error
+func IsPreconditionFailed(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodePreconditionFailed
+	}
+	return false
+}
+
+// IsTooManyRequests checks if error is a rate limit error
+func IsTooManyRequests(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodeTooManyRequests
+	}
+	return false
+}
+
+// IsServiceUnavailable checks if error is a service unavailable error
+func IsServiceUnavailable(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodeServiceUnavailable
+	}
+	return false
+}
+
+// IsConflict checks if error is a conflict error
+func IsConflict(err error) bool {
+	if nahErr, ok := err.(*NahError); ok {
+		return nahErr.Code == ErrorCodeConflict
+	}
+	return false
+}
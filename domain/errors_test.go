@@ -153,6 +153,39 @@ func TestServiceUnavailableError(t *testing.T) {
 	assert.Nil(t, err.Details)
 }
 
+func TestForbiddenError(t *testing.T) {
+	err := ForbiddenError("API key is missing scope", map[string]interface{}{"scope": ScopeProjectsWrite})
+
+	assert.Equal(t, ErrorCodeForbidden, err.Code)
+	assert.Equal(t, "API key is missing scope", err.Message)
+	assert.Equal(t, map[string]interface{}{"scope": ScopeProjectsWrite}, err.Details)
+	assert.True(t, IsForbidden(err))
+	assert.False(t, IsForbidden(UnauthorizedError("invalid token")))
+}
+
+func TestQuotaExceededError(t *testing.T) {
+	err := QuotaExceededError("bucket", QuotaLimitMaxBytes, 1024, 2048)
+
+	assert.Equal(t, ErrorCodeQuotaExceeded, err.Code)
+	assert.Equal(t, "bucket quota exceeded: max_bytes", err.Message)
+	assert.Equal(t, map[string]interface{}{
+		"resource":  "bucket",
+		"limit":     QuotaLimitMaxBytes,
+		"max":       int64(1024),
+		"requested": int64(2048),
+	}, err.Details)
+}
+
+func TestPreconditionFailedError(t *testing.T) {
+	err := PreconditionFailedError("version mismatch", map[string]interface{}{"expected": 2, "actual": 3})
+
+	assert.Equal(t, ErrorCodePreconditionFailed, err.Code)
+	assert.Equal(t, "version mismatch", err.Message)
+	assert.Equal(t, map[string]interface{}{"expected": 2, "actual": 3}, err.Details)
+	assert.True(t, IsPreconditionFailed(err))
+	assert.False(t, IsPreconditionFailed(NotFoundError("object", "123")))
+}
+
 func TestIsNotFound(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -276,4 +309,44 @@ func TestIsInvalidInput(t *testing.T) {
 			assert.Equal(t, tt.expected, IsInvalidInput(tt.err))
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestIsQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "quota exceeded error",
+			err:      QuotaExceededError("project", QuotaLimitMaxObjects, 10, 11),
+			expected: true,
+		},
+		{
+			name:     "invalid input error",
+			err:      InvalidInputError("bad input", nil),
+			expected: false,
+		},
+		{
+			name:     "generic error",
+			err:      assert.AnError,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsQuotaExceeded(tt.err))
+		})
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	err := ConflictError("subnet has instances", map[string]interface{}{"instances": 2})
+
+	assert.Equal(t, ErrorCodeConflict, err.Code)
+	assert.Equal(t, "subnet has instances", err.Message)
+	assert.Equal(t, map[string]interface{}{"instances": 2}, err.Details)
+	assert.True(t, IsConflict(err))
+	assert.False(t, IsConflict(AlreadyExistsError("subnet", "name", "a")))
+}
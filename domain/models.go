@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -15,13 +18,143 @@ type Organization struct {
 }
 
 // APIKey represents an API key tied to an organization
+// A key without scopes has full access to its org; a key without project IDs can use every project
 type APIKey struct {
 	ID         string     `json:"id" db:"id"`
 	OrgID      string     `json:"org_id" db:"org_id"`
 	Name       string     `json:"name" db:"name"`
 	TokenHash  string     `json:"-" db:"token_hash"` // SHA-256 hash, never exposed
+	Scopes     []string   `json:"scopes,omitempty" db:"scopes"`
+	ProjectIDs []string   `json:"project_ids,omitempty" db:"project_ids"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+
+	// Rotation replaces the token of a key; the previous token keeps working until PreviousTokenExpiresAt
+	RotatedAt              *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+	PreviousTokenHash      string     `json:"-" db:"previous_token_hash"`
+	PreviousTokenExpiresAt *time.Time `json:"previous_token_expires_at,omitempty" db:"previous_token_expires_at"`
+
+	// Source is empty for keys created through the API, or APIKeySourceOIDC for keys minted by an OIDC token exchange
+	Source string `json:"source,omitempty" db:"source"`
+}
+
+// APIKeySourceOIDC is the source of API keys minted by an OIDC token exchange
+const APIKeySourceOIDC = "oidc"
+
+// API key scopes, written as resource:action
+// A write scope also grants the read scope of the same resource
+const (
+	ScopeOrgRead        = "org:read" // the org itself, its summary and search
+	ScopeAPIKeysRead    = "api-keys:read"
+	ScopeAPIKeysWrite   = "api-keys:write"
+	ScopeProjectsRead   = "projects:read"
+	ScopeProjectsWrite  = "projects:write"
+	ScopeInstancesRead  = "instances:read"
+	ScopeInstancesWrite = "instances:write"
+	ScopeNetworksRead   = "networks:read" // networks, subnets and firewall rules
+	ScopeNetworksWrite  = "networks:write"
+	ScopeVolumesRead    = "volumes:read"
+	ScopeVolumesWrite   = "volumes:write"
+	ScopeBucketsRead    = "buckets:read"
+	ScopeBucketsWrite   = "buckets:write"
+	ScopeObjectsRead    = "objects:read"
+	ScopeObjectsWrite   = "objects:write"
+	ScopeMetadataRead   = "metadata:read"
+	ScopeMetadataWrite  = "metadata:write"
+	ScopeTFStateRead    = "tfstate:read"
+	ScopeTFStateWrite   = "tfstate:write"
+	ScopeTFStateLock    = "tfstate:lock"
+)
+
+// Scopes lists every API key scope
+var Scopes = []string{
+	ScopeOrgRead,
+	ScopeAPIKeysRead, ScopeAPIKeysWrite,
+	ScopeProjectsRead, ScopeProjectsWrite,
+	ScopeInstancesRead, ScopeInstancesWrite,
+	ScopeNetworksRead, ScopeNetworksWrite,
+	ScopeVolumesRead, ScopeVolumesWrite,
+	ScopeBucketsRead, ScopeBucketsWrite,
+	ScopeObjectsRead, ScopeObjectsWrite,
+	ScopeMetadataRead, ScopeMetadataWrite,
+	ScopeTFStateRead, ScopeTFStateWrite, ScopeTFStateLock,
+}
+
+// IsValidScope reports whether scope is one of Scopes
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Restricted reports whether the key is limited to some scopes or projects
+func (k *APIKey) Restricted() bool {
+	return len(k.Scopes) > 0 || len(k.ProjectIDs) > 0
+}
+
+// Expired reports whether the key has expired at the given time
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	resource, action, _ := strings.Cut(scope, ":")
+	for _, s := range k.Scopes {
+		if s == scope || (action == "read" && s == resource+":write") {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProject reports whether the key can be used with the project
+func (k *APIKey) AllowsProject(projectID string) bool {
+	if len(k.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range k.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether the key may create or delete other: keys can only manage
+// keys with at most their own scopes, projects and lifetime
+func (k *APIKey) CanGrant(other *APIKey) bool {
+	if len(k.Scopes) > 0 {
+		if len(other.Scopes) == 0 {
+			return false
+		}
+		for _, s := range other.Scopes {
+			if !k.HasScope(s) {
+				return false
+			}
+		}
+	}
+	if len(k.ProjectIDs) > 0 {
+		if len(other.ProjectIDs) == 0 {
+			return false
+		}
+		for _, id := range other.ProjectIDs {
+			if !k.AllowsProject(id) {
+				return false
+			}
+		}
+	}
+	if k.ExpiresAt != nil && (other.ExpiresAt == nil || other.ExpiresAt.After(*k.ExpiresAt)) {
+		return false
+	}
+	return true
 }
 
 // APIKeyWithToken is returned only on key creation (contains plaintext token)
@@ -30,6 +163,85 @@ type APIKeyWithToken struct {
 	Token string `json:"token"` // Plaintext token, shown only once
 }
 
+// Key rotation overlap limits
+const (
+	DefaultRotationOverlap = time.Hour
+	MaxRotationOverlap     = 30 * 24 * time.Hour
+)
+
+// RotateAPIKeyRequest represents the request to rotate an API key
+// OverlapSeconds is how long the previous token stays valid (DefaultRotationOverlap if
+// unset, 0 revokes it immediately)
+type RotateAPIKeyRequest struct {
+	OverlapSeconds *int `json:"overlap_seconds,omitempty"`
+}
+
+// Session token lifetimes
+// Session tokens are not checked against the database, so deleting their API key does not
+// revoke them; keep them short
+const (
+	DefaultSessionTokenTTL = 15 * time.Minute
+	MinSessionTokenTTL     = time.Minute
+	MaxSessionTokenTTL     = time.Hour
+)
+
+// CreateSessionTokenRequest represents the request to mint a session token from an API key
+// Scopes and Projects (by slug or ID) narrow the key's access; left empty the token has the
+// key's scopes and projects
+type CreateSessionTokenRequest struct {
+	TTLSeconds int      `json:"ttl_seconds,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	Projects   []string `json:"projects,omitempty"`
+}
+
+// SessionToken is a short-lived, signed token carrying the org, API key, scopes and expiry
+// it was minted with. It authenticates like an API key without a database lookup
+type SessionToken struct {
+	Token      string    `json:"token"`
+	OrgID      string    `json:"org_id"`
+	APIKeyID   string    `json:"api_key_id"`
+	Scopes     []string  `json:"scopes,omitempty"`
+	ProjectIDs []string  `json:"project_ids,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// OIDC trust policy token lifetimes
+const (
+	DefaultOIDCTokenTTL = 15 * time.Minute
+	MinOIDCTokenTTL     = time.Minute
+	MaxOIDCTokenTTL     = 12 * time.Hour
+)
+
+// OIDCTrustPolicy lets CI jobs exchange OIDC tokens of an issuer for short-lived API keys of an org
+// A token matches when every claim in Claims matches its pattern, where * matches any characters
+type OIDCTrustPolicy struct {
+	ID              string            `json:"id" db:"id"`
+	OrgID           string            `json:"org_id" db:"org_id"`
+	Name            string            `json:"name" db:"name"`
+	Issuer          string            `json:"issuer" db:"issuer"`
+	Claims          map[string]string `json:"claims" db:"claims"` // Stored as JSON
+	Scopes          []string          `json:"scopes,omitempty" db:"scopes"`
+	ProjectIDs      []string          `json:"project_ids,omitempty" db:"project_ids"`
+	TokenTTLSeconds int               `json:"token_ttl_seconds" db:"token_ttl_seconds"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+}
+
+// CreateOIDCTrustPolicyRequest represents the request to create an OIDC trust policy
+// Projects are given by slug or ID, as for API keys
+type CreateOIDCTrustPolicyRequest struct {
+	Name            string            `json:"name"`
+	Issuer          string            `json:"issuer"`
+	Claims          map[string]string `json:"claims"`
+	Scopes          []string          `json:"scopes,omitempty"`
+	Projects        []string          `json:"projects,omitempty"`
+	TokenTTLSeconds int               `json:"token_ttl_seconds,omitempty"`
+}
+
+// OIDCTokenExchangeRequest represents the request to exchange an OIDC token for an API key
+type OIDCTokenExchangeRequest struct {
+	Token string `json:"token"`
+}
+
 // OrganizationWithAPIKey is returned on org creation (includes the initial API key)
 type OrganizationWithAPIKey struct {
 	Organization
@@ -37,8 +249,12 @@ type OrganizationWithAPIKey struct {
 }
 
 // CreateAPIKeyRequest represents the request to create an API key
+// Projects are given by slug or ID; leaving Scopes and Projects empty creates a full-access key
 type CreateAPIKeyRequest struct {
-	Name string `json:"name"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	Projects  []string   `json:"projects,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // Project represents a project in the NahCloud system
@@ -53,16 +269,22 @@ type Project struct {
 
 // Instance represents a compute instance within a project
 type Instance struct {
-	ID        string    `json:"id" db:"id"`
-	ProjectID string    `json:"project_id" db:"project_id"`
-	Name      string    `json:"name" db:"name"`
-	Region    string    `json:"region" db:"region"`
-	CPU       int       `json:"cpu" db:"cpu"`
-	MemoryMB  int       `json:"memory_mb" db:"memory_mb"`
-	Image     string    `json:"image" db:"image"`
-	Status    string    `json:"status" db:"status"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID              string    `json:"id" db:"id"`
+	ProjectID       string    `json:"project_id" db:"project_id"`
+	Name            string    `json:"name" db:"name"`
+	Region          string    `json:"region" db:"region"`
+	Zone            string    `json:"zone,omitempty" db:"zone"`
+	SubnetID        string    `json:"subnet_id,omitempty" db:"subnet_id"`
+	PrivateIP       string    `json:"private_ip,omitempty" db:"private_ip"`               // allocated from the subnet
+	FirewallRuleIDs []string  `json:"firewall_rule_ids,omitempty" db:"firewall_rule_ids"` // Stored as JSON
+	InstanceType    string    `json:"instance_type,omitempty" db:"instance_type"`         // empty for custom CPU and memory
+	CPU             int       `json:"cpu" db:"cpu"`
+	MemoryMB        int       `json:"memory_mb" db:"memory_mb"`
+	Image           string    `json:"image" db:"image"`
+	Status          string    `json:"status" db:"status"`
+	Volumes         []string  `json:"volumes" db:"-"` // IDs of the attached volumes
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // InstanceStatus constants
@@ -80,13 +302,316 @@ const (
 	RegionAPEast1    = "ap-east-1"
 )
 
-// ValidRegions is the list of allowed regions
-var ValidRegions = []string{
-	RegionUSEast1,
-	RegionUSWest1,
-	RegionEUWest1,
-	RegionEUCentral1,
-	RegionAPEast1,
+// Region is a location instances run in, made of zones named after it (e.g. us-east-1a)
+// A region that is down simulates an outage: no instances can be created in it
+type Region struct {
+	Name  string   `json:"name" yaml:"name"`
+	Zones []string `json:"zones" yaml:"zones"`
+	Down  bool     `json:"down" yaml:"down"`
+}
+
+// HasZone reports whether a zone belongs to the region
+func (r *Region) HasZone(zone string) bool {
+	for _, z := range r.Zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// Zone is a zone of a region
+type Zone struct {
+	Name   string `json:"name"`
+	Region string `json:"region"`
+}
+
+// UpdateRegionRequest represents the request to update a region
+type UpdateRegionRequest struct {
+	Down *bool `json:"down,omitempty"`
+}
+
+// DefaultRegions are the regions used unless they are configured
+var DefaultRegions = []Region{
+	{Name: RegionUSEast1, Zones: []string{"us-east-1a", "us-east-1b", "us-east-1c"}},
+	{Name: RegionUSWest1, Zones: []string{"us-west-1a", "us-west-1b"}},
+	{Name: RegionEUWest1, Zones: []string{"eu-west-1a", "eu-west-1b", "eu-west-1c"}},
+	{Name: RegionEUCentral1, Zones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"}},
+	{Name: RegionAPEast1, Zones: []string{"ap-east-1a", "ap-east-1b", "ap-east-1c"}},
+}
+
+// InstanceType is a machine type of the instance type catalog
+// Regions lists where the type is offered; empty means every region
+type InstanceType struct {
+	Name     string   `json:"name" yaml:"name"`
+	CPU      int      `json:"cpu" yaml:"cpu"`
+	MemoryMB int      `json:"memory_mb" yaml:"memory_mb"`
+	Regions  []string `json:"regions,omitempty" yaml:"regions"`
+}
+
+// AvailableIn reports whether the type is offered in a region
+func (t *InstanceType) AvailableIn(region string) bool {
+	if len(t.Regions) == 0 {
+		return true
+	}
+	for _, r := range t.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultInstanceTypes is the instance type catalog used unless one is configured
+var DefaultInstanceTypes = []InstanceType{
+	{Name: "nah.micro", CPU: 1, MemoryMB: 512},
+	{Name: "nah.small", CPU: 1, MemoryMB: 2048},
+	{Name: "nah.medium", CPU: 2, MemoryMB: 4096},
+	{Name: "nah.large", CPU: 4, MemoryMB: 8192},
+	{Name: "nah.xlarge", CPU: 8, MemoryMB: 16384},
+	{Name: "nah.2xlarge", CPU: 16, MemoryMB: 32768},
+	{Name: "nah.highmem", CPU: 8, MemoryMB: 65536, Regions: []string{RegionUSEast1, RegionEUWest1}},
+}
+
+// Image is a boot image for instances
+// Public images are built in and shared by all orgs, private images belong to an org. The
+// images of a family are versions of one OS release, so instances can be created from the
+// family to get its latest image that is not deprecated
+type Image struct {
+	ID         string    `json:"id" db:"id"`
+	OrgID      string    `json:"org_id,omitempty" db:"org_id"` // empty for public images
+	Name       string    `json:"name" db:"name"`
+	Family     string    `json:"family" db:"family"`
+	Version    string    `json:"version" db:"version"`
+	OS         string    `json:"os" db:"os"`
+	Public     bool      `json:"public"`
+	Deprecated bool      `json:"deprecated" db:"deprecated"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Image OS constants
+const (
+	ImageOSLinux   = "linux"
+	ImageOSWindows = "windows"
+)
+
+// ValidImageOS is the list of allowed image operating systems
+var ValidImageOS = []string{
+	ImageOSLinux,
+	ImageOSWindows,
+}
+
+// publicImage returns a public image named after its family and version, a YYYYMMDD date
+func publicImage(family, version, os string, deprecated bool) Image {
+	released, _ := time.Parse("20060102", version)
+	name := family + "-v" + version
+	return Image{
+		ID:         name,
+		Name:       name,
+		Family:     family,
+		Version:    version,
+		OS:         os,
+		Public:     true,
+		Deprecated: deprecated,
+		CreatedAt:  released,
+		UpdatedAt:  released,
+	}
+}
+
+// PublicImages is the catalog of built-in images available to all orgs
+var PublicImages = []Image{
+	publicImage("ubuntu-20.04", "20240105", ImageOSLinux, true),
+	publicImage("ubuntu-22.04", "20240110", ImageOSLinux, true),
+	publicImage("ubuntu-22.04", "20250115", ImageOSLinux, false),
+	publicImage("ubuntu-24.04", "20240601", ImageOSLinux, false),
+	publicImage("ubuntu-24.04", "20250201", ImageOSLinux, false),
+	publicImage("debian-11", "20240110", ImageOSLinux, true),
+	publicImage("debian-12", "20240110", ImageOSLinux, false),
+	publicImage("debian-12", "20250110", ImageOSLinux, false),
+	publicImage("rocky-9", "20240601", ImageOSLinux, false),
+	publicImage("windows-2022", "20240611", ImageOSWindows, false),
+}
+
+// CreateImageRequest represents the request to create a private image
+// Name defaults to "<family>-v<version>"
+type CreateImageRequest struct {
+	Name    string `json:"name,omitempty"`
+	Family  string `json:"family"`
+	Version string `json:"version"`
+	OS      string `json:"os"`
+}
+
+// ImageListOptions represents query options for listing images
+type ImageListOptions struct {
+	OrgID  string
+	Family string
+}
+
+// Network is a private network (VPC) of a project, divided into subnets
+type Network struct {
+	ID        string    `json:"id" db:"id"`
+	ProjectID string    `json:"project_id" db:"project_id"`
+	Name      string    `json:"name" db:"name"`
+	CIDR      string    `json:"cidr" db:"cidr"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateNetworkRequest represents the request to create a network
+type CreateNetworkRequest struct {
+	Name string `json:"name"`
+	CIDR string `json:"cidr"`
+}
+
+// UpdateNetworkRequest represents the request to update a network
+// The CIDR of a network cannot be changed
+type UpdateNetworkRequest struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Subnet is an IP range of a network in one region, from which instances get their private IPs
+// The first address after the network address is the gateway
+type Subnet struct {
+	ID        string    `json:"id" db:"id"`
+	ProjectID string    `json:"project_id" db:"project_id"`
+	NetworkID string    `json:"network_id" db:"network_id"`
+	Name      string    `json:"name" db:"name"`
+	Region    string    `json:"region" db:"region"`
+	CIDR      string    `json:"cidr" db:"cidr"`
+	GatewayIP string    `json:"gateway_ip" db:"gateway_ip"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateSubnetRequest represents the request to create a subnet
+type CreateSubnetRequest struct {
+	NetworkID string `json:"network_id"`
+	Name      string `json:"name"`
+	Region    string `json:"region"`
+	CIDR      string `json:"cidr"`
+}
+
+// UpdateSubnetRequest represents the request to update a subnet
+// The network, region and CIDR of a subnet cannot be changed
+type UpdateSubnetRequest struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// SubnetListOptions represents query options for listing subnets
+type SubnetListOptions struct {
+	ProjectID string
+	NetworkID string
+}
+
+// FirewallRule is a security group of a project: the traffic allowed to and from the instances
+// it is attached to. Traffic that no entry allows is denied. NahCloud has no real traffic, so
+// the rules are only stored and validated
+type FirewallRule struct {
+	ID          string              `json:"id" db:"id"`
+	ProjectID   string              `json:"project_id" db:"project_id"`
+	Name        string              `json:"name" db:"name"`
+	Description string              `json:"description,omitempty" db:"description"`
+	Ingress     []FirewallRuleEntry `json:"ingress" db:"ingress"` // Stored as JSON
+	Egress      []FirewallRuleEntry `json:"egress" db:"egress"`   // Stored as JSON
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// FirewallRuleEntry allows one kind of traffic
+// CIDRs are the sources of ingress traffic and the destinations of egress traffic
+// TargetLabels narrow the entry to instances with one of the labels; empty applies to all
+type FirewallRuleEntry struct {
+	Protocol     string   `json:"protocol"`
+	Ports        []string `json:"ports,omitempty"` // e.g. "22" or "8000-8999", empty for all ports
+	CIDRs        []string `json:"cidrs"`
+	TargetLabels []string `json:"target_labels,omitempty"`
+}
+
+// Firewall protocol constants
+const (
+	FirewallProtocolTCP  = "tcp"
+	FirewallProtocolUDP  = "udp"
+	FirewallProtocolICMP = "icmp"
+	FirewallProtocolAll  = "all"
+)
+
+// ValidFirewallProtocols lists the protocols of firewall rule entries
+var ValidFirewallProtocols = []string{FirewallProtocolTCP, FirewallProtocolUDP, FirewallProtocolICMP, FirewallProtocolAll}
+
+// CreateFirewallRuleRequest represents the request to create a firewall rule
+type CreateFirewallRuleRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Ingress     []FirewallRuleEntry `json:"ingress"`
+	Egress      []FirewallRuleEntry `json:"egress"`
+}
+
+// UpdateFirewallRuleRequest represents the request to update a firewall rule
+// Ingress and Egress replace the entries of their direction as a whole
+type UpdateFirewallRuleRequest struct {
+	Name        *string              `json:"name,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Ingress     *[]FirewallRuleEntry `json:"ingress,omitempty"`
+	Egress      *[]FirewallRuleEntry `json:"egress,omitempty"`
+}
+
+// Volume is a block storage volume of a project, which can be attached to one instance at a
+// time in its region (and zone, if both have one)
+type Volume struct {
+	ID         string    `json:"id" db:"id"`
+	ProjectID  string    `json:"project_id" db:"project_id"`
+	Name       string    `json:"name" db:"name"`
+	SizeGB     int       `json:"size_gb" db:"size_gb"`
+	Type       string    `json:"type" db:"type"`
+	Region     string    `json:"region" db:"region"`
+	Zone       string    `json:"zone,omitempty" db:"zone"`
+	Status     string    `json:"status" db:"-"` // derived from InstanceID
+	InstanceID string    `json:"instance_id,omitempty" db:"instance_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Volume type constants
+const (
+	VolumeTypeStandard = "standard"
+	VolumeTypeSSD      = "ssd"
+)
+
+// ValidVolumeTypes lists the types of volumes
+var ValidVolumeTypes = []string{VolumeTypeStandard, VolumeTypeSSD}
+
+// Volume status constants
+const (
+	VolumeStatusAvailable = "available"
+	VolumeStatusInUse     = "in-use"
+)
+
+// CreateVolumeRequest represents the request to create a volume
+type CreateVolumeRequest struct {
+	Name   string `json:"name"`
+	SizeGB int    `json:"size_gb"`
+	Type   string `json:"type,omitempty"` // defaults to standard
+	Region string `json:"region"`
+	Zone   string `json:"zone,omitempty"`
+}
+
+// UpdateVolumeRequest represents the request to update a volume
+// Volumes can grow but not shrink; the type, region and zone cannot be changed
+type UpdateVolumeRequest struct {
+	Name   *string `json:"name,omitempty"`
+	SizeGB *int    `json:"size_gb,omitempty"`
+}
+
+// AttachVolumeRequest represents the request to attach a volume to an instance
+type AttachVolumeRequest struct {
+	InstanceID string `json:"instance_id"`
+}
+
+// VolumeListOptions represents query options for listing volumes
+type VolumeListOptions struct {
+	ProjectID  string
+	InstanceID string
 }
 
 // Metadata represents key-value metadata storage (org-scoped)
@@ -104,22 +629,295 @@ type Metadata struct {
 // Name must be unique within a project
 // Objects reference buckets by ID
 type Bucket struct {
-	ID        string    `json:"id" db:"id"`
-	ProjectID string    `json:"project_id" db:"project_id"`
-	Name      string    `json:"name" db:"name"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID             string          `json:"id" db:"id"`
+	ProjectID      string          `json:"project_id" db:"project_id"`
+	Name           string          `json:"name" db:"name"`
+	LifecycleRules []LifecycleRule `json:"lifecycle_rules,omitempty" db:"lifecycle_rules"` // Stored as JSON
+	Quota          StorageQuota    `json:"quota"`
+	Notifications  []Notification  `json:"notifications,omitempty" db:"notifications"` // Stored as JSON
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// MarshalJSON encodes the bucket without the secrets of its notifications, which anyone who
+// can read the bucket could otherwise use to sign fake deliveries
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	type bucket Bucket // without the MarshalJSON method
+	redacted := bucket(b)
+	redacted.Notifications = RedactNotifications(b.Notifications)
+	return json.Marshal(redacted)
+}
+
+// LifecycleRule describes automatic cleanup of objects in a bucket
+// A rule applies to objects whose path starts with Prefix (empty prefix matches all objects)
+// Day counts of zero disable the corresponding action
+type LifecycleRule struct {
+	ID                              string `json:"id"`
+	Prefix                          string `json:"prefix,omitempty"`
+	Disabled                        bool   `json:"disabled,omitempty"`
+	ExpirationDays                  int    `json:"expiration_days,omitempty"`                    // Delete objects not modified for N days
+	NoncurrentVersionExpirationDays int    `json:"noncurrent_version_expiration_days,omitempty"` // Delete versions N days after they were replaced
+	AbortIncompleteUploadDays       int    `json:"abort_incomplete_upload_days,omitempty"`       // Accepted for API compatibility, uploads are atomic
+}
+
+// BucketLifecycle represents the lifecycle configuration of a bucket
+type BucketLifecycle struct {
+	Rules []LifecycleRule `json:"rules"`
+}
+
+// LifecycleRunResult summarizes a single evaluation of all bucket lifecycle rules
+type LifecycleRunResult struct {
+	ExpiredObjects  int `json:"expired_objects"`
+	DeletedVersions int `json:"deleted_versions"`
+}
+
+// Object event types delivered to bucket notifications
+const (
+	ObjectEventCreated = "object.created"
+	ObjectEventUpdated = "object.updated"
+	ObjectEventDeleted = "object.deleted"
+)
+
+// ValidObjectEvents contains all object event types
+var ValidObjectEvents = []string{ObjectEventCreated, ObjectEventUpdated, ObjectEventDeleted}
+
+// Notification configures a webhook that receives object events of a bucket
+// An empty Events list subscribes to all events; Prefix filters by object path
+// Deliveries are signed with Secret (HMAC-SHA256), which is generated when not provided
+// The secret is only returned when the configuration is set, never when it is read
+type Notification struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Prefix string   `json:"prefix,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// RedactNotifications returns a copy of notifications without their secrets
+func RedactNotifications(notifications []Notification) []Notification {
+	if notifications == nil {
+		return nil
+	}
+	redacted := make([]Notification, len(notifications))
+	for i, n := range notifications {
+		n.Secret = ""
+		redacted[i] = n
+	}
+	return redacted
+}
+
+// BucketNotifications represents the notification configuration of a bucket
+type BucketNotifications struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+// ObjectEvent is the JSON payload POSTed to notification webhooks
+type ObjectEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	BucketID   string    `json:"bucket_id"`
+	BucketName string    `json:"bucket_name"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Version    int       `json:"version"`
+	Time       time.Time `json:"time"`
+}
+
+// Resource kinds and actions of ResourceEvent
+const (
+	ResourceKindProject  = "project"
+	ResourceKindInstance = "instance"
+	ResourceKindMetadata = "metadata"
+	ResourceKindBucket   = "bucket"
+	ResourceKindObject   = "object"
+
+	ResourceActionCreated = "created"
+	ResourceActionUpdated = "updated"
+	ResourceActionDeleted = "deleted"
+)
+
+// ResourceEvent announces a change to a resource of an org (used to live-update the console)
+// ProjectID and BucketID are set for resources that belong to a project or bucket
+type ResourceEvent struct {
+	Kind      string    `json:"kind"`
+	Action    string    `json:"action"`
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	ProjectID string    `json:"project_id,omitempty"`
+	BucketID  string    `json:"bucket_id,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// SearchResult is a resource of an org whose name or path matched a search
+// Name is the path for metadata and objects. URL is set by the API and console to link to the resource
+type SearchResult struct {
+	Kind        string `json:"kind"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ProjectID   string `json:"project_id,omitempty"`
+	ProjectSlug string `json:"project_slug,omitempty"`
+	BucketID    string `json:"bucket_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// RecentResource is a resource of an org with the time it was last changed
+type RecentResource struct {
+	SearchResult
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InstanceSummary counts the instances of an org
+type InstanceSummary struct {
+	Total    int            `json:"total"`
+	ByRegion map[string]int `json:"by_region"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+// OrgSummary is an inventory of an org's resources and recent activity
+type OrgSummary struct {
+	Projects      int               `json:"projects"`
+	Instances     InstanceSummary   `json:"instances"`
+	Buckets       int               `json:"buckets"`
+	Storage       StorageUsage      `json:"storage"`
+	TFStates      int               `json:"tfstates"`
+	TFStateLocks  []*TFStateSummary `json:"tfstate_locks"`
+	RecentChanges []*RecentResource `json:"recent_changes"`
+	APIKeys       []*APIKey         `json:"api_keys"`
+}
+
+// RateLimits are the API request limits, in requests per minute (0 means unlimited)
+// Each limit is a token bucket, so a full minute's worth of requests may be made at once
+type RateLimits struct {
+	Global int `json:"global"`  // across all orgs
+	PerOrg int `json:"per_org"` // per organization
+	PerKey int `json:"per_key"` // per API key, shared with its session tokens
+}
+
+// GlobalStats is the admin overview of all organizations
+type GlobalStats struct {
+	Organizations int             `json:"organizations"`
+	APIKeys       int             `json:"api_keys"`
+	Projects      int             `json:"projects"`
+	Instances     InstanceSummary `json:"instances"`
+	Buckets       int             `json:"buckets"`
+	Storage       StorageUsage    `json:"storage"`
+	TFStates      int             `json:"tfstates"`
+	TFStateLocks  int             `json:"tfstate_locks"`
+}
+
+// Notification delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// NotificationDelivery records an attempt to deliver an event to a webhook
+type NotificationDelivery struct {
+	ID             string    `json:"id" db:"id"`
+	BucketID       string    `json:"bucket_id" db:"bucket_id"`
+	NotificationID string    `json:"notification_id" db:"notification_id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	URL            string    `json:"url" db:"url"`
+	Status         string    `json:"status" db:"status"`
+	Attempts       int       `json:"attempts" db:"attempts"`
+	ResponseCode   int       `json:"response_code,omitempty" db:"response_code"`
+	Error          string    `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StorageQuota limits the storage used by a bucket or project
+// Zero values mean unlimited
+type StorageQuota struct {
+	MaxBytes   int64 `json:"max_bytes" db:"max_bytes"`
+	MaxObjects int64 `json:"max_objects" db:"max_objects"`
+}
+
+// ResourceQuota limits the projects of an org and the instances of each of its projects
+// The CPU and memory limits apply to the instances of a project in one region. Zero values
+// mean unlimited
+type ResourceQuota struct {
+	MaxProjects            int `json:"max_projects"`
+	MaxInstancesPerProject int `json:"max_instances_per_project"`
+	MaxCPUPerRegion        int `json:"max_cpu_per_region"`
+	MaxMemoryMBPerRegion   int `json:"max_memory_mb_per_region"`
+}
+
+// ResourceQuotaUsage reports the quota of an org and its current usage
+// Custom is set when the org has its own quota instead of the server default
+type ResourceQuotaUsage struct {
+	Quota    ResourceQuota          `json:"quota"`
+	Custom   bool                   `json:"custom"`
+	Projects int                    `json:"projects"`
+	Usage    []ProjectInstanceUsage `json:"usage"`
+}
+
+// ProjectInstanceUsage reports the instances of a project and their resources by region
+type ProjectInstanceUsage struct {
+	ProjectID   string                         `json:"project_id"`
+	ProjectSlug string                         `json:"project_slug"`
+	Instances   int                            `json:"instances"`
+	Regions     map[string]RegionInstanceUsage `json:"regions"`
+}
+
+// RegionInstanceUsage reports the resources of a project's instances in a region
+type RegionInstanceUsage struct {
+	Instances int `json:"instances"`
+	CPU       int `json:"cpu"`
+	MemoryMB  int `json:"memory_mb"`
+}
+
+// StorageUsage reports the storage currently used by a bucket or project
+// Only current object versions are counted
+type StorageUsage struct {
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// BucketUsage reports the usage and quota of a single bucket
+type BucketUsage struct {
+	BucketID   string       `json:"bucket_id"`
+	BucketName string       `json:"bucket_name"`
+	Usage      StorageUsage `json:"usage"`
+	Quota      StorageQuota `json:"quota"`
+}
+
+// ProjectStorageUsage reports the storage usage of a project broken down by bucket
+type ProjectStorageUsage struct {
+	ProjectID string        `json:"project_id"`
+	Usage     StorageUsage  `json:"usage"`
+	Quota     StorageQuota  `json:"quota"`
+	Buckets   []BucketUsage `json:"buckets"`
 }
 
 // Object represents a stored object within a bucket
-// Content is a base64-encoded string
+// Content is a base64-encoded string; Size is the decoded length in bytes
+// Version starts at 1 and is incremented each time the content changes;
+// previous contents are kept as noncurrent versions
+// ContentType and Metadata are user-supplied and preserved by copy and move
 type Object struct {
-	ID        string    `json:"id" db:"id"`
-	BucketID  string    `json:"bucket_id" db:"bucket_id"`
-	Path      string    `json:"path" db:"path"`
-	Content   string    `json:"content" db:"content"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          string            `json:"id" db:"id"`
+	BucketID    string            `json:"bucket_id" db:"bucket_id"`
+	Path        string            `json:"path" db:"path"`
+	Content     string            `json:"content" db:"content"`
+	Size        int64             `json:"size" db:"size"`
+	ContentType string            `json:"content_type,omitempty" db:"content_type"`
+	Metadata    map[string]string `json:"metadata,omitempty" db:"metadata"` // Stored as JSON
+	Version     int               `json:"version" db:"version"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ContentSize returns the decoded size in bytes of base64 object content
+// Content that is not valid base64 is counted by its raw length
+func ContentSize(content string) int64 {
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return int64(len(content))
+	}
+	return int64(len(decoded))
 }
 
 // TFStateLock represents Terraform's HTTP backend lock payload
@@ -135,6 +933,67 @@ type TFStateLock struct {
 	Path      string    `json:"Path,omitempty"`
 }
 
+// TFState is the subset of a Terraform state file that NahCloud understands
+// See: https://developer.hashicorp.com/terraform/internals/json-format
+type TFState struct {
+	Version          int                      `json:"version"`
+	TerraformVersion string                   `json:"terraform_version"`
+	Serial           int64                    `json:"serial"`
+	Lineage          string                   `json:"lineage"`
+	Outputs          map[string]TFStateOutput `json:"outputs"`
+	Resources        []TFStateResource        `json:"resources"`
+}
+
+// TFStateOutput represents a root module output in a Terraform state
+type TFStateOutput struct {
+	Value     interface{} `json:"value"`
+	Type      interface{} `json:"type,omitempty"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+// TFStateResource represents a resource in a Terraform state
+type TFStateResource struct {
+	Module    string            `json:"module,omitempty"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	Instances []json.RawMessage `json:"instances"`
+}
+
+// Address returns the resource address as Terraform prints it, e.g. module.net.data.nah_region.this
+func (r TFStateResource) Address() string {
+	addr := r.Type + "." + r.Name
+	if r.Mode == "data" {
+		addr = "data." + addr
+	}
+	if r.Module != "" {
+		addr = r.Module + "." + addr
+	}
+	return addr
+}
+
+// TFStateSummary describes a stored Terraform state without its content
+type TFStateSummary struct {
+	ID               string       `json:"id"`
+	Serial           int64        `json:"serial"`
+	Lineage          string       `json:"lineage"`
+	TerraformVersion string       `json:"terraform_version"`
+	Resources        int          `json:"resources"`
+	Size             int          `json:"size"`
+	Lock             *TFStateLock `json:"lock,omitempty"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// ConsoleSession represents a signed-in web console session
+// Sessions are bound to the API key used to sign in and end when that key is deleted
+type ConsoleSession struct {
+	OrgID     string    `json:"org_id"`
+	APIKeyID  string    `json:"api_key_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CSRFToken string    `json:"-"`
+}
+
 // Organization request/response types
 
 // CreateOrganizationRequest represents the request to create an organization
@@ -165,23 +1024,31 @@ type UpdateProjectRequest struct {
 }
 
 // CreateInstanceRequest represents the request to create an instance
+// With an InstanceType, CPU and MemoryMB may be left out (they are taken from the type)
 type CreateInstanceRequest struct {
-	ProjectID string `json:"project_id"`
-	Name      string `json:"name"`
-	Region    string `json:"region"`
-	CPU       int    `json:"cpu"`
-	MemoryMB  int    `json:"memory_mb"`
-	Image     string `json:"image"`
-	Status    string `json:"status,omitempty"`
+	ProjectID       string   `json:"project_id"`
+	Name            string   `json:"name"`
+	Region          string   `json:"region"`
+	Zone            string   `json:"zone,omitempty"`              // optional, must be a zone of Region
+	SubnetID        string   `json:"subnet_id,omitempty"`         // optional, a subnet of the project in Region
+	FirewallRuleIDs []string `json:"firewall_rule_ids,omitempty"` // optional, firewall rules of the project
+	InstanceType    string   `json:"instance_type,omitempty"`
+	CPU             int      `json:"cpu"`
+	MemoryMB        int      `json:"memory_mb"`
+	Image           string   `json:"image"`
+	Status          string   `json:"status,omitempty"`
 }
 
 // UpdateInstanceRequest represents the request to update an instance
+// Changing InstanceType resizes the instance; setting it to "" keeps the size as custom CPU and memory
 type UpdateInstanceRequest struct {
-	Name     *string `json:"name,omitempty"`
-	CPU      *int    `json:"cpu,omitempty"`
-	MemoryMB *int    `json:"memory_mb,omitempty"`
-	Image    *string `json:"image,omitempty"`
-	Status   *string `json:"status,omitempty"`
+	Name            *string   `json:"name,omitempty"`
+	InstanceType    *string   `json:"instance_type,omitempty"`
+	CPU             *int      `json:"cpu,omitempty"`
+	MemoryMB        *int      `json:"memory_mb,omitempty"`
+	Image           *string   `json:"image,omitempty"`
+	Status          *string   `json:"status,omitempty"`
+	FirewallRuleIDs *[]string `json:"firewall_rule_ids,omitempty"` // replaces the attached firewall rules
 }
 
 // ProjectListOptions represents query options for listing projects
@@ -197,6 +1064,7 @@ type InstanceListOptions struct {
 	Name      string
 	Region    string
 	Status    string
+	SubnetID  string
 }
 
 // CreateMetadataRequest represents the request to create metadata
@@ -218,6 +1086,19 @@ type MetadataListOptions struct {
 	Prefix string
 }
 
+// SearchOptions represents query options for searching the resources of an org
+type SearchOptions struct {
+	OrgID string
+	Query string
+	Kind  string
+	Limit int
+
+	// Kinds and ProjectIDs limit results to what an API key can read; nil means no limit
+	// Resources outside any project (metadata) pass the project limit
+	Kinds      []string
+	ProjectIDs []string
+}
+
 // CreateBucketRequest represents the request to create a bucket
 type CreateBucketRequest struct {
 	ProjectID string `json:"project_id"`
@@ -237,15 +1118,55 @@ type BucketListOptions struct {
 
 // CreateObjectRequest represents the request to create an object
 type CreateObjectRequest struct {
-	BucketID string `json:"bucket_id"`
-	Path     string `json:"path"`
-	Content  string `json:"content"`
+	BucketID    string            `json:"bucket_id"`
+	Path        string            `json:"path"`
+	Content     string            `json:"content"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 // UpdateObjectRequest represents the request to update an object
 type UpdateObjectRequest struct {
-	Path    *string `json:"path,omitempty"`
-	Content *string `json:"content,omitempty"`
+	Path        *string            `json:"path,omitempty"`
+	Content     *string            `json:"content,omitempty"`
+	ContentType *string            `json:"content_type,omitempty"`
+	Metadata    *map[string]string `json:"metadata,omitempty"` // Replaces all metadata when set
+}
+
+// Metadata directives for object copy and move
+const (
+	MetadataDirectiveCopy    = "COPY"    // Keep the source content type and metadata (default)
+	MetadataDirectiveReplace = "REPLACE" // Use the content type and metadata from the request
+)
+
+// CopyObjectRequest represents the request to copy or move an object
+// Destination project and bucket default to the source and must be in the same organization
+// An existing destination is only replaced when Overwrite is set, and then only if its
+// version equals IfMatchVersion (when given)
+type CopyObjectRequest struct {
+	DestinationProject string            `json:"destination_project,omitempty"` // Project slug
+	DestinationBucket  string            `json:"destination_bucket,omitempty"`  // Bucket name
+	DestinationPath    string            `json:"destination_path,omitempty"`
+	Overwrite          bool              `json:"overwrite,omitempty"`
+	IfMatchVersion     *int              `json:"if_match_version,omitempty"`
+	MetadataDirective  string            `json:"metadata_directive,omitempty"`
+	ContentType        string            `json:"content_type,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+}
+
+// PresignObjectRequest represents the request to create a presigned object URL
+type PresignObjectRequest struct {
+	Method    string `json:"method"`               // GET (download) or PUT (upload)
+	Path      string `json:"path"`                 // Object path within the bucket
+	ExpiresIn int    `json:"expires_in,omitempty"` // Validity in seconds
+}
+
+// PresignedObjectURL is a time-limited URL granting access to a single object path
+type PresignedObjectURL struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // ObjectListOptions represents query options for listing objects
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_HasScope(t *testing.T) {
+	full := &APIKey{}
+	assert.True(t, full.HasScope(ScopeAPIKeysWrite))
+
+	key := &APIKey{Scopes: []string{ScopeInstancesWrite, ScopeTFStateRead}}
+	assert.True(t, key.HasScope(ScopeInstancesWrite))
+	assert.True(t, key.HasScope(ScopeInstancesRead), "write implies read")
+	assert.True(t, key.HasScope(ScopeTFStateRead))
+	assert.False(t, key.HasScope(ScopeTFStateWrite))
+	assert.False(t, key.HasScope(ScopeTFStateLock))
+	assert.False(t, key.HasScope(ScopeProjectsRead))
+}
+
+func TestAPIKey_AllowsProject(t *testing.T) {
+	assert.True(t, (&APIKey{}).AllowsProject("p1"))
+
+	key := &APIKey{ProjectIDs: []string{"p1"}}
+	assert.True(t, key.AllowsProject("p1"))
+	assert.False(t, key.AllowsProject("p2"))
+}
+
+func TestAPIKey_Expired(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Minute)
+	key := &APIKey{ExpiresAt: &expiresAt}
+
+	assert.False(t, (&APIKey{}).Expired(now))
+	assert.False(t, key.Expired(now))
+	assert.True(t, key.Expired(expiresAt))
+}
+
+func TestAPIKey_CanGrant(t *testing.T) {
+	now := time.Now()
+	sooner, later := now.Add(time.Hour), now.Add(2*time.Hour)
+
+	full := &APIKey{}
+	scoped := &APIKey{Scopes: []string{ScopeInstancesWrite}, ProjectIDs: []string{"p1"}, ExpiresAt: &later}
+
+	tests := []struct {
+		name     string
+		key      *APIKey
+		other    *APIKey
+		expected bool
+	}{
+		{"full grants anything", full, &APIKey{}, true},
+		{"scoped cannot grant full", scoped, &APIKey{ProjectIDs: []string{"p1"}, ExpiresAt: &sooner}, false},
+		{"scoped grants implied read", scoped, &APIKey{Scopes: []string{ScopeInstancesRead}, ProjectIDs: []string{"p1"}, ExpiresAt: &sooner}, true},
+		{"scoped cannot grant other scope", scoped, &APIKey{Scopes: []string{ScopeBucketsRead}, ProjectIDs: []string{"p1"}, ExpiresAt: &sooner}, false},
+		{"scoped cannot grant other project", scoped, &APIKey{Scopes: []string{ScopeInstancesRead}, ProjectIDs: []string{"p1", "p2"}, ExpiresAt: &sooner}, false},
+		{"scoped cannot grant every project", scoped, &APIKey{Scopes: []string{ScopeInstancesRead}, ExpiresAt: &sooner}, false},
+		{"expiring cannot grant non-expiring", scoped, &APIKey{Scopes: []string{ScopeInstancesRead}, ProjectIDs: []string{"p1"}}, false},
+		{"expiring grants same expiry", scoped, &APIKey{Scopes: []string{ScopeInstancesRead}, ProjectIDs: []string{"p1"}, ExpiresAt: &later}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.key.CanGrant(tt.other))
+		})
+	}
+}
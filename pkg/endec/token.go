@@ -8,7 +8,16 @@ import (
 
 // Token prefixes (3 chars)
 const (
-	PrefixAPI = "api" // api key
+	PrefixAPI       = "api" // api key
+	PrefixSignature = "sig" // presigned URL signature
+	PrefixWebhook   = "whk" // webhook signing secret
+	PrefixConsole   = "web" // web console session cookie
+	PrefixAdmin     = "adm" // admin API token
+	PrefixSession   = "ses" // short-lived session token minted from an API key
+	PrefixRefresh   = "ref" // refresh token (reserved)
+	PrefixInvite    = "inv" // invitation token (reserved)
+	PrefixReset     = "rst" // reset token (reserved)
+	PrefixVerify    = "vfy" // verification token (reserved)
 )
 
 // CreateToken generates a nah token with the given prefix and random payload
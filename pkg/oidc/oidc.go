@@ -0,0 +1,227 @@
+// Package oidc verifies OpenID Connect ID tokens (JWTs) issued by CI systems such as
+// GitHub Actions and GitLab, using each issuer's JSON Web Key Set
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// leeway is the clock skew tolerated when checking exp and nbf
+const leeway = time.Minute
+
+// Issuer configures a trusted token issuer
+// The key set is read from JWKSFile if set, otherwise fetched from JWKSURL
+type Issuer struct {
+	URL      string // the iss claim of its tokens
+	JWKSURL  string
+	JWKSFile string
+	Audience string // tokens must include it in their aud claim
+}
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// String returns a claim as a string, formatting numbers and booleans
+// Missing and structured claims are returned as ""
+func (c Claims) String(name string) string {
+	switch v := c[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// Verifier verifies tokens of a fixed set of issuers
+type Verifier struct {
+	issuers map[string]*issuer
+	now     func() time.Time
+}
+
+type issuer struct {
+	config Issuer
+	keys   *keySet
+}
+
+// NewVerifier creates a verifier for the issuers
+func NewVerifier(issuers []Issuer) (*Verifier, error) {
+	v := &Verifier{issuers: map[string]*issuer{}, now: time.Now}
+	for _, iss := range issuers {
+		if iss.URL == "" {
+			return nil, errors.New("issuer URL is required")
+		}
+		if iss.JWKSURL == "" && iss.JWKSFile == "" {
+			return nil, fmt.Errorf("issuer %s needs a JWKS URL or file", iss.URL)
+		}
+		// Without it, tokens the issuer mints for any other service would be accepted
+		if iss.Audience == "" {
+			return nil, fmt.Errorf("issuer %s needs an audience", iss.URL)
+		}
+		if _, ok := v.issuers[iss.URL]; ok {
+			return nil, fmt.Errorf("issuer %s is configured twice", iss.URL)
+		}
+		v.issuers[iss.URL] = &issuer{config: iss, keys: newKeySet(iss.JWKSURL, iss.JWKSFile)}
+	}
+	return v, nil
+}
+
+// Issuers returns the URLs of the configured issuers
+func (v *Verifier) Issuers() []string {
+	urls := make([]string, 0, len(v.issuers))
+	for url := range v.issuers {
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature, issuer, audience and validity period of a token and
+// returns its claims
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var hdr header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	iss, ok := v.issuers[claims.String("iss")]
+	if !ok {
+		return nil, fmt.Errorf("untrusted issuer %q", claims.String("iss"))
+	}
+
+	key, err := iss.keys.get(hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(hdr.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return nil, errors.New("token has no expiry")
+	}
+	if now.After(exp.Add(leeway)) {
+		return nil, errors.New("token has expired")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if !hasAudience(claims["aud"], iss.config.Audience) {
+		return nil, fmt.Errorf("token audience does not include %q", iss.config.Audience)
+	}
+
+	return claims, nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// numericDate converts a NumericDate claim (seconds since the epoch) to a time
+func numericDate(v interface{}) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// hasAudience reports whether an aud claim (a string or an array of strings) includes audience
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifySignature checks a JWS signature made with one of the RS* or ES* algorithms
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match an RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match an EC key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+	return nil
+}
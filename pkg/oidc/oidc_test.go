@@ -0,0 +1,186 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://token.actions.example.com"
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(hdr) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(signature)
+}
+
+func writeKeySet(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	t.Helper()
+	doc := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}}
+	data, _ := json.Marshal(doc)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwksFile := writeKeySet(t, rsaKey, ecKey)
+
+	v, err := NewVerifier([]Issuer{{URL: testIssuer, JWKSFile: jwksFile, Audience: "nahcloud"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": testIssuer,
+			"aud": "nahcloud",
+			"sub": "repo:acme/app:ref:refs/heads/main",
+			"exp": now.Add(5 * time.Minute).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+		for k, val := range overrides {
+			if val == nil {
+				delete(c, k)
+			} else {
+				c[k] = val
+			}
+		}
+		return c
+	}
+
+	for _, tt := range []struct {
+		name  string
+		token string
+	}{
+		{"RS256", sign(t, "RS256", "rsa-1", rsaKey, claims(nil))},
+		{"ES256", sign(t, "ES256", "ec-1", ecKey, claims(nil))},
+		{"audience list", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": []string{"other", "nahcloud"}}))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(tt.token)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if got.String("sub") != "repo:acme/app:ref:refs/heads/main" {
+				t.Errorf("unexpected sub %q", got.String("sub"))
+			}
+		})
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	valid := sign(t, "RS256", "rsa-1", rsaKey, claims(nil))
+	for _, tt := range []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{"malformed", "not-a-jwt", "malformed"},
+		{"tampered", valid[:strings.LastIndex(valid, ".")] + "." + b64([]byte("bogus")), "signature"},
+		{"wrong key", sign(t, "RS256", "rsa-1", otherKey, claims(nil)), "signature"},
+		{"unknown kid", sign(t, "RS256", "rsa-2", rsaKey, claims(nil)), "unknown signing key"},
+		{"wrong algorithm", sign(t, "ES256", "rsa-1", ecKey, claims(nil)), "does not match"},
+		{"untrusted issuer", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})), "untrusted issuer"},
+		{"expired", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"exp": now.Add(-5 * time.Minute).Unix()})), "expired"},
+		{"no expiry", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"exp": nil})), "no expiry"},
+		{"not yet valid", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"nbf": now.Add(5 * time.Minute).Unix()})), "not valid yet"},
+		{"wrong audience", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": "other"})), "audience"},
+		{"wrong audience list", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": []string{"other", "nahcloud-dev"}})), "audience"},
+		{"no audience", sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": nil})), "audience"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.token)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestVerify_KeySetURL(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	data, err := os.ReadFile(writeKeySet(t, rsaKey, ecKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	v, err := NewVerifier([]Issuer{{URL: testIssuer, JWKSURL: server.URL, Audience: "nahcloud"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := sign(t, "RS256", "rsa-1", rsaKey, map[string]interface{}{"iss": testIssuer, "aud": "nahcloud", "exp": time.Now().Add(time.Minute).Unix()})
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(token); err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the key set to be fetched once, got %d", fetches)
+	}
+}
+
+func TestNewVerifier_InvalidConfig(t *testing.T) {
+	for name, issuers := range map[string][]Issuer{
+		"no URL":      {{JWKSFile: "jwks.json", Audience: "nahcloud"}},
+		"no JWKS":     {{URL: testIssuer, Audience: "nahcloud"}},
+		"no audience": {{URL: testIssuer, JWKSFile: "jwks.json"}},
+		"repeated":    {{URL: testIssuer, JWKSFile: "a.json", Audience: "nahcloud"}, {URL: testIssuer, JWKSFile: "b.json", Audience: "nahcloud"}},
+	} {
+		if _, err := NewVerifier(issuers); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package ratelimit implements in-memory token bucket rate limits
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have refilled completely are dropped
+const sweepInterval = time.Minute
+
+// Limit allows bursts of up to Burst requests, refilled at Rate requests per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a limit of n requests per minute, all of which may be made at once
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Request takes a token from the bucket Key, which is subject to Limit
+type Request struct {
+	Key   string
+	Limit Limit
+}
+
+// Result is the outcome of Allow, reported for the most restrictive bucket
+type Result struct {
+	Allowed    bool
+	Limit      int           // the burst of the bucket
+	Remaining  int           // requests left in the bucket
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until a request would be allowed, if it was denied
+}
+
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens accrued since the bucket was last updated
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate)
+	b.updated = now
+}
+
+// until returns how long it takes to refill the bucket to n tokens
+func (b *bucket) until(n float64) time.Duration {
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration(math.Ceil((n - b.tokens) / b.limit.Rate * float64(time.Second)))
+}
+
+// Limiter tracks token buckets by key
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// New creates a limiter
+func New() *Limiter {
+	return &Limiter{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+// Allow takes a token from each of the requested buckets if all of them have one, and
+// takes none otherwise. Requests whose limit has no burst or rate are ignored
+func (l *Limiter) Allow(reqs ...Request) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	var buckets []*bucket
+	for _, req := range reqs {
+		if req.Limit.Burst <= 0 || req.Limit.Rate <= 0 {
+			continue
+		}
+		b, ok := l.buckets[req.Key]
+		if !ok || b.limit != req.Limit {
+			b = &bucket{limit: req.Limit, tokens: float64(req.Limit.Burst), updated: now}
+			l.buckets[req.Key] = b
+		}
+		b.refill(now)
+		buckets = append(buckets, b)
+	}
+	if len(buckets) == 0 {
+		return Result{Allowed: true}
+	}
+
+	result := Result{Allowed: true}
+	for _, b := range buckets {
+		if b.tokens < 1 {
+			result.Allowed = false
+			result.RetryAfter = max(result.RetryAfter, b.until(1))
+		}
+	}
+	if result.Allowed {
+		for _, b := range buckets {
+			b.tokens--
+		}
+	}
+
+	// Report the bucket with the fewest requests left
+	var tightest *bucket
+	for _, b := range buckets {
+		if tightest == nil || b.tokens < tightest.tokens {
+			tightest = b
+		}
+	}
+	result.Limit = tightest.limit.Burst
+	result.Remaining = int(math.Max(0, math.Floor(tightest.tokens)))
+	result.Reset = tightest.until(float64(tightest.limit.Burst))
+	return result
+}
+
+// sweep drops the buckets that are full, which behave like new ones
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter() (*Limiter, *clock) {
+	c := &clock{t: time.Unix(1700000000, 0)}
+	l := New()
+	l.now = c.now
+	return l, c
+}
+
+func TestAllow(t *testing.T) {
+	l, c := newTestLimiter()
+	limit := PerMinute(3)
+
+	for i := 2; i >= 0; i-- {
+		r := l.Allow(Request{Key: "org", Limit: limit})
+		if !r.Allowed {
+			t.Fatalf("request %d should be allowed", 3-i)
+		}
+		if r.Limit != 3 || r.Remaining != i {
+			t.Errorf("expected limit 3 and %d remaining, got %d and %d", i, r.Limit, r.Remaining)
+		}
+	}
+
+	r := l.Allow(Request{Key: "org", Limit: limit})
+	if r.Allowed {
+		t.Fatal("fourth request should be denied")
+	}
+	if r.RetryAfter != 20*time.Second {
+		t.Errorf("expected retry after 20s, got %s", r.RetryAfter)
+	}
+	if r.Reset != time.Minute {
+		t.Errorf("expected reset after 1m, got %s", r.Reset)
+	}
+
+	// Other keys have their own buckets
+	if r := l.Allow(Request{Key: "other", Limit: limit}); !r.Allowed {
+		t.Error("other key should be allowed")
+	}
+
+	c.advance(20 * time.Second)
+	if r := l.Allow(Request{Key: "org", Limit: limit}); !r.Allowed {
+		t.Error("request should be allowed after refill")
+	}
+}
+
+func TestAllow_AllOrNothing(t *testing.T) {
+	l, _ := newTestLimiter()
+	org := Request{Key: "org", Limit: PerMinute(1)}
+	key := Request{Key: "key", Limit: PerMinute(10)}
+
+	if r := l.Allow(org, key); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("first request should be allowed with 0 remaining, got %+v", r)
+	}
+	if r := l.Allow(org, key); r.Allowed {
+		t.Fatal("second request should be denied by the org bucket")
+	}
+
+	// The denied request did not take a token from the key bucket
+	if r := l.Allow(key); r.Remaining != 8 {
+		t.Errorf("expected 8 remaining, got %d", r.Remaining)
+	}
+}
+
+func TestAllow_NoLimits(t *testing.T) {
+	l, _ := newTestLimiter()
+	for i := 0; i < 100; i++ {
+		if r := l.Allow(Request{Key: "org"}); !r.Allowed || r.Limit != 0 {
+			t.Fatalf("unlimited request should be allowed, got %+v", r)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l, c := newTestLimiter()
+	l.Allow(Request{Key: "idle", Limit: PerMinute(60)})
+	l.Allow(Request{Key: "busy", Limit: Limit{Rate: 1.0 / 120, Burst: 1}})
+
+	c.advance(sweepInterval)
+	l.Allow()
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("full bucket should be dropped")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("bucket that is still refilling should be kept")
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"crypto/subtle"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// SetAdminToken sets the token that authenticates the admin API
+// An empty token disables the admin API
+func (s *Service) SetAdminToken(token string) {
+	s.adminTokenHash = ""
+	if token != "" {
+		s.adminTokenHash = hashToken(token)
+	}
+}
+
+// AuthenticateAdmin checks a token against the admin token
+func (s *Service) AuthenticateAdmin(token string) error {
+	if s.adminTokenHash == "" {
+		return domain.UnauthorizedError("admin API is disabled")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(s.adminTokenHash)) != 1 {
+		return domain.UnauthorizedError("invalid admin token")
+	}
+	return nil
+}
+
+// SetPublicOrgCreation sets whether anyone can create organizations through POST /v1/orgs
+// Organizations can always be created through the admin API
+func (s *Service) SetPublicOrgCreation(enabled bool) {
+	s.orgCreationDisabled = !enabled
+}
+
+// PublicOrgCreation reports whether anyone can create organizations
+func (s *Service) PublicOrgCreation() bool {
+	return !s.orgCreationDisabled
+}
+
+// ResetAPIKeys revokes every API key of an organization and creates a new full-access key
+func (s *Service) ResetAPIKeys(orgID string) (*domain.APIKeyWithToken, error) {
+	if _, err := s.orgRepo.GetByID(orgID); err != nil {
+		return nil, err
+	}
+	if err := s.apiKeyRepo.DeleteByOrgID(orgID); err != nil {
+		return nil, err
+	}
+	return s.createAPIKey(&domain.APIKey{OrgID: orgID, Name: "default"})
+}
+
+// GetGlobalStats adds up the summaries of all organizations
+func (s *Service) GetGlobalStats() (*domain.GlobalStats, error) {
+	orgs, err := s.orgRepo.List(domain.OrganizationListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.GlobalStats{
+		Organizations: len(orgs),
+		Instances: domain.InstanceSummary{
+			ByRegion: map[string]int{},
+			ByStatus: map[string]int{},
+		},
+	}
+	for _, org := range orgs {
+		summary, err := s.GetOrgSummary(org.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		stats.APIKeys += len(summary.APIKeys)
+		stats.Projects += summary.Projects
+		stats.Instances.Total += summary.Instances.Total
+		for region, n := range summary.Instances.ByRegion {
+			stats.Instances.ByRegion[region] += n
+		}
+		for status, n := range summary.Instances.ByStatus {
+			stats.Instances.ByStatus[status] += n
+		}
+		stats.Buckets += summary.Buckets
+		stats.Storage.Bytes += summary.Storage.Bytes
+		stats.Storage.Objects += summary.Storage.Objects
+		stats.TFStates += summary.TFStates
+		stats.TFStateLocks += len(summary.TFStateLocks)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticateAdmin(t *testing.T) {
+	svc := setupTestService(t)
+
+	assert.True(t, domain.IsUnauthorized(svc.AuthenticateAdmin("")), "admin API is disabled without a token")
+
+	svc.SetAdminToken("admin-secret")
+	assert.NoError(t, svc.AuthenticateAdmin("admin-secret"))
+	assert.True(t, domain.IsUnauthorized(svc.AuthenticateAdmin("admin-secre")))
+	assert.True(t, domain.IsUnauthorized(svc.AuthenticateAdmin("")))
+}
+
+func TestResetAPIKeys(t *testing.T) {
+	svc := setupTestService(t)
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+	_, err = svc.CreateAPIKey(org.ID, domain.CreateAPIKeyRequest{Name: "ci"}, nil)
+	require.NoError(t, err)
+
+	key, err := svc.ResetAPIKeys(org.ID)
+	require.NoError(t, err)
+
+	keys, err := svc.ListAPIKeys(org.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.ID, keys[0].ID)
+	assert.False(t, keys[0].Restricted())
+
+	// The old keys no longer work
+	_, err = svc.GetOrganizationByToken(org.APIKey.Token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	_, err = svc.GetOrganizationByToken(key.Token)
+	assert.NoError(t, err)
+
+	_, err = svc.ResetAPIKeys("missing")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestGetGlobalStats(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	_, err = svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: "web", Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	require.NoError(t, err)
+	_, err = svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+
+	stats, err := svc.GetGlobalStats()
+	require.NoError(t, err)
+	// The database also has its default organization, which has no API keys
+	assert.Equal(t, 3, stats.Organizations)
+	assert.Equal(t, 2, stats.APIKeys)
+	assert.Equal(t, 1, stats.Projects)
+	assert.Equal(t, 1, stats.Instances.Total)
+	assert.Equal(t, 1, stats.Instances.ByRegion["us-east-1"])
+	assert.Equal(t, 1, stats.Buckets)
+}
+
+func TestPublicOrgCreation(t *testing.T) {
+	svc := setupTestService(t)
+	assert.True(t, svc.PublicOrgCreation())
+	svc.SetPublicOrgCreation(false)
+	assert.False(t, svc.PublicOrgCreation())
+}
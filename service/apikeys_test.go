@@ -0,0 +1,194 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey_Scoped(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	expiresAt := time.Now().Add(time.Hour)
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{
+		Name:      "ci",
+		Scopes:    []string{domain.ScopeInstancesWrite, domain.ScopeTFStateLock, domain.ScopeInstancesWrite},
+		Projects:  []string{"test-project", project.ID},
+		ExpiresAt: &expiresAt,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.ScopeInstancesWrite, domain.ScopeTFStateLock}, key.Scopes)
+	assert.Equal(t, []string{project.ID}, key.ProjectIDs)
+	require.NotNil(t, key.ExpiresAt)
+
+	authKey, org, err := svc.AuthenticateAPIKey(key.Token)
+	require.NoError(t, err)
+	assert.Equal(t, project.OrgID, org.ID)
+	assert.Equal(t, key.Scopes, authKey.Scopes)
+	assert.Equal(t, key.ProjectIDs, authKey.ProjectIDs)
+	assert.True(t, authKey.HasScope(domain.ScopeInstancesRead))
+	assert.False(t, authKey.HasScope(domain.ScopeProjectsWrite))
+}
+
+func TestCreateAPIKey_InvalidInput(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	otherProject, err := svc.CreateProject(other.ID, domain.CreateProjectRequest{Slug: "other-project", Name: "Other Project"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Scopes: []string{"projects:admin"}}, nil)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{ExpiresAt: &past}, nil)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// Projects of other orgs are not found, by slug or ID
+	for _, ref := range []string{"missing", otherProject.Slug, otherProject.ID} {
+		_, err = svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Projects: []string{ref}}, nil)
+		assert.True(t, domain.IsForeignKeyViolation(err), "expected foreign key violation for %s, got %v", ref, err)
+	}
+}
+
+func TestCreateAPIKey_CannotEscalate(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+	orgID := project.OrgID
+
+	expiresAt := time.Now().Add(time.Hour)
+	creator, err := svc.CreateAPIKey(orgID, domain.CreateAPIKeyRequest{
+		Scopes:    []string{domain.ScopeAPIKeysWrite, domain.ScopeInstancesRead},
+		Projects:  []string{project.Slug},
+		ExpiresAt: &expiresAt,
+	}, nil)
+	require.NoError(t, err)
+
+	later := expiresAt.Add(time.Hour)
+	sooner := expiresAt.Add(-time.Minute)
+	for name, req := range map[string]domain.CreateAPIKeyRequest{
+		"full access":     {ExpiresAt: &sooner},
+		"wider scope":     {Scopes: []string{domain.ScopeInstancesWrite}, Projects: []string{project.Slug}, ExpiresAt: &sooner},
+		"every project":   {Scopes: []string{domain.ScopeInstancesRead}, ExpiresAt: &sooner},
+		"no expiry":       {Scopes: []string{domain.ScopeInstancesRead}, Projects: []string{project.Slug}},
+		"longer lifetime": {Scopes: []string{domain.ScopeInstancesRead}, Projects: []string{project.Slug}, ExpiresAt: &later},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateAPIKey(orgID, req, &creator.APIKey)
+			assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+		})
+	}
+
+	narrower, err := svc.CreateAPIKey(orgID, domain.CreateAPIKeyRequest{
+		Scopes:    []string{domain.ScopeInstancesRead},
+		Projects:  []string{project.Slug},
+		ExpiresAt: &sooner,
+	}, &creator.APIKey)
+	require.NoError(t, err)
+
+	// The default key has full access, so the restricted key cannot revoke it
+	keys, err := svc.ListAPIKeys(orgID)
+	require.NoError(t, err)
+	for _, key := range keys {
+		if key.Name == "default" {
+			err = svc.DeleteAPIKey(orgID, key.ID, &creator.APIKey)
+			assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+		}
+	}
+	require.NoError(t, svc.DeleteAPIKey(orgID, narrower.ID, &creator.APIKey))
+}
+
+func TestAuthenticateAPIKey_Expired(t *testing.T) {
+	svc := setupTestService(t)
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	key, err := svc.createAPIKey(&domain.APIKey{OrgID: org.ID, Name: "expired", ExpiresAt: &past})
+	require.NoError(t, err)
+
+	_, _, err = svc.AuthenticateAPIKey(key.Token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	_, err = svc.GetOrganizationByToken(key.Token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+}
+
+func TestCreateConsoleSession_RestrictedKey(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+
+	scoped, err := svc.CreateAPIKey(org.ID, domain.CreateAPIKeyRequest{Scopes: []string{domain.ScopeOrgRead}}, nil)
+	require.NoError(t, err)
+	_, _, err = svc.CreateConsoleSession(scoped.Token)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+
+	// Sessions of expiring keys end with the key
+	expiresAt := time.Now().Add(time.Minute)
+	expiring, err := svc.CreateAPIKey(org.ID, domain.CreateAPIKeyRequest{ExpiresAt: &expiresAt}, nil)
+	require.NoError(t, err)
+	session, _, err := svc.CreateConsoleSession(expiring.Token)
+	require.NoError(t, err)
+	assert.False(t, session.ExpiresAt.After(expiresAt))
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	svc := setupTestService(t)
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+	original := org.APIKey
+
+	overlap := 60
+	rotated, err := svc.RotateAPIKey(org.ID, original.ID, domain.RotateAPIKeyRequest{OverlapSeconds: &overlap}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, original.ID, rotated.ID)
+	assert.NotEqual(t, original.Token, rotated.Token)
+	require.NotNil(t, rotated.RotatedAt)
+	require.NotNil(t, rotated.PreviousTokenExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *rotated.PreviousTokenExpiresAt, 5*time.Second)
+
+	// Both tokens work during the overlap
+	for _, token := range []string{original.Token, rotated.Token} {
+		key, _, err := svc.AuthenticateAPIKey(token)
+		require.NoError(t, err)
+		assert.Equal(t, original.ID, key.ID)
+	}
+
+	// Rotating again without overlap revokes the previous tokens immediately
+	none := 0
+	again, err := svc.RotateAPIKey(org.ID, original.ID, domain.RotateAPIKeyRequest{OverlapSeconds: &none}, nil)
+	require.NoError(t, err)
+	for _, token := range []string{original.Token, rotated.Token} {
+		_, _, err := svc.AuthenticateAPIKey(token)
+		assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	}
+	_, _, err = svc.AuthenticateAPIKey(again.Token)
+	assert.NoError(t, err)
+}
+
+func TestRotateAPIKey_InvalidInput(t *testing.T) {
+	svc := setupTestService(t)
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+
+	for _, seconds := range []int{-1, int(domain.MaxRotationOverlap.Seconds()) + 1} {
+		_, err := svc.RotateAPIKey(org.ID, org.APIKey.ID, domain.RotateAPIKeyRequest{OverlapSeconds: &seconds}, nil)
+		assert.True(t, domain.IsInvalidInput(err), "expected invalid input for %d, got %v", seconds, err)
+	}
+
+	_, err = svc.RotateAPIKey(org.ID, "missing", domain.RotateAPIKeyRequest{}, nil)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	// A scoped key cannot rotate a full-access key
+	scoped, err := svc.CreateAPIKey(org.ID, domain.CreateAPIKeyRequest{Scopes: []string{domain.ScopeAPIKeysWrite}}, nil)
+	require.NoError(t, err)
+	_, err = svc.RotateAPIKey(org.ID, org.APIKey.ID, domain.RotateAPIKeyRequest{}, &scoped.APIKey)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameBucket(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	obj := createTestObjects(t, svc, bucket.ID, "a")["a"]
+	other, err := svc.CreateBucket(bucket.ProjectID, domain.CreateBucketRequest{Name: "other-bucket"})
+	require.NoError(t, err)
+
+	_, err = svc.PutBucketLifecycle(bucket.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{{Prefix: "tmp/", ExpirationDays: 30}}})
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, svc.bucketRepo.SaveDelivery(&domain.NotificationDelivery{
+		ID: "d1", BucketID: bucket.ID, NotificationID: "n1", EventID: "e1", EventType: domain.ObjectEventCreated,
+		URL: "https://example.com/hook", Status: "delivered", CreatedAt: now, UpdatedAt: now,
+	}))
+
+	// Renaming to the current name changes nothing
+	same, err := svc.RenameBucket(bucket.ID, bucket.Name)
+	require.NoError(t, err)
+	assert.Equal(t, bucket.ID, same.ID)
+	assert.Equal(t, bucket.UpdatedAt.Unix(), same.UpdatedAt.Unix())
+
+	_, err = svc.RenameBucket(bucket.ID, other.Name)
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+	_, err = svc.RenameBucket(bucket.ID, "Not Valid")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	renamed, err := svc.RenameBucket(bucket.ID, "renamed-bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "renamed-bucket", renamed.ID)
+	assert.Equal(t, "renamed-bucket", renamed.Name)
+	assert.Equal(t, bucket.ProjectID, renamed.ProjectID)
+	require.Len(t, renamed.LifecycleRules, 1)
+
+	_, err = svc.GetBucket(bucket.ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	// Objects and notification deliveries follow the bucket
+	got, err := svc.GetObject(obj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, renamed.ID, got.BucketID)
+	deliveries, err := svc.ListNotificationDeliveries(renamed.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "d1", deliveries[0].ID)
+
+	// The old name is free again
+	_, err = svc.CreateBucket(bucket.ProjectID, domain.CreateBucketRequest{Name: bucket.Name})
+	require.NoError(t, err)
+}
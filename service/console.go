@@ -0,0 +1,97 @@
+package service
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+)
+
+// ConsoleSessionTTL is how long a web console session stays valid after sign-in
+const ConsoleSessionTTL = 12 * time.Hour
+
+// consoleSessionClaims is the signed payload of a console session cookie
+type consoleSessionClaims struct {
+	OrgID     string `json:"o"`
+	KeyID     string `json:"k"`
+	Nonce     string `json:"n"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// csrfToken derives the CSRF token of a session from its nonce
+func (s *Service) csrfToken(nonce string) string {
+	return hex.EncodeToString(s.signature("csrf", []byte(nonce)))
+}
+
+// CreateConsoleSession signs in to the web console with an API key
+// It returns the session and the signed token to store in the session cookie
+func (s *Service) CreateConsoleSession(apiKeyToken string) (*domain.ConsoleSession, string, error) {
+	apiKey, err := s.lookupAPIKey(apiKeyToken)
+	if err != nil {
+		return nil, "", err
+	}
+	// The console has no per-scope checks, so it only accepts full-access keys
+	if apiKey.Restricted() {
+		return nil, "", domain.ForbiddenError("the console requires an API key without scopes or project restrictions", nil)
+	}
+	go s.apiKeyRepo.UpdateLastUsed(apiKey.ID)
+
+	nonce, err := generateID()
+	if err != nil {
+		return nil, "", domain.InternalError("failed to generate session nonce")
+	}
+	expiresAt := time.Now().Add(ConsoleSessionTTL).Truncate(time.Second)
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(expiresAt) {
+		expiresAt = apiKey.ExpiresAt.Truncate(time.Second)
+	}
+	token, err := s.signToken(endec.PrefixConsole, consoleSessionClaims{
+		OrgID:     apiKey.OrgID,
+		KeyID:     apiKey.ID,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &domain.ConsoleSession{
+		OrgID:     apiKey.OrgID,
+		APIKeyID:  apiKey.ID,
+		ExpiresAt: expiresAt,
+		CSRFToken: s.csrfToken(nonce),
+	}, token, nil
+}
+
+// ValidateConsoleSession verifies a console session token and returns the session
+// Sessions whose API key has since been deleted or has expired are rejected
+func (s *Service) ValidateConsoleSession(token string) (*domain.ConsoleSession, error) {
+	var claims consoleSessionClaims
+	if err := s.verifyToken(token, endec.PrefixConsole, &claims); err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, domain.UnauthorizedError("session has expired")
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(claims.KeyID)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil, domain.UnauthorizedError("session API key has been deleted")
+		}
+		return nil, err
+	}
+	if apiKey.OrgID != claims.OrgID {
+		return nil, domain.UnauthorizedError("invalid session")
+	}
+	if apiKey.Expired(time.Now()) {
+		return nil, domain.UnauthorizedError("session API key has expired")
+	}
+
+	return &domain.ConsoleSession{
+		OrgID:     claims.OrgID,
+		APIKeyID:  claims.KeyID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		CSRFToken: s.csrfToken(claims.Nonce),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleSession(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	project := createTestProject(t, svc)
+
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Name: "console"}, nil)
+	require.NoError(t, err)
+
+	session, token, err := svc.CreateConsoleSession(key.Token)
+	require.NoError(t, err)
+	assert.Equal(t, project.OrgID, session.OrgID)
+	assert.Equal(t, key.ID, session.APIKeyID)
+	assert.NotEmpty(t, session.CSRFToken)
+	assert.WithinDuration(t, time.Now().Add(ConsoleSessionTTL), session.ExpiresAt, 5*time.Second)
+
+	validated, err := svc.ValidateConsoleSession(token)
+	require.NoError(t, err)
+	assert.Equal(t, session.OrgID, validated.OrgID)
+	assert.Equal(t, session.APIKeyID, validated.APIKeyID)
+	assert.Equal(t, session.CSRFToken, validated.CSRFToken)
+
+	// Each sign-in gets its own CSRF token
+	other, _, err := svc.CreateConsoleSession(key.Token)
+	require.NoError(t, err)
+	assert.NotEqual(t, session.CSRFToken, other.CSRFToken)
+
+	// Restricted keys and unknown keys cannot sign in
+	restricted, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Scopes: []string{domain.ScopeInstancesRead}}, nil)
+	require.NoError(t, err)
+	_, _, err = svc.CreateConsoleSession(restricted.Token)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+	_, _, err = svc.CreateConsoleSession(key.Token + "2")
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+}
+
+func TestConsoleSession_Rejected(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	project := createTestProject(t, svc)
+
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Name: "console"}, nil)
+	require.NoError(t, err)
+	_, token, err := svc.CreateConsoleSession(key.Token)
+	require.NoError(t, err)
+	_, data, err := endec.ParseToken(token)
+	require.NoError(t, err)
+
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-1] ^= 1
+	tampered, err := endec.CreateTokenFromData(endec.PrefixConsole, flipped)
+	require.NoError(t, err)
+	wrongPrefix, err := endec.CreateTokenFromData(endec.PrefixSession, data)
+	require.NoError(t, err)
+
+	forger := setupTestService(t)
+	forger.SetSigningSecret([]byte("other-secret"))
+	forged, err := forger.signToken(endec.PrefixConsole, consoleSessionClaims{
+		OrgID: project.OrgID, KeyID: key.ID, Nonce: "n", ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	expired, err := svc.signToken(endec.PrefixConsole, consoleSessionClaims{
+		OrgID: project.OrgID, KeyID: key.ID, Nonce: "n", ExpiresAt: time.Now().Add(-time.Second).Unix(),
+	})
+	require.NoError(t, err)
+	otherOrg, err := svc.signToken(endec.PrefixConsole, consoleSessionClaims{
+		OrgID: "other-org", KeyID: key.ID, Nonce: "n", ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"tampered signature", tampered},
+		{"wrong prefix", wrongPrefix},
+		{"other secret", forged},
+		{"expired", expired},
+		{"other organization", otherOrg},
+		{"api key", key.Token},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ValidateConsoleSession(tt.token)
+			assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+		})
+	}
+
+	// Deleting the API key ends its sessions
+	_, err = svc.ValidateConsoleSession(token)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteAPIKey(project.OrgID, key.ID, nil))
+	_, err = svc.ValidateConsoleSession(token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+}
+
+func TestConsoleSession_KeyExpiry(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	project := createTestProject(t, svc)
+
+	// Sessions end no later than their key
+	expiresAt := time.Now().Add(2 * time.Second)
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{ExpiresAt: &expiresAt}, nil)
+	require.NoError(t, err)
+	session, token, err := svc.CreateConsoleSession(key.Token)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt.Unix(), session.ExpiresAt.Unix())
+
+	_, err = svc.ValidateConsoleSession(token)
+	require.NoError(t, err)
+	time.Sleep(time.Until(expiresAt.Truncate(time.Second).Add(1100 * time.Millisecond)))
+	_, err = svc.ValidateConsoleSession(token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+}
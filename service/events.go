@@ -0,0 +1,125 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// eventBufferSize is how far a subscriber may fall behind before its events are dropped
+const eventBufferSize = 64
+
+// eventBus fans resource events out to subscribers of an org
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan domain.ResourceEvent]string // channel -> org ID
+	closed      bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: make(map[chan domain.ResourceEvent]string)}
+}
+
+// subscribe returns a channel of the org's events and a function that cancels the subscription
+// The channel is closed when the subscription is cancelled or the bus is closed
+func (b *eventBus) subscribe(orgID string) (<-chan domain.ResourceEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan domain.ResourceEvent, eventBufferSize)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = orgID
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// active reports whether anyone is listening, so publishers can skip building events
+func (b *eventBus) active() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// publish sends an event to the org's subscribers without blocking; slow subscribers miss events
+func (b *eventBus) publish(event domain.ResourceEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch, orgID := range b.subscribers {
+		if orgID != event.OrgID {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// close ends all subscriptions and rejects new ones
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = make(map[chan domain.ResourceEvent]string)
+	b.closed = true
+}
+
+// SubscribeEvents streams changes to the resources of an org until cancel is called
+func (s *Service) SubscribeEvents(orgID string) (events <-chan domain.ResourceEvent, cancel func()) {
+	return s.events.subscribe(orgID)
+}
+
+// CloseEvents ends all event subscriptions, e.g. so streaming requests finish on shutdown
+func (s *Service) CloseEvents() {
+	s.events.close()
+}
+
+// publishEvent publishes a change to a resource of an org
+func (s *Service) publishEvent(kind, action, orgID, id string) {
+	if !s.events.active() {
+		return
+	}
+	s.events.publish(domain.ResourceEvent{Kind: kind, Action: action, ID: id, OrgID: orgID, Time: time.Now().UTC()})
+}
+
+// publishProjectEvent publishes a change to a resource of a project (and bucket, for objects)
+func (s *Service) publishProjectEvent(kind, action, projectID, bucketID, id string) {
+	if !s.events.active() {
+		return
+	}
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		log.Printf("Events: failed to look up project %s: %v", projectID, err)
+		return
+	}
+	s.events.publish(domain.ResourceEvent{
+		Kind:      kind,
+		Action:    action,
+		ID:        id,
+		OrgID:     project.OrgID,
+		ProjectID: projectID,
+		BucketID:  bucketID,
+		Time:      time.Now().UTC(),
+	})
+}
+
+// publishObjectEvent publishes an object change, given its bucket notification event type
+func (s *Service) publishObjectEvent(bucket *domain.Bucket, obj *domain.Object, eventType string) {
+	action := strings.TrimPrefix(eventType, "object.")
+	s.publishProjectEvent(domain.ResourceKindObject, action, bucket.ProjectID, bucket.ID, obj.ID)
+}
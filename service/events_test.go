@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextEvent waits briefly for an event on ch
+func nextEvent(t *testing.T, ch <-chan domain.ResourceEvent) domain.ResourceEvent {
+	t.Helper()
+	select {
+	case event, ok := <-ch:
+		require.True(t, ok, "event channel closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return domain.ResourceEvent{}
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+
+	events, cancel := svc.SubscribeEvents(project.OrgID)
+	defer cancel()
+
+	// Changes in other orgs are not delivered
+	_, err = svc.CreateMetadata(domain.CreateMetadataRequest{OrgID: other.ID, Path: "ignored", Value: "x"})
+	require.NoError(t, err)
+
+	instance, err := svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: "web", Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	require.NoError(t, err)
+	event := nextEvent(t, events)
+	assert.Equal(t, domain.ResourceKindInstance, event.Kind)
+	assert.Equal(t, domain.ResourceActionCreated, event.Action)
+	assert.Equal(t, instance.ID, event.ID)
+	assert.Equal(t, project.ID, event.ProjectID)
+
+	obj, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a.txt", Content: "aGk="})
+	require.NoError(t, err)
+	event = nextEvent(t, events)
+	assert.Equal(t, domain.ResourceKindObject, event.Kind)
+	assert.Equal(t, obj.ID, event.ID)
+	assert.Equal(t, bucket.ID, event.BucketID)
+
+	require.NoError(t, svc.DeleteInstance(instance.ID))
+	event = nextEvent(t, events)
+	assert.Equal(t, domain.ResourceKindInstance, event.Kind)
+	assert.Equal(t, domain.ResourceActionDeleted, event.Action)
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok, "channel should be closed after cancel")
+}
+
+func TestCloseEvents(t *testing.T) {
+	svc := setupTestService(t)
+
+	events, cancel := svc.SubscribeEvents("test-org")
+	defer cancel()
+
+	svc.CloseEvents()
+	_, ok := <-events
+	assert.False(t, ok, "channel should be closed by CloseEvents")
+
+	// Subscriptions after close end immediately
+	late, _ := svc.SubscribeEvents("test-org")
+	_, ok = <-late
+	assert.False(t, ok)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// FirewallRuleRepository defines the interface for firewall rule data operations
+type FirewallRuleRepository interface {
+	Create(rule *domain.FirewallRule) error
+	GetByID(id string) (*domain.FirewallRule, error)
+	ListByProjectID(projectID string) ([]*domain.FirewallRule, error)
+	Update(id string, req domain.UpdateFirewallRuleRequest) (*domain.FirewallRule, error)
+	Delete(id string) error
+}
+
+// Limits of firewall rules
+const (
+	maxFirewallEntries       = 100 // per direction of a rule
+	maxInstanceFirewallRules = 5
+	maxFirewallDescription   = 255
+)
+
+// SetFirewallRuleRepository sets the repository of firewall rules
+func (s *Service) SetFirewallRuleRepository(repo FirewallRuleRepository) {
+	s.firewallRuleRepo = repo
+}
+
+// validateFirewallEntries validates the ingress or egress entries of a firewall rule
+// A nil list is returned as an empty one, so rules always list both directions
+func validateFirewallEntries(entries []domain.FirewallRuleEntry, direction string) ([]domain.FirewallRuleEntry, error) {
+	if len(entries) > maxFirewallEntries {
+		return nil, domain.InvalidInputError("too many "+direction+" entries", map[string]interface{}{
+			"max":    maxFirewallEntries,
+			"actual": len(entries),
+		})
+	}
+	for i, entry := range entries {
+		// Details name the entry as it is addressed in the request, e.g. ingress[0]
+		at := direction + "[" + strconv.Itoa(i) + "]"
+
+		validProtocol := false
+		for _, protocol := range domain.ValidFirewallProtocols {
+			validProtocol = validProtocol || entry.Protocol == protocol
+		}
+		if !validProtocol {
+			return nil, domain.InvalidInputError("invalid protocol", map[string]interface{}{
+				"entry":           at,
+				"valid_protocols": domain.ValidFirewallProtocols,
+				"actual":          entry.Protocol,
+			})
+		}
+
+		if len(entry.Ports) > 0 && entry.Protocol != domain.FirewallProtocolTCP && entry.Protocol != domain.FirewallProtocolUDP {
+			return nil, domain.InvalidInputError("ports can only be set for tcp and udp", map[string]interface{}{
+				"entry":    at,
+				"protocol": entry.Protocol,
+			})
+		}
+		for _, ports := range entry.Ports {
+			if !validPortRange(ports) {
+				return nil, domain.InvalidInputError("invalid port range, expected a port or a range such as 8000-8999 within 1-65535", map[string]interface{}{
+					"entry": at,
+					"ports": ports,
+				})
+			}
+		}
+
+		if len(entry.CIDRs) == 0 {
+			return nil, domain.InvalidInputError("cidrs is required, use 0.0.0.0/0 for any address", map[string]interface{}{
+				"entry": at,
+			})
+		}
+		for _, cidr := range entry.CIDRs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil || prefix.Masked() != prefix {
+				return nil, domain.InvalidInputError("invalid cidr", map[string]interface{}{
+					"entry": at,
+					"cidr":  cidr,
+				})
+			}
+		}
+
+		for _, label := range entry.TargetLabels {
+			if err := validateResourceName(label, "target label"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if entries == nil {
+		entries = []domain.FirewallRuleEntry{}
+	}
+	return entries, nil
+}
+
+// validPortRange reports whether ports is a port or a range of ports such as 8000-8999
+func validPortRange(ports string) bool {
+	from, to, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		to = from
+	}
+	first, err := strconv.Atoi(from)
+	if err != nil {
+		return false
+	}
+	last, err := strconv.Atoi(to)
+	if err != nil {
+		return false
+	}
+	return first >= 1 && first <= last && last <= 65535
+}
+
+// validateFirewallDescription validates the description of a firewall rule
+func validateFirewallDescription(description string) error {
+	if len(description) > maxFirewallDescription {
+		return domain.InvalidInputError("description too long", map[string]interface{}{
+			"max_length": maxFirewallDescription,
+			"actual":     len(description),
+		})
+	}
+	return nil
+}
+
+// CreateFirewallRule creates a firewall rule in a project
+func (s *Service) CreateFirewallRule(projectID string, req domain.CreateFirewallRuleRequest) (*domain.FirewallRule, error) {
+	if s.firewallRuleRepo == nil {
+		return nil, domain.InternalError("firewall rules are not configured")
+	}
+	if err := validateResourceName(req.Name, "firewall rule"); err != nil {
+		return nil, err
+	}
+	if err := validateFirewallDescription(req.Description); err != nil {
+		return nil, err
+	}
+	ingress, err := validateFirewallEntries(req.Ingress, "ingress")
+	if err != nil {
+		return nil, err
+	}
+	egress, err := validateFirewallEntries(req.Egress, "egress")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate firewall rule ID")
+	}
+	rule := &domain.FirewallRule{
+		ID:          id,
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		Ingress:     ingress,
+		Egress:      egress,
+	}
+	if err := s.firewallRuleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetFirewallRule retrieves a firewall rule of a project
+func (s *Service) GetFirewallRule(projectID, id string) (*domain.FirewallRule, error) {
+	if s.firewallRuleRepo == nil {
+		return nil, domain.InternalError("firewall rules are not configured")
+	}
+	rule, err := s.firewallRuleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.ProjectID != projectID {
+		return nil, domain.NotFoundError("firewall rule", id)
+	}
+	return rule, nil
+}
+
+// ListFirewallRules lists the firewall rules of a project
+func (s *Service) ListFirewallRules(projectID string) ([]*domain.FirewallRule, error) {
+	if s.firewallRuleRepo == nil {
+		return nil, domain.InternalError("firewall rules are not configured")
+	}
+	return s.firewallRuleRepo.ListByProjectID(projectID)
+}
+
+// UpdateFirewallRule updates a firewall rule of a project
+func (s *Service) UpdateFirewallRule(projectID, id string, req domain.UpdateFirewallRuleRequest) (*domain.FirewallRule, error) {
+	if _, err := s.GetFirewallRule(projectID, id); err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if err := validateResourceName(*req.Name, "firewall rule"); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		if err := validateFirewallDescription(*req.Description); err != nil {
+			return nil, err
+		}
+	}
+	if req.Ingress != nil {
+		ingress, err := validateFirewallEntries(*req.Ingress, "ingress")
+		if err != nil {
+			return nil, err
+		}
+		req.Ingress = &ingress
+	}
+	if req.Egress != nil {
+		egress, err := validateFirewallEntries(*req.Egress, "egress")
+		if err != nil {
+			return nil, err
+		}
+		req.Egress = &egress
+	}
+	return s.firewallRuleRepo.Update(id, req)
+}
+
+// DeleteFirewallRule deletes a firewall rule of a project, which must not be attached to instances
+func (s *Service) DeleteFirewallRule(projectID, id string) error {
+	if _, err := s.GetFirewallRule(projectID, id); err != nil {
+		return err
+	}
+	instances, err := s.instanceRepo.List(domain.InstanceListOptions{ProjectID: projectID})
+	if err != nil {
+		return err
+	}
+	attached := []string{}
+	for _, instance := range instances {
+		for _, ruleID := range instance.FirewallRuleIDs {
+			if ruleID == id {
+				attached = append(attached, instance.ID)
+			}
+		}
+	}
+	if len(attached) > 0 {
+		return domain.ConflictError("firewall rule is attached to instances", map[string]interface{}{
+			"firewall_rule_id": id,
+			"instance_ids":     attached,
+		})
+	}
+	return s.firewallRuleRepo.Delete(id)
+}
+
+// validateInstanceFirewallRules validates the firewall rules attached to an instance of a project
+func (s *Service) validateInstanceFirewallRules(projectID string, ids []string) error {
+	if len(ids) > maxInstanceFirewallRules {
+		return domain.InvalidInputError("too many firewall rules", map[string]interface{}{
+			"max":    maxInstanceFirewallRules,
+			"actual": len(ids),
+		})
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			return domain.InvalidInputError("firewall rule attached twice", map[string]interface{}{
+				"firewall_rule_id": id,
+			})
+		}
+		seen[id] = true
+		if _, err := s.GetFirewallRule(projectID, id); err != nil {
+			if domain.IsNotFound(err) {
+				return domain.ForeignKeyViolationError("firewall rule", "id", id)
+			}
+			return err
+		}
+	}
+	return nil
+}
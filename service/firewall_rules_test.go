@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirewallRules_Validation(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	tests := []struct {
+		name  string
+		entry domain.FirewallRuleEntry
+	}{
+		{"bad protocol", domain.FirewallRuleEntry{Protocol: "sctp", CIDRs: []string{"0.0.0.0/0"}}},
+		{"ports for icmp", domain.FirewallRuleEntry{Protocol: "icmp", Ports: []string{"22"}, CIDRs: []string{"0.0.0.0/0"}}},
+		{"port zero", domain.FirewallRuleEntry{Protocol: "tcp", Ports: []string{"0"}, CIDRs: []string{"0.0.0.0/0"}}},
+		{"port too high", domain.FirewallRuleEntry{Protocol: "tcp", Ports: []string{"65536"}, CIDRs: []string{"0.0.0.0/0"}}},
+		{"reversed range", domain.FirewallRuleEntry{Protocol: "tcp", Ports: []string{"9000-8000"}, CIDRs: []string{"0.0.0.0/0"}}},
+		{"no cidrs", domain.FirewallRuleEntry{Protocol: "tcp", Ports: []string{"22"}}},
+		{"bad cidr", domain.FirewallRuleEntry{Protocol: "tcp", CIDRs: []string{"10.0.0.1/8"}}},
+		{"bad label", domain.FirewallRuleEntry{Protocol: "tcp", CIDRs: []string{"0.0.0.0/0"}, TargetLabels: []string{"web servers"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateFirewallRule(project.ID, domain.CreateFirewallRuleRequest{Name: "fw", Ingress: []domain.FirewallRuleEntry{tt.entry}})
+			assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+		})
+	}
+
+	rule, err := svc.CreateFirewallRule(project.ID, domain.CreateFirewallRuleRequest{
+		Name: "web",
+		Ingress: []domain.FirewallRuleEntry{
+			{Protocol: "tcp", Ports: []string{"80", "443", "8000-8999"}, CIDRs: []string{"0.0.0.0/0", "::/0"}, TargetLabels: []string{"web"}},
+			{Protocol: "icmp", CIDRs: []string{"10.0.0.0/8"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, rule.Ingress, 2)
+	assert.NotNil(t, rule.Egress)
+
+	_, err = svc.CreateFirewallRule(project.ID, domain.CreateFirewallRuleRequest{Name: "web"})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+
+	// Updating a direction replaces its entries and leaves the other one alone
+	egress := []domain.FirewallRuleEntry{{Protocol: "all", CIDRs: []string{"0.0.0.0/0"}}}
+	rule, err = svc.UpdateFirewallRule(project.ID, rule.ID, domain.UpdateFirewallRuleRequest{Egress: &egress})
+	require.NoError(t, err)
+	rule, err = svc.GetFirewallRule(project.ID, rule.ID)
+	require.NoError(t, err)
+	assert.Len(t, rule.Ingress, 2)
+	assert.Equal(t, egress, rule.Egress)
+	assert.Equal(t, []string{"80", "443", "8000-8999"}, rule.Ingress[0].Ports)
+
+	_, err = svc.GetFirewallRule("other-project", rule.ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestFirewallRules_Instances(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	web, err := svc.CreateFirewallRule(project.ID, domain.CreateFirewallRuleRequest{Name: "web"})
+	require.NoError(t, err)
+	ssh, err := svc.CreateFirewallRule(project.ID, domain.CreateFirewallRuleRequest{Name: "ssh"})
+	require.NoError(t, err)
+
+	create := func(name string, ruleIDs ...string) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: "us-east-1", FirewallRuleIDs: ruleIDs, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	}
+
+	instance, err := create("web-1", web.ID)
+	require.NoError(t, err)
+	instance, err = svc.GetInstance(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{web.ID}, instance.FirewallRuleIDs)
+
+	_, err = create("web-2", "missing")
+	assert.True(t, domain.IsForeignKeyViolation(err), "expected foreign key violation, got %v", err)
+	_, err = create("web-2", web.ID, web.ID)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// Attached rules cannot be deleted
+	assert.True(t, domain.IsConflict(svc.DeleteFirewallRule(project.ID, web.ID)), "expected conflict")
+
+	ids := []string{ssh.ID}
+	instance, err = svc.UpdateInstance(instance.ID, domain.UpdateInstanceRequest{FirewallRuleIDs: &ids})
+	require.NoError(t, err)
+	assert.Equal(t, []string{ssh.ID}, instance.FirewallRuleIDs)
+	require.NoError(t, svc.DeleteFirewallRule(project.ID, web.ID))
+
+	ids = []string{}
+	instance, err = svc.UpdateInstance(instance.ID, domain.UpdateInstanceRequest{FirewallRuleIDs: &ids})
+	require.NoError(t, err)
+	assert.Empty(t, instance.FirewallRuleIDs)
+}
+
+func TestValidPortRange(t *testing.T) {
+	assert.True(t, validPortRange("22"))
+	assert.True(t, validPortRange("1-65535"))
+	assert.True(t, validPortRange("8080-8080"))
+	assert.False(t, validPortRange(""))
+	assert.False(t, validPortRange("-22"))
+	assert.False(t, validPortRange("22-"))
+	assert.False(t, validPortRange("http"))
+}
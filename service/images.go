@@ -0,0 +1,261 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// ImageRepository defines the interface for private image data operations
+type ImageRepository interface {
+	Create(image *domain.Image) error
+	GetByID(id string) (*domain.Image, error)
+	List(opts domain.ImageListOptions) ([]*domain.Image, error)
+	SetDeprecated(id string, deprecated bool) (*domain.Image, error)
+	Delete(id string) error
+}
+
+// imageFieldPattern is what image names, families and versions may contain
+var imageFieldPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// SetImageRepository sets the repository of private images
+// Without one, only the public images can be used
+func (s *Service) SetImageRepository(repo ImageRepository) {
+	s.imageRepo = repo
+}
+
+// validateImageField validates the name, family or version of an image
+func validateImageField(value, field string) error {
+	if value == "" {
+		return domain.InvalidInputError(field+" is required", nil)
+	}
+	if len(value) > 63 {
+		return domain.InvalidInputError(field+" too long", map[string]interface{}{
+			"max_length": 63,
+			"actual":     len(value),
+		})
+	}
+	if !imageFieldPattern.MatchString(value) {
+		return domain.InvalidInputError(field+" can only contain letters, digits, '.', '_' and '-'", map[string]interface{}{
+			field: value,
+		})
+	}
+	return nil
+}
+
+// CreateImage creates a private image of an org
+func (s *Service) CreateImage(orgID string, req domain.CreateImageRequest) (*domain.Image, error) {
+	if s.imageRepo == nil {
+		return nil, domain.InternalError("images are not configured")
+	}
+	if _, err := s.orgRepo.GetByID(orgID); err != nil {
+		return nil, err
+	}
+
+	if err := validateImageField(req.Family, "family"); err != nil {
+		return nil, err
+	}
+	if err := validateImageField(req.Version, "version"); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		req.Name = req.Family + "-v" + req.Version
+	}
+	if err := validateImageField(req.Name, "name"); err != nil {
+		return nil, err
+	}
+	validOS := false
+	for _, os := range domain.ValidImageOS {
+		validOS = validOS || req.OS == os
+	}
+	if !validOS {
+		return nil, domain.InvalidInputError("invalid os", map[string]interface{}{
+			"valid_os": domain.ValidImageOS,
+			"actual":   req.OS,
+		})
+	}
+
+	// Names are looked up in the org before the public images, so they must not shadow them
+	for _, public := range domain.PublicImages {
+		if public.Name == req.Name {
+			return nil, domain.AlreadyExistsError("image", "name", req.Name)
+		}
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate image ID")
+	}
+	image := &domain.Image{
+		ID:      id,
+		OrgID:   orgID,
+		Name:    req.Name,
+		Family:  req.Family,
+		Version: req.Version,
+		OS:      req.OS,
+	}
+	if err := s.imageRepo.Create(image); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// ListImages lists the public images and the private images of an org
+func (s *Service) ListImages(opts domain.ImageListOptions) ([]*domain.Image, error) {
+	images := []*domain.Image{}
+	for _, public := range domain.PublicImages {
+		if opts.Family == "" || public.Family == opts.Family {
+			public := public
+			images = append(images, &public)
+		}
+	}
+	if s.imageRepo == nil {
+		return images, nil
+	}
+	private, err := s.imageRepo.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(images, private...), nil
+}
+
+// GetImage retrieves a public image or a private image of an org by ID or name
+func (s *Service) GetImage(orgID, idOrName string) (*domain.Image, error) {
+	images, err := s.ListImages(domain.ImageListOptions{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	// Private images first, since only they can share a name with an image of another org
+	for i := len(images) - 1; i >= 0; i-- {
+		if images[i].ID == idOrName || images[i].Name == idOrName {
+			return images[i], nil
+		}
+	}
+	return nil, domain.NotFoundError("image", idOrName)
+}
+
+// privateImage retrieves a private image of an org, which unlike public images can be changed
+func (s *Service) privateImage(orgID, idOrName string) (*domain.Image, error) {
+	image, err := s.GetImage(orgID, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if image.Public {
+		return nil, domain.ForbiddenError("public images cannot be changed", map[string]interface{}{
+			"image": image.Name,
+		})
+	}
+	return image, nil
+}
+
+// DeprecateImage deprecates a private image of an org
+// New instances can no longer use it, existing ones keep running it
+func (s *Service) DeprecateImage(orgID, idOrName string) (*domain.Image, error) {
+	image, err := s.privateImage(orgID, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	return s.imageRepo.SetDeprecated(image.ID, true)
+}
+
+// DeleteImage deletes a private image of an org
+func (s *Service) DeleteImage(orgID, idOrName string) error {
+	image, err := s.privateImage(orgID, idOrName)
+	if err != nil {
+		return err
+	}
+	return s.imageRepo.Delete(image.ID)
+}
+
+// resolveImage finds the image an instance of an org is created from: an image by name, or
+// the latest version of a family. Unknown and deprecated images are rejected
+func (s *Service) resolveImage(orgID, ref string) (*domain.Image, error) {
+	images, err := s.ListImages(domain.ImageListOptions{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	var image *domain.Image
+	for i := len(images) - 1; i >= 0 && image == nil; i-- {
+		if images[i].Name == ref {
+			image = images[i]
+		}
+	}
+	if image == nil {
+		image = latestImage(images, ref)
+	}
+	if image == nil {
+		// A family whose images are all deprecated
+		for _, candidate := range images {
+			if candidate.Family == ref {
+				image = candidate
+			}
+		}
+	}
+
+	if image == nil {
+		return nil, domain.InvalidInputError("unknown image", map[string]interface{}{
+			"image": ref,
+		})
+	}
+	if image.Deprecated {
+		details := map[string]interface{}{
+			"image":  image.Name,
+			"family": image.Family,
+		}
+		if latest := latestImage(images, image.Family); latest != nil {
+			details["replacement"] = latest.Name
+		}
+		return nil, domain.InvalidInputError("image is deprecated", details)
+	}
+	return image, nil
+}
+
+// imageFamily returns the family of an instance's image, or "" if the image no longer exists
+func (s *Service) imageFamily(instance *domain.Instance) string {
+	project, err := s.projectRepo.GetByID(instance.ProjectID)
+	if err != nil {
+		return ""
+	}
+	image, err := s.GetImage(project.OrgID, instance.Image)
+	if err != nil {
+		return ""
+	}
+	return image.Family
+}
+
+// latestImage returns the image of a family with the highest version that is not deprecated
+func latestImage(images []*domain.Image, family string) *domain.Image {
+	var latest *domain.Image
+	for _, image := range images {
+		if image.Family == family && !image.Deprecated && (latest == nil || compareVersions(image.Version, latest.Version) > 0) {
+			latest = image
+		}
+	}
+	return latest
+}
+
+// compareVersions compares image versions, numerically where both have digits at the same
+// position (so "1.10" is newer than "1.9") and as strings otherwise
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				return an - bn
+			}
+			continue
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
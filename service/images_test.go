@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImages_CreateInstance(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	create := func(name, image string) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: image})
+	}
+
+	// A family resolves to its latest image that is not deprecated
+	web1, err := create("web-1", "ubuntu-22.04")
+	require.NoError(t, err)
+	assert.Equal(t, "ubuntu-22.04-v20250115", web1.Image)
+
+	web2, err := create("web-2", "debian-12-v20240110")
+	require.NoError(t, err)
+	assert.Equal(t, "debian-12-v20240110", web2.Image)
+
+	_, err = create("web-3", "ubuntu:latest")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	_, err = create("web-3", "ubuntu-22.04-v20240110")
+	require.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	assert.Equal(t, "ubuntu-22.04-v20250115", err.(*domain.NahError).Details["replacement"])
+
+	// Every image of the family is deprecated
+	_, err = create("web-3", "ubuntu-20.04")
+	require.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	assert.Equal(t, "image is deprecated", err.(*domain.NahError).Message)
+
+	// The family still matches the instance, so it is not an image change
+	family := "ubuntu-22.04"
+	_, err = svc.UpdateInstance(web1.ID, domain.UpdateInstanceRequest{Image: &family})
+	require.NoError(t, err)
+	_, err = svc.UpdateInstance(web2.ID, domain.UpdateInstanceRequest{Image: &family})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+}
+
+func TestImages_Private(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+	orgID := project.OrgID
+
+	v1, err := svc.CreateImage(orgID, domain.CreateImageRequest{Family: "app", Version: "1.9", OS: domain.ImageOSLinux})
+	require.NoError(t, err)
+	assert.Equal(t, "app-v1.9", v1.Name)
+	assert.False(t, v1.Public)
+	_, err = svc.CreateImage(orgID, domain.CreateImageRequest{Family: "app", Version: "1.10", OS: domain.ImageOSLinux})
+	require.NoError(t, err)
+
+	_, err = svc.CreateImage(orgID, domain.CreateImageRequest{Family: "app", Version: "1.9", OS: domain.ImageOSLinux})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+	_, err = svc.CreateImage(orgID, domain.CreateImageRequest{Name: "debian-12-v20250110", Family: "app", Version: "2", OS: domain.ImageOSLinux})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+	_, err = svc.CreateImage(orgID, domain.CreateImageRequest{Family: "app", Version: "2", OS: "plan9"})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = svc.CreateImage(orgID, domain.CreateImageRequest{Family: "app/x", Version: "2", OS: domain.ImageOSLinux})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	images, err := svc.ListImages(domain.ImageListOptions{OrgID: orgID, Family: "app"})
+	require.NoError(t, err)
+	assert.Len(t, images, 2)
+
+	image, err := svc.resolveImage(orgID, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "app-v1.10", image.Name)
+
+	// Deprecating the latest image falls back to the previous one
+	deprecated, err := svc.DeprecateImage(orgID, "app-v1.10")
+	require.NoError(t, err)
+	assert.True(t, deprecated.Deprecated)
+	image, err = svc.resolveImage(orgID, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "app-v1.9", image.Name)
+
+	// Private images are not visible to other orgs
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	_, err = svc.GetImage(other.ID, v1.ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+	_, err = svc.resolveImage(other.ID, "app")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// Public images cannot be changed
+	_, err = svc.DeprecateImage(orgID, "debian-12-v20250110")
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+	assert.True(t, domain.IsForbidden(svc.DeleteImage(orgID, "debian-12-v20250110")), "expected forbidden")
+
+	require.NoError(t, svc.DeleteImage(orgID, v1.ID))
+	_, err = svc.GetImage(orgID, "app-v1.9")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Positive(t, compareVersions("1.10", "1.9"))
+	assert.Positive(t, compareVersions("20250115", "20240110"))
+	assert.Positive(t, compareVersions("1.2.1", "1.2"))
+	assert.Negative(t, compareVersions("1.0-beta", "1.0-rc"))
+	assert.Zero(t, compareVersions("2.0", "2.0"))
+}
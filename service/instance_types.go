@@ -0,0 +1,100 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// SetInstanceTypes replaces the instance type catalog (domain.DefaultInstanceTypes by default)
+// Instances of types that are no longer in the catalog keep their size
+func (s *Service) SetInstanceTypes(types []domain.InstanceType) error {
+	if len(types) == 0 {
+		return domain.InvalidInputError("the instance type catalog cannot be empty", nil)
+	}
+	seen := map[string]bool{}
+	for _, t := range types {
+		if err := validateName(t.Name, "instance type"); err != nil {
+			return err
+		}
+		if seen[t.Name] {
+			return domain.AlreadyExistsError("instance type", "name", t.Name)
+		}
+		seen[t.Name] = true
+		if err := validateInstanceSize(t.CPU, t.MemoryMB); err != nil {
+			return instanceTypeError(t.Name, err)
+		}
+		for _, region := range t.Regions {
+			if _, err := s.region(region); err != nil {
+				return instanceTypeError(t.Name, err)
+			}
+		}
+	}
+	s.instanceTypes = types
+	return nil
+}
+
+// instanceTypeError prefixes the message of a validation error with the instance type it is about
+func instanceTypeError(name string, err error) error {
+	if nahErr, ok := err.(*domain.NahError); ok {
+		return domain.InvalidInputError("instance type "+name+": "+nahErr.Message, nahErr.Details)
+	}
+	return err
+}
+
+// ListInstanceTypes lists the instance type catalog, only the types offered in region if set
+func (s *Service) ListInstanceTypes(region string) ([]domain.InstanceType, error) {
+	if region != "" {
+		if _, err := s.region(region); err != nil {
+			return nil, err
+		}
+	}
+	types := []domain.InstanceType{}
+	for _, t := range s.catalog() {
+		if region == "" || t.AvailableIn(region) {
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// catalog returns the instance types in effect
+func (s *Service) catalog() []domain.InstanceType {
+	if s.instanceTypes == nil {
+		return domain.DefaultInstanceTypes
+	}
+	return s.instanceTypes
+}
+
+// instanceType finds an instance type that is offered in region
+func (s *Service) instanceType(name, region string) (*domain.InstanceType, error) {
+	var names []string
+	for _, t := range s.catalog() {
+		if t.Name == name {
+			if !t.AvailableIn(region) {
+				return nil, domain.InvalidInputError("instance type is not available in region", map[string]interface{}{
+					"instance_type": name,
+					"region":        region,
+					"regions":       t.Regions,
+				})
+			}
+			return &t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, domain.InvalidInputError("invalid instance type", map[string]interface{}{
+		"valid_instance_types": names,
+		"actual":               name,
+	})
+}
+
+// instanceTypeSize returns the CPU and memory of an instance type. The requested values
+// may be left zero, but must otherwise match the type
+func instanceTypeSize(t *domain.InstanceType, cpu, memoryMB int) (int, int, error) {
+	if (cpu != 0 && cpu != t.CPU) || (memoryMB != 0 && memoryMB != t.MemoryMB) {
+		return 0, 0, domain.InvalidInputError("cpu and memory_mb do not match the instance type", map[string]interface{}{
+			"instance_type": t.Name,
+			"cpu":           t.CPU,
+			"memory_mb":     t.MemoryMB,
+		})
+	}
+	return t.CPU, t.MemoryMB, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceTypes_Create(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	create := func(name, instanceType, region string, cpu, memoryMB int) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{
+			ProjectID:    project.ID,
+			Name:         name,
+			Region:       region,
+			InstanceType: instanceType,
+			CPU:          cpu,
+			MemoryMB:     memoryMB,
+			Image:        "ubuntu-24.04",
+		})
+	}
+
+	// The size is taken from the type
+	instance, err := create("web-1", "nah.medium", "us-east-1", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "nah.medium", instance.InstanceType)
+	assert.Equal(t, 2, instance.CPU)
+	assert.Equal(t, 4096, instance.MemoryMB)
+
+	instance, err = svc.GetInstance(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nah.medium", instance.InstanceType)
+
+	// A size that matches the type is accepted
+	_, err = create("web-2", "nah.medium", "us-east-1", 2, 4096)
+	require.NoError(t, err)
+
+	_, err = create("web-3", "nah.medium", "us-east-1", 4, 0)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	_, err = create("web-3", "nah.huge", "us-east-1", 0, 0)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	_, err = create("db-1", "nah.highmem", "ap-east-1", 0, 0)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = create("db-1", "nah.highmem", "eu-west-1", 0, 0)
+	require.NoError(t, err)
+
+	// Instances without a type keep a custom size
+	instance, err = create("custom", "", "us-east-1", 3, 3000)
+	require.NoError(t, err)
+	assert.Empty(t, instance.InstanceType)
+}
+
+func TestInstanceTypes_Update(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	instance, err := svc.CreateInstance(domain.CreateInstanceRequest{
+		ProjectID:    project.ID,
+		Name:         "web",
+		Region:       "us-east-1",
+		InstanceType: "nah.small",
+		Image:        "ubuntu-24.04",
+	})
+	require.NoError(t, err)
+
+	// Changing the type resizes the instance
+	large := "nah.large"
+	instance, err = svc.UpdateInstance(instance.ID, domain.UpdateInstanceRequest{InstanceType: &large})
+	require.NoError(t, err)
+	assert.Equal(t, "nah.large", instance.InstanceType)
+	assert.Equal(t, 4, instance.CPU)
+	assert.Equal(t, 8192, instance.MemoryMB)
+
+	// The size of a typed instance cannot be changed on its own
+	cpu := 2
+	_, err = svc.UpdateInstance(instance.ID, domain.UpdateInstanceRequest{CPU: &cpu})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// Clearing the type allows a custom size
+	custom := ""
+	instance, err = svc.UpdateInstance(instance.ID, domain.UpdateInstanceRequest{InstanceType: &custom, CPU: &cpu})
+	require.NoError(t, err)
+	assert.Empty(t, instance.InstanceType)
+	assert.Equal(t, 2, instance.CPU)
+	assert.Equal(t, 8192, instance.MemoryMB)
+}
+
+func TestSetInstanceTypes(t *testing.T) {
+	svc := setupTestService(t)
+
+	tests := []struct {
+		name  string
+		types []domain.InstanceType
+	}{
+		{"empty", nil},
+		{"no name", []domain.InstanceType{{CPU: 1, MemoryMB: 512}}},
+		{"duplicate", []domain.InstanceType{{Name: "a", CPU: 1, MemoryMB: 512}, {Name: "a", CPU: 2, MemoryMB: 1024}}},
+		{"no cpu", []domain.InstanceType{{Name: "a", MemoryMB: 512}}},
+		{"bad region", []domain.InstanceType{{Name: "a", CPU: 1, MemoryMB: 512, Regions: []string{"mars-1"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, svc.SetInstanceTypes(tt.types))
+		})
+	}
+
+	require.NoError(t, svc.SetInstanceTypes([]domain.InstanceType{
+		{Name: "tiny", CPU: 1, MemoryMB: 256},
+		{Name: "big", CPU: 32, MemoryMB: 65536, Regions: []string{"us-west-1"}},
+	}))
+
+	types, err := svc.ListInstanceTypes("")
+	require.NoError(t, err)
+	assert.Len(t, types, 2)
+
+	types, err = svc.ListInstanceTypes("us-east-1")
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	assert.Equal(t, "tiny", types[0].Name)
+
+	_, err = svc.ListInstanceTypes("mars-1")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// maxLifecycleRules caps the number of rules per bucket
+const maxLifecycleRules = 100
+
+// validateLifecycleRules validates lifecycle rules and assigns IDs to rules without one
+func validateLifecycleRules(rules []domain.LifecycleRule) error {
+	if len(rules) > maxLifecycleRules {
+		return domain.InvalidInputError("too many lifecycle rules", map[string]interface{}{
+			"max_rules": maxLifecycleRules,
+			"actual":    len(rules),
+		})
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule-%d", i+1)
+		}
+		if len(rule.ID) > 255 {
+			return domain.InvalidInputError("lifecycle rule ID too long", map[string]interface{}{
+				"max_length": 255,
+				"actual":     len(rule.ID),
+			})
+		}
+		if seen[rule.ID] {
+			return domain.InvalidInputError("duplicate lifecycle rule ID", map[string]interface{}{"id": rule.ID})
+		}
+		seen[rule.ID] = true
+
+		if len(rule.Prefix) > 1024 {
+			return domain.InvalidInputError("lifecycle rule prefix too long", map[string]interface{}{
+				"id":         rule.ID,
+				"max_length": 1024,
+				"actual":     len(rule.Prefix),
+			})
+		}
+		if rule.ExpirationDays < 0 || rule.NoncurrentVersionExpirationDays < 0 || rule.AbortIncompleteUploadDays < 0 {
+			return domain.InvalidInputError("lifecycle rule day counts cannot be negative", map[string]interface{}{"id": rule.ID})
+		}
+		if rule.ExpirationDays == 0 && rule.NoncurrentVersionExpirationDays == 0 && rule.AbortIncompleteUploadDays == 0 {
+			return domain.InvalidInputError("lifecycle rule must specify at least one action", map[string]interface{}{
+				"id":      rule.ID,
+				"actions": []string{"expiration_days", "noncurrent_version_expiration_days", "abort_incomplete_upload_days"},
+			})
+		}
+	}
+	return nil
+}
+
+// GetBucketLifecycle returns the lifecycle configuration of a bucket
+func (s *Service) GetBucketLifecycle(bucketID string) (*domain.BucketLifecycle, error) {
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	rules := bucket.LifecycleRules
+	if rules == nil {
+		rules = []domain.LifecycleRule{}
+	}
+	return &domain.BucketLifecycle{Rules: rules}, nil
+}
+
+// PutBucketLifecycle replaces the lifecycle configuration of a bucket
+func (s *Service) PutBucketLifecycle(bucketID string, cfg domain.BucketLifecycle) (*domain.BucketLifecycle, error) {
+	if err := validateLifecycleRules(cfg.Rules); err != nil {
+		return nil, err
+	}
+	bucket, err := s.bucketRepo.UpdateLifecycle(bucketID, cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BucketLifecycle{Rules: bucket.LifecycleRules}, nil
+}
+
+// ApplyLifecycleRules evaluates the lifecycle rules of every bucket as of the given time
+func (s *Service) ApplyLifecycleRules(now time.Time) (*domain.LifecycleRunResult, error) {
+	buckets, err := s.bucketRepo.List(domain.BucketListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.LifecycleRunResult{}
+	for _, bucket := range buckets {
+		for _, rule := range bucket.LifecycleRules {
+			if rule.Disabled {
+				continue
+			}
+			if err := s.applyLifecycleRule(bucket, rule, now, result); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// applyLifecycleRule applies a single rule to a bucket and accumulates the outcome
+func (s *Service) applyLifecycleRule(bucket *domain.Bucket, rule domain.LifecycleRule, now time.Time, result *domain.LifecycleRunResult) error {
+	if rule.ExpirationDays > 0 {
+		cutoff := now.AddDate(0, 0, -rule.ExpirationDays)
+		objects, err := s.objectRepo.List(domain.ObjectListOptions{BucketID: bucket.ID, Prefix: rule.Prefix})
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			// The prefix filter of List is case-insensitive and treats _ and % as wildcards
+			if !strings.HasPrefix(obj.Path, rule.Prefix) || !obj.UpdatedAt.Before(cutoff) {
+				continue
+			}
+			if err := s.objectRepo.Delete(obj.ID); err != nil {
+				// Another rule may have expired the object already
+				if domain.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			s.notifyObjectEvent(bucket, obj, domain.ObjectEventDeleted)
+			result.ExpiredObjects++
+		}
+	}
+
+	if rule.NoncurrentVersionExpirationDays > 0 {
+		cutoff := now.AddDate(0, 0, -rule.NoncurrentVersionExpirationDays)
+		n, err := s.objectRepo.DeleteNoncurrentVersions(bucket.ID, rule.Prefix, cutoff)
+		if err != nil {
+			return err
+		}
+		result.DeletedVersions += n
+	}
+
+	// AbortIncompleteUploadDays has nothing to act on: object uploads are
+	// single requests, so there are never incomplete uploads to abort.
+	return nil
+}
+
+// StartLifecycleWorker evaluates lifecycle rules every interval until ctx is cancelled
+func (s *Service) StartLifecycleWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				result, err := s.ApplyLifecycleRules(now)
+				if err != nil {
+					log.Printf("Lifecycle worker: %v", err)
+					continue
+				}
+				if result.ExpiredObjects > 0 || result.DeletedVersions > 0 {
+					log.Printf("Lifecycle worker: expired %d objects, deleted %d noncurrent versions", result.ExpiredObjects, result.DeletedVersions)
+				}
+			}
+		}
+	}()
+}
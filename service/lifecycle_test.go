@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestObjects creates an object with the given content at each path of a bucket
+func createTestObjects(t *testing.T, svc *Service, bucketID string, paths ...string) map[string]*domain.Object {
+	t.Helper()
+
+	objects := map[string]*domain.Object{}
+	for _, path := range paths {
+		obj, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucketID, Path: path, Content: "data"})
+		require.NoError(t, err)
+		objects[path] = obj
+	}
+	return objects
+}
+
+func TestApplyLifecycleRules_Expiration(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	createTestObjects(t, svc, bucket.ID, "tmp/a", "keep/b")
+
+	_, err := svc.PutBucketLifecycle(bucket.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{{Prefix: "tmp/", ExpirationDays: 30}}})
+	require.NoError(t, err)
+
+	// Objects expire once they have not been modified for the whole period
+	result, err := svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 29))
+	require.NoError(t, err)
+	assert.Zero(t, result.ExpiredObjects)
+
+	result, err = svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 31))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExpiredObjects)
+
+	objects, err := svc.ListObjects(domain.ObjectListOptions{BucketID: bucket.ID})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "keep/b", objects[0].Path)
+}
+
+func TestApplyLifecycleRules_NoncurrentVersions(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	obj := createTestObjects(t, svc, bucket.ID, "logs/a")["logs/a"]
+
+	for _, content := range []string{"v2", "v3"} {
+		content := content
+		_, err := svc.UpdateObject(obj.ID, domain.UpdateObjectRequest{Content: &content})
+		require.NoError(t, err)
+	}
+
+	_, err := svc.PutBucketLifecycle(bucket.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{{Prefix: "logs/", NoncurrentVersionExpirationDays: 7}}})
+	require.NoError(t, err)
+
+	result, err := svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 6))
+	require.NoError(t, err)
+	assert.Zero(t, result.DeletedVersions)
+
+	result, err = svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 8))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.DeletedVersions)
+	assert.Zero(t, result.ExpiredObjects)
+
+	// The current version is kept
+	current, err := svc.GetObject(obj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", current.Content)
+}
+
+func TestApplyLifecycleRules_PrefixIsolation(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	objects := createTestObjects(t, svc, bucket.ID, "tmp/a", "TMP/a", "log_a/x", "logXa/x", "log%a/x")
+
+	// Prefixes are case-sensitive and _ and % are not wildcards
+	for _, path := range []string{"TMP/a", "logXa/x", "log%a/x"} {
+		content := "v2"
+		_, err := svc.UpdateObject(objects[path].ID, domain.UpdateObjectRequest{Content: &content})
+		require.NoError(t, err)
+	}
+	_, err := svc.PutBucketLifecycle(bucket.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{
+		{Prefix: "tmp/", ExpirationDays: 1, NoncurrentVersionExpirationDays: 1},
+		{Prefix: "log_a/", ExpirationDays: 1, NoncurrentVersionExpirationDays: 1},
+	}})
+	require.NoError(t, err)
+
+	result, err := svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ExpiredObjects)
+	assert.Zero(t, result.DeletedVersions)
+
+	remaining, err := svc.ListObjects(domain.ObjectListOptions{BucketID: bucket.ID})
+	require.NoError(t, err)
+	var paths []string
+	for _, obj := range remaining {
+		paths = append(paths, obj.Path)
+	}
+	assert.ElementsMatch(t, []string{"TMP/a", "logXa/x", "log%a/x"}, paths)
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"encoding/binary"
+	"net/netip"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// NetworkRepository defines the interface for network data operations
+type NetworkRepository interface {
+	Create(network *domain.Network) error
+	GetByID(id string) (*domain.Network, error)
+	ListByProjectID(projectID string) ([]*domain.Network, error)
+	Update(id string, req domain.UpdateNetworkRequest) (*domain.Network, error)
+	Delete(id string) error
+}
+
+// SubnetRepository defines the interface for subnet data operations
+type SubnetRepository interface {
+	Create(subnet *domain.Subnet) error
+	GetByID(id string) (*domain.Subnet, error)
+	List(opts domain.SubnetListOptions) ([]*domain.Subnet, error)
+	Update(id string, req domain.UpdateSubnetRequest) (*domain.Subnet, error)
+	Delete(id string) error
+}
+
+// Prefix lengths of network and subnet CIDRs. The smallest subnet has 5 usable addresses:
+// the network address, the gateway and the broadcast address are reserved
+const (
+	minNetworkPrefix = 8
+	maxNetworkPrefix = 28
+	maxSubnetPrefix  = 29
+)
+
+// privateRanges are the IPv4 ranges networks must be within (RFC 1918)
+var privateRanges = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+}
+
+// SetNetworkRepositories sets the repositories of networks and subnets
+func (s *Service) SetNetworkRepositories(networks NetworkRepository, subnets SubnetRepository) {
+	s.networkRepo = networks
+	s.subnetRepo = subnets
+}
+
+// parseCIDR parses an IPv4 CIDR whose prefix length is between minBits and maxBits
+// The CIDR must be written with its network address, e.g. 10.0.1.0/24 and not 10.0.1.5/24
+func parseCIDR(cidr string, minBits, maxBits int) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil || !prefix.Addr().Is4() {
+		return netip.Prefix{}, domain.InvalidInputError("cidr must be an IPv4 CIDR block, e.g. 10.0.0.0/16", map[string]interface{}{
+			"cidr": cidr,
+		})
+	}
+	if prefix.Masked() != prefix {
+		return netip.Prefix{}, domain.InvalidInputError("cidr has host bits set", map[string]interface{}{
+			"cidr":     cidr,
+			"expected": prefix.Masked().String(),
+		})
+	}
+	if prefix.Bits() < minBits || prefix.Bits() > maxBits {
+		return netip.Prefix{}, domain.InvalidInputError("cidr prefix length out of range", map[string]interface{}{
+			"cidr": cidr,
+			"min":  minBits,
+			"max":  maxBits,
+		})
+	}
+	return prefix, nil
+}
+
+// contains reports whether the prefix outer contains all of inner
+func contains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// Network operations
+
+// CreateNetwork creates a network in a project
+func (s *Service) CreateNetwork(projectID string, req domain.CreateNetworkRequest) (*domain.Network, error) {
+	if s.networkRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	if err := validateResourceName(req.Name, "network"); err != nil {
+		return nil, err
+	}
+	prefix, err := parseCIDR(req.CIDR, minNetworkPrefix, maxNetworkPrefix)
+	if err != nil {
+		return nil, err
+	}
+	private := false
+	for _, r := range privateRanges {
+		private = private || contains(r, prefix)
+	}
+	if !private {
+		return nil, domain.InvalidInputError("cidr must be within a private range", map[string]interface{}{
+			"cidr":   req.CIDR,
+			"ranges": []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		})
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate network ID")
+	}
+	network := &domain.Network{
+		ID:        id,
+		ProjectID: projectID,
+		Name:      req.Name,
+		CIDR:      prefix.String(),
+	}
+	if err := s.networkRepo.Create(network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// GetNetwork retrieves a network of a project
+func (s *Service) GetNetwork(projectID, id string) (*domain.Network, error) {
+	if s.networkRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	network, err := s.networkRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if network.ProjectID != projectID {
+		return nil, domain.NotFoundError("network", id)
+	}
+	return network, nil
+}
+
+// ListNetworks lists the networks of a project
+func (s *Service) ListNetworks(projectID string) ([]*domain.Network, error) {
+	if s.networkRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	return s.networkRepo.ListByProjectID(projectID)
+}
+
+// UpdateNetwork updates a network of a project
+func (s *Service) UpdateNetwork(projectID, id string, req domain.UpdateNetworkRequest) (*domain.Network, error) {
+	if _, err := s.GetNetwork(projectID, id); err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if err := validateResourceName(*req.Name, "network"); err != nil {
+			return nil, err
+		}
+	}
+	return s.networkRepo.Update(id, req)
+}
+
+// DeleteNetwork deletes a network of a project, which must have no subnets
+func (s *Service) DeleteNetwork(projectID, id string) error {
+	// Held so a subnet cannot be created in the network between the check and the delete
+	s.ipMu.Lock()
+	defer s.ipMu.Unlock()
+	if _, err := s.GetNetwork(projectID, id); err != nil {
+		return err
+	}
+	subnets, err := s.subnetRepo.List(domain.SubnetListOptions{ProjectID: projectID, NetworkID: id})
+	if err != nil {
+		return err
+	}
+	if len(subnets) > 0 {
+		return domain.ConflictError("network has subnets", map[string]interface{}{
+			"network_id": id,
+			"subnets":    len(subnets),
+		})
+	}
+	return s.networkRepo.Delete(id)
+}
+
+// Subnet operations
+
+// CreateSubnet creates a subnet in a network of a project
+// Its CIDR must be within the network's and must not overlap the network's other subnets
+func (s *Service) CreateSubnet(projectID string, req domain.CreateSubnetRequest) (*domain.Subnet, error) {
+	if s.subnetRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	if err := validateResourceName(req.Name, "subnet"); err != nil {
+		return nil, err
+	}
+	if _, err := s.region(req.Region); err != nil {
+		return nil, err
+	}
+	prefix, err := parseCIDR(req.CIDR, minNetworkPrefix, maxSubnetPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Held until the subnet is stored, so concurrent creates cannot both pass the overlap check
+	s.ipMu.Lock()
+	defer s.ipMu.Unlock()
+	network, err := s.GetNetwork(projectID, req.NetworkID)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil, domain.ForeignKeyViolationError("network", "id", req.NetworkID)
+		}
+		return nil, err
+	}
+	if !contains(netip.MustParsePrefix(network.CIDR), prefix) {
+		return nil, domain.InvalidInputError("cidr is not within the network", map[string]interface{}{
+			"cidr":         req.CIDR,
+			"network_cidr": network.CIDR,
+		})
+	}
+
+	subnets, err := s.subnetRepo.List(domain.SubnetListOptions{ProjectID: projectID, NetworkID: network.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range subnets {
+		if netip.MustParsePrefix(other.CIDR).Overlaps(prefix) {
+			return nil, domain.ConflictError("cidr overlaps another subnet of the network", map[string]interface{}{
+				"cidr":        req.CIDR,
+				"subnet_id":   other.ID,
+				"subnet_cidr": other.CIDR,
+			})
+		}
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate subnet ID")
+	}
+	subnet := &domain.Subnet{
+		ID:        id,
+		ProjectID: projectID,
+		NetworkID: network.ID,
+		Name:      req.Name,
+		Region:    req.Region,
+		CIDR:      prefix.String(),
+		GatewayIP: prefix.Addr().Next().String(),
+	}
+	if err := s.subnetRepo.Create(subnet); err != nil {
+		return nil, err
+	}
+	return subnet, nil
+}
+
+// GetSubnet retrieves a subnet of a project
+func (s *Service) GetSubnet(projectID, id string) (*domain.Subnet, error) {
+	if s.subnetRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	subnet, err := s.subnetRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if subnet.ProjectID != projectID {
+		return nil, domain.NotFoundError("subnet", id)
+	}
+	return subnet, nil
+}
+
+// ListSubnets lists the subnets of a project, optionally of one network
+func (s *Service) ListSubnets(opts domain.SubnetListOptions) ([]*domain.Subnet, error) {
+	if s.subnetRepo == nil {
+		return nil, domain.InternalError("networks are not configured")
+	}
+	return s.subnetRepo.List(opts)
+}
+
+// UpdateSubnet updates a subnet of a project
+func (s *Service) UpdateSubnet(projectID, id string, req domain.UpdateSubnetRequest) (*domain.Subnet, error) {
+	if _, err := s.GetSubnet(projectID, id); err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if err := validateResourceName(*req.Name, "subnet"); err != nil {
+			return nil, err
+		}
+	}
+	return s.subnetRepo.Update(id, req)
+}
+
+// DeleteSubnet deletes a subnet of a project, which must have no instances
+func (s *Service) DeleteSubnet(projectID, id string) error {
+	// Held so an instance cannot be created in the subnet between the check and the delete
+	s.ipMu.Lock()
+	defer s.ipMu.Unlock()
+	if _, err := s.GetSubnet(projectID, id); err != nil {
+		return err
+	}
+	instances, err := s.instanceRepo.List(domain.InstanceListOptions{ProjectID: projectID, SubnetID: id})
+	if err != nil {
+		return err
+	}
+	if len(instances) > 0 {
+		return domain.ConflictError("subnet has instances", map[string]interface{}{
+			"subnet_id": id,
+			"instances": len(instances),
+		})
+	}
+	return s.subnetRepo.Delete(id)
+}
+
+// instanceSubnet finds the subnet an instance of a project in region is created in
+func (s *Service) instanceSubnet(projectID, subnetID, region string) (*domain.Subnet, error) {
+	subnet, err := s.GetSubnet(projectID, subnetID)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil, domain.ForeignKeyViolationError("subnet", "id", subnetID)
+		}
+		return nil, err
+	}
+	if subnet.Region != region {
+		return nil, domain.InvalidInputError("subnet is in another region", map[string]interface{}{
+			"subnet_id":     subnet.ID,
+			"subnet_region": subnet.Region,
+			"region":        region,
+		})
+	}
+	return subnet, nil
+}
+
+// allocateIP returns the lowest address of a subnet that is not reserved or used by an
+// instance, so that the same instances created in the same order get the same IPs
+// The caller must hold ipMu until the instance is stored
+func (s *Service) allocateIP(subnet *domain.Subnet) (string, error) {
+	instances, err := s.instanceRepo.List(domain.InstanceListOptions{ProjectID: subnet.ProjectID, SubnetID: subnet.ID})
+	if err != nil {
+		return "", err
+	}
+	used := map[string]bool{}
+	for _, instance := range instances {
+		used[instance.PrivateIP] = true
+	}
+
+	prefix := netip.MustParsePrefix(subnet.CIDR)
+	network := prefix.Addr().As4()
+	first := binary.BigEndian.Uint32(network[:])
+	last := first | (1<<(32-prefix.Bits()) - 1)
+
+	// Skip the network address and the gateway, and stop before the broadcast address
+	for n := first + 2; n < last; n++ {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], n)
+		if ip := netip.AddrFrom4(b).String(); !used[ip] {
+			return ip, nil
+		}
+	}
+	return "", domain.ConflictError("subnet has no free IP addresses", map[string]interface{}{
+		"subnet_id": subnet.ID,
+		"cidr":      subnet.CIDR,
+	})
+}
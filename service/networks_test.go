@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworks_CIDR(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	tests := []struct {
+		name string
+		cidr string
+	}{
+		{"not a cidr", "10.0.0.0"},
+		{"ipv6", "fd00::/64"},
+		{"host bits set", "10.0.1.5/16"},
+		{"public", "8.8.0.0/16"},
+		{"too large", "10.0.0.0/7"},
+		{"too small", "10.0.0.0/29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateNetwork(project.ID, domain.CreateNetworkRequest{Name: "vpc", CIDR: tt.cidr})
+			assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+		})
+	}
+
+	network, err := svc.CreateNetwork(project.ID, domain.CreateNetworkRequest{Name: "vpc", CIDR: "10.0.0.0/16"})
+	require.NoError(t, err)
+	_, err = svc.CreateNetwork(project.ID, domain.CreateNetworkRequest{Name: "vpc", CIDR: "10.1.0.0/16"})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+
+	create := func(name, cidr string) (*domain.Subnet, error) {
+		return svc.CreateSubnet(project.ID, domain.CreateSubnetRequest{NetworkID: network.ID, Name: name, Region: "us-east-1", CIDR: cidr})
+	}
+
+	subnet, err := create("a", "10.0.1.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.1.1", subnet.GatewayIP)
+
+	_, err = create("b", "10.1.0.0/24")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = create("b", "10.0.0.0/23")
+	require.True(t, domain.IsConflict(err), "expected conflict, got %v", err)
+	assert.Equal(t, subnet.ID, err.(*domain.NahError).Details["subnet_id"])
+	_, err = create("b", "10.0.2.0/24")
+	require.NoError(t, err)
+
+	_, err = svc.CreateSubnet(project.ID, domain.CreateSubnetRequest{NetworkID: "missing", Name: "c", Region: "us-east-1", CIDR: "10.0.3.0/24"})
+	assert.True(t, domain.IsForeignKeyViolation(err), "expected foreign key violation, got %v", err)
+
+	subnets, err := svc.ListSubnets(domain.SubnetListOptions{ProjectID: project.ID, NetworkID: network.ID})
+	require.NoError(t, err)
+	assert.Len(t, subnets, 2)
+
+	// Networks with subnets cannot be deleted
+	assert.True(t, domain.IsConflict(svc.DeleteNetwork(project.ID, network.ID)), "expected conflict")
+}
+
+func TestNetworks_AllocateIP(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	network, err := svc.CreateNetwork(project.ID, domain.CreateNetworkRequest{Name: "vpc", CIDR: "192.168.0.0/24"})
+	require.NoError(t, err)
+	subnet, err := svc.CreateSubnet(project.ID, domain.CreateSubnetRequest{NetworkID: network.ID, Name: "small", Region: "us-east-1", CIDR: "192.168.0.0/29"})
+	require.NoError(t, err)
+
+	create := func(name, region string) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: region, SubnetID: subnet.ID, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	}
+
+	// The network address, the gateway and the broadcast address are reserved
+	var instances []*domain.Instance
+	for i, ip := range []string{"192.168.0.2", "192.168.0.3", "192.168.0.4", "192.168.0.5", "192.168.0.6"} {
+		instance, err := create("web-"+string(rune('a'+i)), "us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, ip, instance.PrivateIP)
+		assert.Equal(t, subnet.ID, instance.SubnetID)
+		instances = append(instances, instance)
+	}
+
+	_, err = create("full", "us-east-1")
+	assert.True(t, domain.IsConflict(err), "expected conflict, got %v", err)
+
+	// Released addresses are allocated again
+	require.NoError(t, svc.DeleteInstance(instances[1].ID))
+	instance, err := create("web-z", "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.3", instance.PrivateIP)
+
+	_, err = create("other", "eu-west-1")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// Subnets with instances cannot be deleted
+	assert.True(t, domain.IsConflict(svc.DeleteSubnet(project.ID, subnet.ID)), "expected conflict")
+}
+
+func TestNetworks_ConcurrentChanges(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	network, err := svc.CreateNetwork(project.ID, domain.CreateNetworkRequest{Name: "vpc", CIDR: "10.0.0.0/16"})
+	require.NoError(t, err)
+
+	// Runs fns at once
+	concurrently := func(fns ...func()) {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for _, fn := range fns {
+			wg.Add(1)
+			go func(fn func()) {
+				defer wg.Done()
+				<-start
+				fn()
+			}(fn)
+		}
+		close(start)
+		wg.Wait()
+	}
+
+	// Overlapping subnets created at once cannot both pass the overlap check
+	var creates []func()
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("subnet-%d", i)
+		creates = append(creates, func() {
+			_, err := svc.CreateSubnet(project.ID, domain.CreateSubnetRequest{NetworkID: network.ID, Name: name, Region: "us-east-1", CIDR: "10.0.1.0/24"})
+			if err != nil {
+				assert.True(t, domain.IsConflict(err), "expected conflict, got %v", err)
+			}
+		})
+	}
+	concurrently(creates...)
+	subnets, err := svc.ListSubnets(domain.SubnetListOptions{ProjectID: project.ID, NetworkID: network.ID})
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+
+	// A subnet deleted while an instance is created in it either keeps the instance or refuses it
+	var deleteErr, createErr error
+	concurrently(
+		func() { deleteErr = svc.DeleteSubnet(project.ID, subnets[0].ID) },
+		func() {
+			_, createErr = svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: "web", Region: "us-east-1", SubnetID: subnets[0].ID, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+		},
+	)
+	if deleteErr == nil {
+		assert.True(t, domain.IsForeignKeyViolation(createErr), "expected foreign key violation, got %v", createErr)
+	} else {
+		assert.True(t, domain.IsConflict(deleteErr), "expected conflict, got %v", deleteErr)
+		assert.NoError(t, createErr)
+	}
+}
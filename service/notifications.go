@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+)
+
+const (
+	maxBucketNotifications   = 10
+	defaultDeliveryListLimit = 50
+	maxDeliveryListLimit     = 500
+)
+
+// notificationRetryDelays are the waits between delivery attempts; an event is
+// attempted len(notificationRetryDelays)+1 times before the delivery is marked failed
+var notificationRetryDelays = []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}
+
+// notificationClient is the HTTP client used to deliver webhook events
+var notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+// validateNotifications validates a notification configuration, assigning IDs and
+// secrets where missing. Secrets of notifications that keep their ID are preserved.
+func validateNotifications(notifications []domain.Notification, existing []domain.Notification) ([]domain.Notification, error) {
+	if len(notifications) > maxBucketNotifications {
+		return nil, domain.InvalidInputError("too many notifications", map[string]interface{}{
+			"max":    maxBucketNotifications,
+			"actual": len(notifications),
+		})
+	}
+
+	secrets := make(map[string]string, len(existing))
+	for _, n := range existing {
+		secrets[n.ID] = n.Secret
+	}
+
+	seen := make(map[string]bool, len(notifications))
+	validated := make([]domain.Notification, 0, len(notifications))
+	for i, n := range notifications {
+		if n.ID == "" {
+			n.ID = fmt.Sprintf("notification-%d", i+1)
+		}
+		if seen[n.ID] {
+			return nil, domain.InvalidInputError("duplicate notification id", map[string]interface{}{"id": n.ID})
+		}
+		seen[n.ID] = true
+
+		u, err := url.Parse(n.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, domain.InvalidInputError("notification url must be an absolute http(s) URL", map[string]interface{}{
+				"id":     n.ID,
+				"actual": n.URL,
+			})
+		}
+
+		for _, event := range n.Events {
+			if !slices.Contains(domain.ValidObjectEvents, event) {
+				return nil, domain.InvalidInputError("invalid notification event", map[string]interface{}{
+					"id":           n.ID,
+					"valid_events": domain.ValidObjectEvents,
+					"actual":       event,
+				})
+			}
+		}
+
+		if n.Secret == "" {
+			n.Secret = secrets[n.ID]
+		}
+		if n.Secret == "" {
+			secret, err := endec.CreateToken(endec.PrefixWebhook, 24)
+			if err != nil {
+				return nil, domain.InternalError("failed to generate notification secret")
+			}
+			n.Secret = secret
+		}
+
+		validated = append(validated, n)
+	}
+	return validated, nil
+}
+
+// GetBucketNotifications returns the notification configuration of a bucket, without secrets
+func (s *Service) GetBucketNotifications(bucketID string) (*domain.BucketNotifications, error) {
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	notifications := domain.RedactNotifications(bucket.Notifications)
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	return &domain.BucketNotifications{Notifications: notifications}, nil
+}
+
+// PutBucketNotifications replaces the notification configuration of a bucket
+func (s *Service) PutBucketNotifications(bucketID string, cfg domain.BucketNotifications) (*domain.BucketNotifications, error) {
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := validateNotifications(cfg.Notifications, bucket.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err = s.bucketRepo.UpdateNotifications(bucketID, notifications)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BucketNotifications{Notifications: bucket.Notifications}, nil
+}
+
+// ListNotificationDeliveries returns the most recent webhook deliveries of a bucket
+func (s *Service) ListNotificationDeliveries(bucketID string, limit int) ([]*domain.NotificationDelivery, error) {
+	if limit <= 0 {
+		limit = defaultDeliveryListLimit
+	}
+	if limit > maxDeliveryListLimit {
+		limit = maxDeliveryListLimit
+	}
+	if _, err := s.bucketRepo.GetByID(bucketID); err != nil {
+		return nil, err
+	}
+	return s.bucketRepo.ListDeliveries(bucketID, limit)
+}
+
+// notificationMatches reports whether a notification subscribes to an event on path
+func notificationMatches(n domain.Notification, eventType, path string) bool {
+	if !strings.HasPrefix(path, n.Prefix) {
+		return false
+	}
+	return len(n.Events) == 0 || slices.Contains(n.Events, eventType)
+}
+
+// notifyObjectEvent sends an object event to every matching notification of the bucket
+// Deliveries happen in the background; failures are recorded in the delivery log
+func (s *Service) notifyObjectEvent(bucket *domain.Bucket, obj *domain.Object, eventType string) {
+	// Console watchers see every change; webhooks only the ones they match
+	s.publishObjectEvent(bucket, obj, eventType)
+
+	if len(bucket.Notifications) == 0 {
+		return
+	}
+
+	eventID, err := generateID()
+	if err != nil {
+		log.Printf("Notifications: failed to generate event id: %v", err)
+		return
+	}
+	event := domain.ObjectEvent{
+		ID:         eventID,
+		Type:       eventType,
+		BucketID:   bucket.ID,
+		BucketName: bucket.Name,
+		Path:       obj.Path,
+		Size:       obj.Size,
+		Version:    obj.Version,
+		Time:       time.Now().UTC(),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Notifications: failed to encode event: %v", err)
+		return
+	}
+
+	for _, n := range bucket.Notifications {
+		if !notificationMatches(n, eventType, obj.Path) {
+			continue
+		}
+		deliveryID, err := generateID()
+		if err != nil {
+			log.Printf("Notifications: failed to generate delivery id: %v", err)
+			continue
+		}
+		now := time.Now()
+		delivery := &domain.NotificationDelivery{
+			ID:             deliveryID,
+			BucketID:       bucket.ID,
+			NotificationID: n.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			URL:            n.URL,
+			Status:         domain.DeliveryStatusPending,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.bucketRepo.SaveDelivery(delivery); err != nil {
+			log.Printf("Notifications: failed to record delivery: %v", err)
+			continue
+		}
+		go s.deliverNotification(n, delivery, payload)
+	}
+}
+
+// deliverNotification POSTs payload to the notification URL, retrying with backoff
+func (s *Service) deliverNotification(n domain.Notification, delivery *domain.NotificationDelivery, payload []byte) {
+	for attempt := 0; ; attempt++ {
+		code, err := postNotification(n, delivery, payload)
+		delivery.Attempts++
+		delivery.ResponseCode = code
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		delivery.UpdatedAt = time.Now()
+
+		done := err == nil || attempt >= len(notificationRetryDelays)
+		switch {
+		case err == nil:
+			delivery.Status = domain.DeliveryStatusDelivered
+		case done:
+			delivery.Status = domain.DeliveryStatusFailed
+		}
+		if saveErr := s.bucketRepo.SaveDelivery(delivery); saveErr != nil {
+			// The bucket may have been deleted while we were delivering
+			if !domain.IsForeignKeyViolation(saveErr) {
+				log.Printf("Notifications: failed to update delivery %s: %v", delivery.ID, saveErr)
+			}
+			return
+		}
+		if done {
+			return
+		}
+		time.Sleep(notificationRetryDelays[attempt])
+	}
+}
+
+// postNotification performs a single delivery attempt and returns the response status code
+// Any non-2xx response is treated as a failure
+func postNotification(n domain.Notification, delivery *domain.NotificationDelivery, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	mac := hmac.New(sha256.New, []byte(n.Secret))
+	mac.Write(payload)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NahCloud-Notifications")
+	req.Header.Set("X-Nah-Event", delivery.EventType)
+	req.Header.Set("X-Nah-Delivery", delivery.ID)
+	req.Header.Set("X-Nah-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := notificationClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver records the requests delivered to an httptest server
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	failures int // number of requests to answer with 500 before succeeding
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, body)
+	if rcv.failures > 0 {
+		rcv.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rcv *webhookReceiver) count() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return len(rcv.requests)
+}
+
+// useFastRetries shortens notification retry delays for the duration of a test
+func useFastRetries(t *testing.T) {
+	t.Helper()
+	saved := notificationRetryDelays
+	notificationRetryDelays = []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}
+	t.Cleanup(func() { notificationRetryDelays = saved })
+}
+
+// waitForDeliveries waits until all deliveries of a bucket have left the pending state
+func waitForDeliveries(t *testing.T, svc *Service, bucketID string, n int) []*domain.NotificationDelivery {
+	t.Helper()
+	var deliveries []*domain.NotificationDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		deliveries, err = svc.ListNotificationDeliveries(bucketID, 0)
+		require.NoError(t, err)
+		if len(deliveries) != n {
+			return false
+		}
+		for _, d := range deliveries {
+			if d.Status == domain.DeliveryStatusPending {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	return deliveries
+}
+
+func TestPutBucketNotifications_Validation(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	_, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: "ftp://example.com"}},
+	})
+	assert.True(t, domain.IsInvalidInput(err))
+
+	_, err = svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: "http://example.com", Events: []string{"object.renamed"}}},
+	})
+	assert.True(t, domain.IsInvalidInput(err))
+
+	cfg, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: "http://example.com/hook"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Notifications, 1)
+	assert.Equal(t, "notification-1", cfg.Notifications[0].ID)
+	assert.True(t, strings.HasPrefix(cfg.Notifications[0].Secret, "nah_whk_"))
+
+	// Re-putting the same ID without a secret keeps the generated one
+	again, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{ID: "notification-1", URL: "http://example.com/other"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Notifications[0].Secret, again.Notifications[0].Secret)
+}
+
+func TestBucketNotifications_SecretNotReadable(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	cfg, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: "http://example.com/hook", Secret: "s3cret-value"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret-value", cfg.Notifications[0].Secret)
+
+	// Reading the bucket or its notifications does not show the secret
+	read, err := svc.GetBucket(bucket.ID)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(read)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "s3cret-value")
+	assert.NotContains(t, string(encoded), `"secret"`)
+	assert.Contains(t, string(encoded), "http://example.com/hook")
+
+	buckets, err := svc.ListBuckets(domain.BucketListOptions{ProjectID: bucket.ProjectID})
+	require.NoError(t, err)
+	encoded, err = json.Marshal(buckets)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "s3cret-value")
+
+	got, err := svc.GetBucketNotifications(bucket.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Notifications[0].Secret)
+
+	// The secret is still used to sign deliveries
+	read, err = svc.GetBucket(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret-value", read.Notifications[0].Secret)
+}
+
+func TestObjectEvents_DeliveredAndSigned(t *testing.T) {
+	useFastRetries(t)
+	rcv := &webhookReceiver{}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	cfg, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: server.URL, Prefix: "logs/"}},
+	})
+	require.NoError(t, err)
+	secret := cfg.Notifications[0].Secret
+
+	obj, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "logs/a.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+	// Outside the prefix: no event
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "other.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+
+	deliveries := waitForDeliveries(t, svc, bucket.ID, 1)
+	assert.Equal(t, domain.DeliveryStatusDelivered, deliveries[0].Status)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].ResponseCode)
+	require.Equal(t, 1, rcv.count())
+
+	req, body := rcv.requests[0], rcv.bodies[0]
+	assert.Equal(t, domain.ObjectEventCreated, req.Header.Get("X-Nah-Event"))
+	assert.Equal(t, deliveries[0].ID, req.Header.Get("X-Nah-Delivery"))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Nah-Signature"))
+
+	var event domain.ObjectEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, domain.ObjectEventCreated, event.Type)
+	assert.Equal(t, bucket.ID, event.BucketID)
+	assert.Equal(t, "logs/a.txt", event.Path)
+	assert.Equal(t, int64(5), event.Size)
+	assert.Equal(t, 1, event.Version)
+
+	require.NoError(t, svc.DeleteObject(obj.ID))
+	deliveries = waitForDeliveries(t, svc, bucket.ID, 2)
+	assert.Equal(t, domain.ObjectEventDeleted, deliveries[0].EventType)
+}
+
+func TestObjectEvents_Retries(t *testing.T) {
+	useFastRetries(t)
+	rcv := &webhookReceiver{failures: 2}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	_, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: server.URL, Events: []string{domain.ObjectEventCreated}}},
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+
+	deliveries := waitForDeliveries(t, svc, bucket.ID, 1)
+	assert.Equal(t, domain.DeliveryStatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Equal(t, 3, rcv.count())
+}
+
+func TestObjectEvents_FailedAfterRetries(t *testing.T) {
+	useFastRetries(t)
+	rcv := &webhookReceiver{failures: 100}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	_, err := svc.PutBucketNotifications(bucket.ID, domain.BucketNotifications{
+		Notifications: []domain.Notification{{URL: server.URL}},
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+
+	deliveries := waitForDeliveries(t, svc, bucket.ID, 1)
+	assert.Equal(t, domain.DeliveryStatusFailed, deliveries[0].Status)
+	assert.Equal(t, len(notificationRetryDelays)+1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].ResponseCode)
+	assert.NotEmpty(t, deliveries[0].Error)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// objectDestination is the resolved target of a copy or move
+type objectDestination struct {
+	bucket   *domain.Bucket
+	path     string
+	existing *domain.Object // nil if nothing is stored at the destination
+}
+
+// resolveObjectDestination resolves and validates the destination of a copy or move
+// of src (stored in srcBucket) within the organization orgID
+func (s *Service) resolveObjectDestination(orgID string, srcBucket *domain.Bucket, src *domain.Object, req domain.CopyObjectRequest) (*objectDestination, error) {
+	dest := &objectDestination{bucket: srcBucket, path: src.Path}
+
+	if req.DestinationProject != "" || req.DestinationBucket != "" {
+		projectID := srcBucket.ProjectID
+		if req.DestinationProject != "" {
+			project, err := s.projectRepo.GetBySlug(orgID, req.DestinationProject)
+			if err != nil {
+				return nil, err
+			}
+			projectID = project.ID
+		}
+		bucketName := req.DestinationBucket
+		if bucketName == "" {
+			bucketName = srcBucket.Name
+		}
+		bucket, err := s.bucketRepo.GetByName(projectID, bucketName)
+		if err != nil {
+			return nil, err
+		}
+		dest.bucket = bucket
+	}
+
+	if req.DestinationPath != "" {
+		if err := validateObjectPath(req.DestinationPath); err != nil {
+			return nil, err
+		}
+		dest.path = req.DestinationPath
+	}
+
+	if dest.bucket.ID == srcBucket.ID && dest.path == src.Path {
+		return nil, domain.InvalidInputError("destination is the same as the source", nil)
+	}
+
+	existing, err := s.objectRepo.GetByPath(dest.bucket.ID, dest.path)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		dest.existing = existing
+	}
+
+	// Conditional overwrite
+	if dest.existing != nil && !req.Overwrite {
+		return nil, domain.AlreadyExistsError("object", "path", dest.path)
+	}
+	if req.IfMatchVersion != nil {
+		if dest.existing == nil {
+			return nil, domain.PreconditionFailedError("destination object does not exist", map[string]interface{}{
+				"expected_version": *req.IfMatchVersion,
+			})
+		}
+		if dest.existing.Version != *req.IfMatchVersion {
+			return nil, domain.PreconditionFailedError("destination object version does not match", map[string]interface{}{
+				"expected_version": *req.IfMatchVersion,
+				"actual_version":   dest.existing.Version,
+			})
+		}
+	}
+
+	return dest, nil
+}
+
+// copyObjectMetadata returns the content type and metadata the destination should get
+func copyObjectMetadata(src *domain.Object, req domain.CopyObjectRequest) (string, map[string]string, error) {
+	switch strings.ToUpper(req.MetadataDirective) {
+	case "", domain.MetadataDirectiveCopy:
+		return src.ContentType, src.Metadata, nil
+	case domain.MetadataDirectiveReplace:
+		return req.ContentType, req.Metadata, nil
+	default:
+		return "", nil, domain.InvalidInputError("invalid metadata_directive", map[string]interface{}{
+			"valid_directives": []string{domain.MetadataDirectiveCopy, domain.MetadataDirectiveReplace},
+			"actual":           req.MetadataDirective,
+		})
+	}
+}
+
+// CopyObject copies an object server-side to another path, bucket or project of the same organization
+func (s *Service) CopyObject(orgID, objectID string, req domain.CopyObjectRequest) (*domain.Object, error) {
+	src, err := s.objectRepo.GetByID(objectID)
+	if err != nil {
+		return nil, err
+	}
+	srcBucket, err := s.bucketRepo.GetByID(src.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	contentType, metadata, err := copyObjectMetadata(src, req)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.resolveObjectDestination(orgID, srcBucket, src, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest.existing == nil {
+		return s.CreateObject(domain.CreateObjectRequest{
+			BucketID:    dest.bucket.ID,
+			Path:        dest.path,
+			Content:     src.Content,
+			ContentType: contentType,
+			Metadata:    metadata,
+		})
+	}
+	return s.UpdateObject(dest.existing.ID, domain.UpdateObjectRequest{
+		Content:     &src.Content,
+		ContentType: &contentType,
+		Metadata:    &metadata,
+	})
+}
+
+// MoveObject moves an object server-side to another path, bucket or project of the same organization
+// The object keeps its ID and version history
+func (s *Service) MoveObject(orgID, objectID string, req domain.CopyObjectRequest) (*domain.Object, error) {
+	// Held until the move is done, so the sizes checked below cannot change under it
+	s.storageMu.Lock()
+	defer s.storageMu.Unlock()
+
+	src, err := s.objectRepo.GetByID(objectID)
+	if err != nil {
+		return nil, err
+	}
+	srcBucket, err := s.bucketRepo.GetByID(src.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	contentType, metadata, err := copyObjectMetadata(src, req)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.resolveObjectDestination(orgID, srcBucket, src, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quota: the destination gains the object and its versions, minus whatever it replaces
+	if dest.bucket.ID != srcBucket.ID {
+		deltaBytes, err := s.objectStoredSize(src)
+		if err != nil {
+			return nil, err
+		}
+		deltaObjects := int64(1)
+		if dest.existing != nil {
+			replaced, err := s.objectStoredSize(dest.existing)
+			if err != nil {
+				return nil, err
+			}
+			deltaBytes -= replaced
+			deltaObjects = 0
+		}
+		if err := s.checkBucketQuota(dest.bucket, deltaBytes, deltaObjects); err != nil {
+			return nil, err
+		}
+		if dest.bucket.ProjectID != srcBucket.ProjectID {
+			if err := s.checkProjectQuota(dest.bucket.ProjectID, deltaBytes, deltaObjects); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	obj, err := s.objectRepo.Move(src.ID, dest.bucket.ID, dest.path, dest.existing != nil)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != obj.ContentType || !maps.Equal(metadata, obj.Metadata) {
+		obj, err = s.objectRepo.Update(obj.ID, domain.UpdateObjectRequest{
+			ContentType: &contentType,
+			Metadata:    &metadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A move is seen as a delete at the source and a create (or overwrite) at the destination
+	s.notifyObjectEvent(srcBucket, src, domain.ObjectEventDeleted)
+	if dest.existing != nil {
+		s.notifyObjectEvent(dest.bucket, obj, domain.ObjectEventUpdated)
+	} else {
+		s.notifyObjectEvent(dest.bucket, obj, domain.ObjectEventCreated)
+	}
+	return obj, nil
+}
+
+// objectStoredSize returns the bytes an object takes up in its bucket, noncurrent versions included
+func (s *Service) objectStoredSize(obj *domain.Object) (int64, error) {
+	noncurrent, err := s.objectRepo.NoncurrentSize(obj.ID)
+	if err != nil {
+		return 0, err
+	}
+	return obj.Size + noncurrent, nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyObject(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	objects := createTestObjects(t, svc, bucket.ID, "a", "b")
+	src := objects["a"]
+
+	copied, err := svc.CopyObject(project.OrgID, src.ID, domain.CopyObjectRequest{DestinationPath: "c"})
+	require.NoError(t, err)
+	assert.NotEqual(t, src.ID, copied.ID)
+	assert.Equal(t, "c", copied.Path)
+	assert.Equal(t, src.Content, copied.Content)
+	assert.Equal(t, 1, copied.Version)
+
+	_, err = svc.CopyObject(project.OrgID, src.ID, domain.CopyObjectRequest{})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// An existing destination is only replaced on request, and keeps its ID
+	content := "v2"
+	_, err = svc.UpdateObject(src.ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+	_, err = svc.CopyObject(project.OrgID, src.ID, domain.CopyObjectRequest{DestinationPath: "b"})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+
+	overwritten, err := svc.CopyObject(project.OrgID, src.ID, domain.CopyObjectRequest{DestinationPath: "b", Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, objects["b"].ID, overwritten.ID)
+	assert.Equal(t, "v2", overwritten.Content)
+	assert.Equal(t, 2, overwritten.Version)
+
+	// The source is left alone
+	got, err := svc.GetObject(src.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.Path)
+}
+
+func TestCopyObject_IfMatchVersion(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	objects := createTestObjects(t, svc, bucket.ID, "a", "b")
+
+	stale, current := 1, 2
+	content := "v2"
+	_, err = svc.UpdateObject(objects["b"].ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+
+	for _, req := range []domain.CopyObjectRequest{
+		{DestinationPath: "b", Overwrite: true, IfMatchVersion: &stale},
+		{DestinationPath: "missing", Overwrite: true, IfMatchVersion: &stale},
+	} {
+		_, err = svc.CopyObject(project.OrgID, objects["a"].ID, req)
+		assert.True(t, domain.IsPreconditionFailed(err), "expected precondition failed, got %v", err)
+		_, err = svc.MoveObject(project.OrgID, objects["a"].ID, req)
+		assert.True(t, domain.IsPreconditionFailed(err), "expected precondition failed, got %v", err)
+	}
+
+	// Nothing changed on failure
+	got, err := svc.GetObject(objects["b"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", got.Content)
+	got, err = svc.GetObject(objects["a"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.Path)
+
+	copied, err := svc.CopyObject(project.OrgID, objects["a"].ID, domain.CopyObjectRequest{DestinationPath: "b", Overwrite: true, IfMatchVersion: &current})
+	require.NoError(t, err)
+	assert.Equal(t, "data", copied.Content)
+	assert.Equal(t, 3, copied.Version)
+}
+
+func TestMoveObject(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	objects := createTestObjects(t, svc, bucket.ID, "a", "b")
+
+	_, err = svc.MoveObject(project.OrgID, objects["a"].ID, domain.CopyObjectRequest{DestinationPath: "b"})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+
+	// Overwriting replaces the destination object with the moved one
+	moved, err := svc.MoveObject(project.OrgID, objects["a"].ID, domain.CopyObjectRequest{DestinationPath: "b", Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, objects["a"].ID, moved.ID)
+	assert.Equal(t, "b", moved.Path)
+	_, err = svc.GetObject(objects["b"].ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	remaining, err := svc.ListObjects(domain.ObjectListOptions{BucketID: bucket.ID})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, moved.ID, remaining[0].ID)
+}
+
+func TestMoveObject_CrossProject(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	otherProject, err := svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "other-project", Name: "Other Project"})
+	require.NoError(t, err)
+	dest, err := svc.CreateBucket(otherProject.ID, domain.CreateBucketRequest{Name: "dest"})
+	require.NoError(t, err)
+
+	obj := createTestObjects(t, svc, bucket.ID, "logs/a")["logs/a"]
+	for _, content := range []string{"v2", "v3"} {
+		content := content
+		_, err := svc.UpdateObject(obj.ID, domain.UpdateObjectRequest{Content: &content})
+		require.NoError(t, err)
+	}
+
+	moved, err := svc.MoveObject(project.OrgID, obj.ID, domain.CopyObjectRequest{DestinationProject: "other-project", DestinationBucket: "dest"})
+	require.NoError(t, err)
+	assert.Equal(t, obj.ID, moved.ID)
+	assert.Equal(t, dest.ID, moved.BucketID)
+	assert.Equal(t, "logs/a", moved.Path)
+	assert.Equal(t, "v3", moved.Content)
+	assert.Equal(t, 3, moved.Version)
+
+	left, err := svc.ListObjects(domain.ObjectListOptions{BucketID: bucket.ID})
+	require.NoError(t, err)
+	assert.Empty(t, left)
+
+	// The noncurrent versions moved with the object
+	_, err = svc.PutBucketLifecycle(dest.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{{Prefix: "logs/", NoncurrentVersionExpirationDays: 7}}})
+	require.NoError(t, err)
+	result, err := svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 8))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.DeletedVersions)
+
+	// Projects of other organizations cannot be reached
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	_, err = svc.MoveObject(other.ID, obj.ID, domain.CopyObjectRequest{DestinationProject: "test-project", DestinationBucket: "test-bucket"})
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestCopyObject_DestinationQuota(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+	otherProject, err := svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "other-project", Name: "Other Project"})
+	require.NoError(t, err)
+	dest, err := svc.CreateBucket(otherProject.ID, domain.CreateBucketRequest{Name: "dest"})
+	require.NoError(t, err)
+
+	src, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a", Content: testContent(60)})
+	require.NoError(t, err)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: dest.ID, Path: "b", Content: testContent(50)})
+	require.NoError(t, err)
+
+	toDest := domain.CopyObjectRequest{DestinationProject: "other-project", DestinationBucket: "dest"}
+
+	_, err = svc.PutBucketQuota(dest.ID, domain.StorageQuota{MaxBytes: 100})
+	require.NoError(t, err)
+	_, err = svc.CopyObject(project.OrgID, src.ID, toDest)
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+	_, err = svc.MoveObject(project.OrgID, src.ID, toDest)
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+
+	_, err = svc.PutBucketQuota(dest.ID, domain.StorageQuota{})
+	require.NoError(t, err)
+	_, err = svc.PutProjectStorageQuota(otherProject.ID, domain.StorageQuota{MaxObjects: 1})
+	require.NoError(t, err)
+	_, err = svc.CopyObject(project.OrgID, src.ID, toDest)
+	assertQuotaExceeded(t, err, "project", domain.QuotaLimitMaxObjects)
+	_, err = svc.MoveObject(project.OrgID, src.ID, toDest)
+	assertQuotaExceeded(t, err, "project", domain.QuotaLimitMaxObjects)
+
+	// Copying over an object adds no object, but the replaced content stays as a noncurrent version
+	toDest.DestinationPath = "b"
+	toDest.Overwrite = true
+	copied, err := svc.CopyObject(project.OrgID, src.ID, toDest)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), copied.Size)
+
+	usage, err := svc.GetBucketUsage(dest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 110, Objects: 1}, usage.Usage)
+	got, err := svc.GetObject(src.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bucket.ID, got.BucketID)
+
+	// Moving over it replaces the object along with its versions
+	_, err = svc.MoveObject(project.OrgID, src.ID, toDest)
+	require.NoError(t, err)
+	usage, err = svc.GetBucketUsage(dest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 60, Objects: 1}, usage.Usage)
+}
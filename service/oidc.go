@@ -0,0 +1,217 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/oidc"
+)
+
+// OIDCTrustPolicyRepository defines the interface for OIDC trust policy data operations
+type OIDCTrustPolicyRepository interface {
+	Create(policy *domain.OIDCTrustPolicy) error
+	GetByID(id string) (*domain.OIDCTrustPolicy, error)
+	ListByOrgID(orgID string) ([]*domain.OIDCTrustPolicy, error)
+	Delete(id string) error
+}
+
+// OIDCVerifier verifies OIDC tokens of the trusted issuers
+type OIDCVerifier interface {
+	Verify(token string) (oidc.Claims, error)
+	Issuers() []string
+}
+
+// SetOIDC sets the repository of trust policies and the verifier used for OIDC token exchange
+func (s *Service) SetOIDC(repo OIDCTrustPolicyRepository, verifier OIDCVerifier) {
+	s.oidcRepo = repo
+	s.oidc = verifier
+}
+
+// CreateOIDCTrustPolicy creates a trust policy for an org
+// creator is the key making the request (nil for callers with full access). Keys minted by
+// the policy must be within the creator's access, and since the policy outlives any expiry
+// only keys without one can create policies
+func (s *Service) CreateOIDCTrustPolicy(orgID string, req domain.CreateOIDCTrustPolicyRequest, creator *domain.APIKey) (*domain.OIDCTrustPolicy, error) {
+	if s.oidcRepo == nil || s.oidc == nil {
+		return nil, domain.InternalError("OIDC is not configured")
+	}
+	if _, err := s.orgRepo.GetByID(orgID); err != nil {
+		return nil, err
+	}
+	if err := validateName(req.Name, "policy"); err != nil {
+		return nil, err
+	}
+
+	trusted := false
+	for _, issuer := range s.oidc.Issuers() {
+		trusted = trusted || issuer == req.Issuer
+	}
+	if !trusted {
+		return nil, domain.InvalidInputError("issuer is not configured on this server", map[string]interface{}{
+			"issuer":  req.Issuer,
+			"issuers": s.oidc.Issuers(),
+		})
+	}
+
+	// A policy without conditions would trust every token of the issuer, e.g. any GitHub repository
+	if len(req.Claims) == 0 {
+		return nil, domain.InvalidInputError("at least one claim condition is required", nil)
+	}
+	for claim, pattern := range req.Claims {
+		if claim == "" || strings.Trim(pattern, "*") == "" {
+			return nil, domain.InvalidInputError("claim conditions must match something more specific than *", map[string]interface{}{
+				"claim": claim,
+			})
+		}
+	}
+
+	ttl := domain.DefaultOIDCTokenTTL
+	if req.TokenTTLSeconds != 0 {
+		ttl = time.Duration(req.TokenTTLSeconds) * time.Second
+		if ttl < domain.MinOIDCTokenTTL || ttl > domain.MaxOIDCTokenTTL {
+			return nil, domain.InvalidInputError("token_ttl_seconds out of range", map[string]interface{}{
+				"min":    int(domain.MinOIDCTokenTTL.Seconds()),
+				"max":    int(domain.MaxOIDCTokenTTL.Seconds()),
+				"actual": req.TokenTTLSeconds,
+			})
+		}
+	}
+
+	// Scopes and projects are validated like those of API keys
+	grant, err := s.apiKeyGrant(orgID, req.Scopes, req.Projects)
+	if err != nil {
+		return nil, err
+	}
+	if creator != nil && (creator.ExpiresAt != nil || !creator.CanGrant(grant)) {
+		return nil, domain.ForbiddenError("API key cannot create a trust policy with more access than its own", nil)
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate policy ID")
+	}
+	policy := &domain.OIDCTrustPolicy{
+		ID:              id,
+		OrgID:           orgID,
+		Name:            req.Name,
+		Issuer:          req.Issuer,
+		Claims:          req.Claims,
+		Scopes:          grant.Scopes,
+		ProjectIDs:      grant.ProjectIDs,
+		TokenTTLSeconds: int(ttl.Seconds()),
+	}
+	if err := s.oidcRepo.Create(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListOIDCTrustPolicies lists the trust policies of an org
+func (s *Service) ListOIDCTrustPolicies(orgID string) ([]*domain.OIDCTrustPolicy, error) {
+	if s.oidcRepo == nil {
+		return nil, domain.InternalError("OIDC is not configured")
+	}
+	return s.oidcRepo.ListByOrgID(orgID)
+}
+
+// GetOIDCTrustPolicy retrieves a trust policy of an org
+func (s *Service) GetOIDCTrustPolicy(orgID, policyID string) (*domain.OIDCTrustPolicy, error) {
+	if s.oidcRepo == nil {
+		return nil, domain.InternalError("OIDC is not configured")
+	}
+	policy, err := s.oidcRepo.GetByID(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.OrgID != orgID {
+		return nil, domain.NotFoundError("oidc_trust_policy", policyID)
+	}
+	return policy, nil
+}
+
+// DeleteOIDCTrustPolicy deletes a trust policy of an org
+// Keys already minted by the policy stay valid until they expire
+func (s *Service) DeleteOIDCTrustPolicy(orgID, policyID string, creator *domain.APIKey) error {
+	policy, err := s.GetOIDCTrustPolicy(orgID, policyID)
+	if err != nil {
+		return err
+	}
+	grant := &domain.APIKey{Scopes: policy.Scopes, ProjectIDs: policy.ProjectIDs}
+	if creator != nil && (creator.ExpiresAt != nil || !creator.CanGrant(grant)) {
+		return domain.ForbiddenError("API key cannot delete a trust policy with more access than its own", nil)
+	}
+	return s.oidcRepo.Delete(policyID)
+}
+
+// ExchangeOIDCToken verifies an OIDC token and, if a trust policy of the org matches its
+// claims, returns a new API key with the policy's scopes and projects that expires after
+// the policy's token TTL. The first matching policy (oldest first) is used
+func (s *Service) ExchangeOIDCToken(orgID string, req domain.OIDCTokenExchangeRequest) (*domain.APIKeyWithToken, error) {
+	if s.oidcRepo == nil || s.oidc == nil {
+		return nil, domain.InternalError("OIDC is not configured")
+	}
+	if req.Token == "" {
+		return nil, domain.InvalidInputError("token is required", nil)
+	}
+
+	claims, err := s.oidc.Verify(req.Token)
+	if err != nil {
+		return nil, domain.UnauthorizedError("invalid OIDC token: " + err.Error())
+	}
+
+	policies, err := s.oidcRepo.ListByOrgID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	var policy *domain.OIDCTrustPolicy
+	for _, p := range policies {
+		if p.Issuer == claims.String("iss") && matchClaims(p.Claims, claims) {
+			policy = p
+			break
+		}
+	}
+	if policy == nil {
+		return nil, domain.ForbiddenError("no trust policy of the organization matches the token", map[string]interface{}{
+			"iss": claims.String("iss"),
+			"sub": claims.String("sub"),
+		})
+	}
+
+	// Minted keys pile up, so clear out the ones that have expired
+	// Expired keys created by users are left for them to delete
+	now := time.Now()
+	if err := s.apiKeyRepo.DeleteExpired(orgID, domain.APIKeySourceOIDC, now); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.UTC().Add(time.Duration(policy.TokenTTLSeconds) * time.Second).Truncate(time.Second)
+	return s.createAPIKey(&domain.APIKey{
+		OrgID:      orgID,
+		Name:       "oidc:" + policy.Name,
+		Scopes:     policy.Scopes,
+		ProjectIDs: policy.ProjectIDs,
+		ExpiresAt:  &expiresAt,
+		Source:     domain.APIKeySourceOIDC,
+	})
+}
+
+// matchClaims reports whether every claim condition of a policy matches the token's claims
+func matchClaims(conditions map[string]string, claims oidc.Claims) bool {
+	for claim, pattern := range conditions {
+		if !matchPattern(pattern, claims.String(claim)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPattern matches value against a pattern in which * matches any characters (including none)
+func matchPattern(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(value)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOIDCIssuer = "https://token.actions.example.com"
+
+// fakeVerifier trusts testOIDCIssuer and accepts the tokens in the map, mapped to their claims
+type fakeVerifier map[string]oidc.Claims
+
+func (v fakeVerifier) Verify(token string) (oidc.Claims, error) {
+	claims, ok := v[token]
+	if !ok {
+		return nil, errors.New("invalid signature")
+	}
+	return claims, nil
+}
+
+func (v fakeVerifier) Issuers() []string {
+	return []string{testOIDCIssuer}
+}
+
+func TestExchangeOIDCToken(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	svc.oidc = fakeVerifier{
+		"main":   {"iss": testOIDCIssuer, "sub": "repo:acme/infra:ref:refs/heads/main", "repository": "acme/infra"},
+		"branch": {"iss": testOIDCIssuer, "sub": "repo:acme/infra:ref:refs/heads/feature", "repository": "acme/infra"},
+		"fork":   {"iss": testOIDCIssuer, "sub": "repo:evil/infra:ref:refs/heads/main", "repository": "evil/infra"},
+	}
+
+	policy, err := svc.CreateOIDCTrustPolicy(project.OrgID, domain.CreateOIDCTrustPolicyRequest{
+		Name:     "deploy",
+		Issuer:   testOIDCIssuer,
+		Claims:   map[string]string{"sub": "repo:acme/infra:ref:refs/heads/main", "repository": "acme/*"},
+		Scopes:   []string{domain.ScopeInstancesWrite},
+		Projects: []string{"test-project"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int(domain.DefaultOIDCTokenTTL.Seconds()), policy.TokenTTLSeconds)
+	assert.Equal(t, []string{project.ID}, policy.ProjectIDs)
+
+	key, err := svc.ExchangeOIDCToken(project.OrgID, domain.OIDCTokenExchangeRequest{Token: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, "oidc:deploy", key.Name)
+	assert.Equal(t, []string{domain.ScopeInstancesWrite}, key.Scopes)
+	assert.Equal(t, []string{project.ID}, key.ProjectIDs)
+	require.NotNil(t, key.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(domain.DefaultOIDCTokenTTL), *key.ExpiresAt, 5*time.Second)
+
+	authKey, org, err := svc.AuthenticateAPIKey(key.Token)
+	require.NoError(t, err)
+	assert.Equal(t, project.OrgID, org.ID)
+	assert.True(t, authKey.HasScope(domain.ScopeInstancesRead))
+
+	// Tokens of other branches or repositories match no policy
+	for _, token := range []string{"branch", "fork"} {
+		_, err = svc.ExchangeOIDCToken(project.OrgID, domain.OIDCTokenExchangeRequest{Token: token})
+		assert.True(t, domain.IsForbidden(err), "expected forbidden for %s, got %v", token, err)
+	}
+
+	_, err = svc.ExchangeOIDCToken(project.OrgID, domain.OIDCTokenExchangeRequest{Token: "forged"})
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+
+	// Policies of other orgs do not apply
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	_, err = svc.ExchangeOIDCToken(other.ID, domain.OIDCTokenExchangeRequest{Token: "main"})
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+
+	// Deleting the policy stops the exchange
+	require.NoError(t, svc.DeleteOIDCTrustPolicy(project.OrgID, policy.ID, nil))
+	_, err = svc.ExchangeOIDCToken(project.OrgID, domain.OIDCTokenExchangeRequest{Token: "main"})
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+}
+
+func TestExchangeOIDCToken_DeletesExpiredMintedKeys(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	svc.oidc = fakeVerifier{"main": {"iss": testOIDCIssuer, "sub": "repo:acme/infra:ref:refs/heads/main"}}
+	_, err := svc.CreateOIDCTrustPolicy(project.OrgID, domain.CreateOIDCTrustPolicyRequest{
+		Name:   "deploy",
+		Issuer: testOIDCIssuer,
+		Claims: map[string]string{"sub": "repo:acme/infra:*"},
+	}, nil)
+	require.NoError(t, err)
+
+	expired := time.Now().Add(-time.Hour)
+	minted, err := svc.createAPIKey(&domain.APIKey{OrgID: project.OrgID, Name: "oidc:deploy", ExpiresAt: &expired, Source: domain.APIKeySourceOIDC})
+	require.NoError(t, err)
+	// Keys created by users are kept, even one named like a minted key
+	userKeys := []string{}
+	for _, name := range []string{"ci", "oidc:deploy"} {
+		key, err := svc.createAPIKey(&domain.APIKey{OrgID: project.OrgID, Name: name, ExpiresAt: &expired})
+		require.NoError(t, err)
+		userKeys = append(userKeys, key.ID)
+	}
+
+	key, err := svc.ExchangeOIDCToken(project.OrgID, domain.OIDCTokenExchangeRequest{Token: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.APIKeySourceOIDC, key.Source)
+
+	_, err = svc.apiKeyRepo.GetByID(minted.ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+	for _, id := range append(userKeys, key.ID) {
+		_, err = svc.apiKeyRepo.GetByID(id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCreateOIDCTrustPolicy_InvalidInput(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	valid := func(modify func(req *domain.CreateOIDCTrustPolicyRequest)) domain.CreateOIDCTrustPolicyRequest {
+		req := domain.CreateOIDCTrustPolicyRequest{
+			Name:   "deploy",
+			Issuer: testOIDCIssuer,
+			Claims: map[string]string{"repository": "acme/infra"},
+		}
+		modify(&req)
+		return req
+	}
+
+	for name, req := range map[string]domain.CreateOIDCTrustPolicyRequest{
+		"empty name":     valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.Name = "" }),
+		"unknown issuer": valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.Issuer = "https://example.com" }),
+		"no claims":      valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.Claims = nil }),
+		"wildcard claim": valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.Claims = map[string]string{"sub": "**"} }),
+		"invalid scope":  valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.Scopes = []string{"projects:admin"} }),
+		"ttl too long":   valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.TokenTTLSeconds = 13 * 3600 }),
+		"ttl too short":  valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.TokenTTLSeconds = 30 }),
+		"negative ttl":   valid(func(req *domain.CreateOIDCTrustPolicyRequest) { req.TokenTTLSeconds = -60 }),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateOIDCTrustPolicy(project.OrgID, req, nil)
+			assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+		})
+	}
+
+	// Restricted keys cannot create policies with more access than their own
+	creator := &domain.APIKey{Scopes: []string{domain.ScopeInstancesWrite, domain.ScopeAPIKeysWrite}}
+	_, err := svc.CreateOIDCTrustPolicy(project.OrgID, valid(func(req *domain.CreateOIDCTrustPolicyRequest) {}), creator)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+	_, err = svc.CreateOIDCTrustPolicy(project.OrgID, valid(func(req *domain.CreateOIDCTrustPolicyRequest) {
+		req.Scopes = []string{domain.ScopeInstancesRead}
+	}), creator)
+	assert.NoError(t, err)
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, value string
+		match          bool
+	}{
+		{"acme/infra", "acme/infra", true},
+		{"acme/infra", "acme/infra2", false},
+		{"acme/*", "acme/infra", true},
+		{"acme/*", "acme/", true},
+		{"acme/*", "evil/acme/infra", false},
+		{"repo:acme/*:ref:refs/tags/v*", "repo:acme/infra:ref:refs/tags/v1.2", true},
+		{"repo:acme/*:ref:refs/tags/v*", "repo:acme/infra:ref:refs/heads/v1", false},
+		{"a.c", "abc", false},
+	} {
+		assert.Equal(t, tc.match, matchPattern(tc.pattern, tc.value), "%q ~ %q", tc.pattern, tc.value)
+	}
+}
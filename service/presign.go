@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+)
+
+const (
+	defaultPresignExpiry = time.Hour
+	maxPresignExpiry     = 7 * 24 * time.Hour
+)
+
+// presignedObjectClaims is the signed payload of a presigned object URL token
+// Bucket IDs are names that can be taken by another org once the bucket is deleted
+// or renamed, so the token also records the project the bucket belonged to
+type presignedObjectClaims struct {
+	ProjectID string `json:"j"`
+	BucketID  string `json:"b"`
+	Path      string `json:"p"`
+	Method    string `json:"m"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// PresignObject issues a time-limited signed URL for a GET or PUT on an object path
+// The returned URL is relative to the server root
+func (s *Service) PresignObject(bucketID string, req domain.PresignObjectRequest) (*domain.PresignedObjectURL, error) {
+	method := strings.ToUpper(req.Method)
+	if method != http.MethodGet && method != http.MethodPut {
+		return nil, domain.InvalidInputError("invalid method", map[string]interface{}{
+			"valid_methods": []string{http.MethodGet, http.MethodPut},
+			"actual":        req.Method,
+		})
+	}
+	if err := validateObjectPath(req.Path); err != nil {
+		return nil, err
+	}
+
+	expiry := defaultPresignExpiry
+	if req.ExpiresIn != 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if expiry <= 0 || expiry > maxPresignExpiry {
+		return nil, domain.InvalidInputError("invalid expires_in", map[string]interface{}{
+			"min_seconds": 1,
+			"max_seconds": int(maxPresignExpiry.Seconds()),
+			"actual":      req.ExpiresIn,
+		})
+	}
+
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+	token, err := s.signToken(endec.PrefixSignature, presignedObjectClaims{
+		ProjectID: bucket.ProjectID,
+		BucketID:  bucket.ID,
+		Path:      req.Path,
+		Method:    method,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PresignedObjectURL{
+		URL:       "/v1/presigned/" + token,
+		Method:    method,
+		Path:      req.Path,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// verifyPresignedObject validates a presigned token for the given HTTP method
+// The bucket must still belong to the project it was presigned in
+func (s *Service) verifyPresignedObject(token, method string) (*presignedObjectClaims, error) {
+	var claims presignedObjectClaims
+	if err := s.verifyToken(token, endec.PrefixSignature, &claims); err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, domain.UnauthorizedError("presigned URL has expired")
+	}
+	if claims.Method != method {
+		return nil, domain.UnauthorizedError("presigned URL does not allow " + method)
+	}
+
+	bucket, err := s.bucketRepo.GetByID(claims.BucketID)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	if bucket == nil || bucket.ProjectID != claims.ProjectID {
+		return nil, domain.UnauthorizedError("presigned URL bucket no longer exists")
+	}
+	return &claims, nil
+}
+
+// GetPresignedObject returns the object referenced by a presigned GET token
+func (s *Service) GetPresignedObject(token string) (*domain.Object, error) {
+	claims, err := s.verifyPresignedObject(token, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	return s.objectRepo.GetByPath(claims.BucketID, claims.Path)
+}
+
+// PutPresignedObject stores raw content at the path referenced by a presigned PUT token,
+// creating the object or replacing its content. It reports whether the object was created.
+func (s *Service) PutPresignedObject(token string, content []byte) (*domain.Object, bool, error) {
+	claims, err := s.verifyPresignedObject(token, http.MethodPut)
+	if err != nil {
+		return nil, false, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(content)
+	existing, err := s.objectRepo.GetByPath(claims.BucketID, claims.Path)
+	if err != nil {
+		if !domain.IsNotFound(err) {
+			return nil, false, err
+		}
+		obj, err := s.CreateObject(domain.CreateObjectRequest{
+			BucketID: claims.BucketID,
+			Path:     claims.Path,
+			Content:  encoded,
+		})
+		return obj, err == nil, err
+	}
+
+	obj, err := s.UpdateObject(existing.ID, domain.UpdateObjectRequest{Content: &encoded})
+	return obj, false, err
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// presignToken presigns a method on a path of a bucket and returns the token of the URL
+func presignToken(t *testing.T, svc *Service, bucketID, method, path string) string {
+	t.Helper()
+
+	url, err := svc.PresignObject(bucketID, domain.PresignObjectRequest{Method: method, Path: path})
+	require.NoError(t, err)
+	return strings.TrimPrefix(url.URL, "/v1/presigned/")
+}
+
+// retoken re-encodes a token with a prefix and data
+func retoken(t *testing.T, prefix string, data []byte) string {
+	t.Helper()
+
+	token, err := endec.CreateTokenFromData(prefix, data)
+	require.NoError(t, err)
+	return token
+}
+
+func TestPresignObject(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	bucket := createTestBucket(t, svc)
+
+	put := presignToken(t, svc, bucket.ID, "put", "docs/a.txt")
+	obj, created, err := svc.PutPresignedObject(put, []byte("hello"))
+	require.NoError(t, err)
+	assert.True(t, created)
+	_, created, err = svc.PutPresignedObject(put, []byte("hello again"))
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	got, err := svc.GetPresignedObject(presignToken(t, svc, bucket.ID, http.MethodGet, "docs/a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, obj.ID, got.ID)
+	assert.Equal(t, 2, got.Version)
+
+	_, err = svc.PresignObject(bucket.ID, domain.PresignObjectRequest{Method: http.MethodDelete, Path: "docs/a.txt"})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = svc.PresignObject(bucket.ID, domain.PresignObjectRequest{Method: http.MethodGet, Path: "docs/a.txt", ExpiresIn: 8 * 24 * 3600})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = svc.PresignObject("missing", domain.PresignObjectRequest{Method: http.MethodGet, Path: "docs/a.txt"})
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestPresignObject_Rejected(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	bucket := createTestBucket(t, svc)
+	other, err := svc.CreateBucket(bucket.ProjectID, domain.CreateBucketRequest{Name: "other-bucket"})
+	require.NoError(t, err)
+	for _, b := range []*domain.Bucket{bucket, other} {
+		_, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: b.ID, Path: "a.txt", Content: "aGVsbG8="})
+		require.NoError(t, err)
+		_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: b.ID, Path: "b.txt", Content: "aGVsbG8="})
+		require.NoError(t, err)
+	}
+
+	get := presignToken(t, svc, bucket.ID, http.MethodGet, "a.txt")
+	_, data, err := endec.ParseToken(get)
+	require.NoError(t, err)
+
+	// Claims moved to another object or bucket no longer match the signature
+	for _, replace := range [][2]string{{`"p":"a.txt"`, `"p":"b.txt"`}, {bucket.ID, other.ID}} {
+		require.True(t, bytes.Contains(data, []byte(replace[0])))
+		tampered := retoken(t, endec.PrefixSignature, bytes.Replace(data, []byte(replace[0]), []byte(replace[1]), 1))
+		_, err = svc.GetPresignedObject(tampered)
+		assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	}
+
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-1] ^= 1
+	expired, err := svc.signToken(endec.PrefixSignature, presignedObjectClaims{ProjectID: bucket.ProjectID, BucketID: bucket.ID, Path: "a.txt", Method: http.MethodGet, ExpiresAt: time.Now().Add(-time.Second).Unix()})
+	require.NoError(t, err)
+	otherSecret := setupTestService(t)
+	otherSecret.SetSigningSecret([]byte("other-secret"))
+	forged, err := otherSecret.signToken(endec.PrefixSignature, presignedObjectClaims{ProjectID: bucket.ProjectID, BucketID: bucket.ID, Path: "a.txt", Method: http.MethodGet, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"tampered signature", retoken(t, endec.PrefixSignature, flipped)},
+		{"wrong prefix", retoken(t, endec.PrefixSession, data)},
+		{"signed for another prefix", func() string {
+			token, err := svc.signToken(endec.PrefixSession, presignedObjectClaims{ProjectID: bucket.ProjectID, BucketID: bucket.ID, Path: "a.txt", Method: http.MethodGet, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+			require.NoError(t, err)
+			return token
+		}()},
+		{"other secret", forged},
+		{"expired", expired},
+		{"put token", presignToken(t, svc, bucket.ID, http.MethodPut, "a.txt")},
+		{"garbage", "nah_sig_!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetPresignedObject(tt.token)
+			assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+		})
+	}
+
+	// A GET token cannot upload
+	_, _, err = svc.PutPresignedObject(get, []byte("overwrite"))
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+
+	// The untampered token still reads its own object from its own bucket
+	obj, err := svc.GetPresignedObject(get)
+	require.NoError(t, err)
+	assert.Equal(t, bucket.ID, obj.BucketID)
+	assert.Equal(t, "a.txt", obj.Path)
+	assert.Equal(t, 1, obj.Version)
+}
+
+func TestPresignObject_BucketNameReused(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	bucket := createTestBucket(t, svc)
+	renamed, err := svc.CreateBucket(bucket.ProjectID, domain.CreateBucketRequest{Name: "renamed"})
+	require.NoError(t, err)
+
+	get := presignToken(t, svc, bucket.ID, http.MethodGet, "a.txt")
+	put := presignToken(t, svc, bucket.ID, http.MethodPut, "a.txt")
+	renamedPut := presignToken(t, svc, renamed.ID, http.MethodPut, "a.txt")
+
+	// Another org takes over both names once the buckets are deleted or renamed
+	require.NoError(t, svc.DeleteBucket(bucket.ID))
+	_, err = svc.RenameBucket(renamed.ID, "renamed-again")
+	require.NoError(t, err)
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	project, err := svc.CreateProject(org.ID, domain.CreateProjectRequest{Slug: "other-project", Name: "Other Project"})
+	require.NoError(t, err)
+	for _, name := range []string{bucket.Name, renamed.Name} {
+		b, err := svc.CreateBucket(project.ID, domain.CreateBucketRequest{Name: name})
+		require.NoError(t, err)
+		_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: b.ID, Path: "a.txt", Content: "c2VjcmV0"})
+		require.NoError(t, err)
+	}
+
+	_, err = svc.GetPresignedObject(get)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	for _, token := range []string{put, renamedPut} {
+		_, _, err = svc.PutPresignedObject(token, []byte("overwrite"))
+		assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	}
+
+	// The other org's objects are untouched
+	for _, name := range []string{bucket.Name, renamed.Name} {
+		objects, err := svc.ListObjects(domain.ObjectListOptions{BucketID: name})
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, 1, objects[0].Version)
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// validateStorageQuota validates a storage quota (zero means unlimited)
+func validateStorageQuota(quota domain.StorageQuota) error {
+	if quota.MaxBytes < 0 {
+		return domain.InvalidInputError("max_bytes cannot be negative", map[string]interface{}{"actual": quota.MaxBytes})
+	}
+	if quota.MaxObjects < 0 {
+		return domain.InvalidInputError("max_objects cannot be negative", map[string]interface{}{"actual": quota.MaxObjects})
+	}
+	return nil
+}
+
+// checkQuota returns a quota exceeded error if usage grown by the given deltas breaks quota
+// Only growth is checked so that objects can always be shrunk or deleted when over quota
+func checkQuota(resource string, quota domain.StorageQuota, usage domain.StorageUsage, deltaBytes, deltaObjects int64) error {
+	if quota.MaxBytes > 0 && deltaBytes > 0 && usage.Bytes+deltaBytes > quota.MaxBytes {
+		return domain.QuotaExceededError(resource, domain.QuotaLimitMaxBytes, quota.MaxBytes, usage.Bytes+deltaBytes)
+	}
+	if quota.MaxObjects > 0 && deltaObjects > 0 && usage.Objects+deltaObjects > quota.MaxObjects {
+		return domain.QuotaExceededError(resource, domain.QuotaLimitMaxObjects, quota.MaxObjects, usage.Objects+deltaObjects)
+	}
+	return nil
+}
+
+// checkStorageQuota verifies that adding deltaBytes and deltaObjects to a bucket
+// stays within both the bucket quota and the quota of its project
+func (s *Service) checkStorageQuota(bucket *domain.Bucket, deltaBytes, deltaObjects int64) error {
+	if err := s.checkBucketQuota(bucket, deltaBytes, deltaObjects); err != nil {
+		return err
+	}
+	return s.checkProjectQuota(bucket.ProjectID, deltaBytes, deltaObjects)
+}
+
+// checkBucketQuota verifies that adding deltaBytes and deltaObjects stays within the bucket quota
+func (s *Service) checkBucketQuota(bucket *domain.Bucket, deltaBytes, deltaObjects int64) error {
+	if (deltaBytes <= 0 && deltaObjects <= 0) || bucket.Quota == (domain.StorageQuota{}) {
+		return nil
+	}
+	usage, err := s.bucketRepo.GetUsage(bucket.ID)
+	if err != nil {
+		return err
+	}
+	return checkQuota("bucket", bucket.Quota, *usage, deltaBytes, deltaObjects)
+}
+
+// checkProjectQuota verifies that adding deltaBytes and deltaObjects stays within the project quota
+func (s *Service) checkProjectQuota(projectID string, deltaBytes, deltaObjects int64) error {
+	if deltaBytes <= 0 && deltaObjects <= 0 {
+		return nil
+	}
+	quota, err := s.projectRepo.GetStorageQuota(projectID)
+	if err != nil {
+		return err
+	}
+	if *quota == (domain.StorageQuota{}) {
+		return nil
+	}
+	usage, err := s.projectStorageUsage(projectID)
+	if err != nil {
+		return err
+	}
+	return checkQuota("project", *quota, usage.Usage, deltaBytes, deltaObjects)
+}
+
+// projectStorageUsage sums the usage of all buckets in a project
+func (s *Service) projectStorageUsage(projectID string) (*domain.ProjectStorageUsage, error) {
+	buckets, err := s.bucketRepo.ListUsage(projectID)
+	if err != nil {
+		return nil, err
+	}
+	usage := &domain.ProjectStorageUsage{ProjectID: projectID, Buckets: buckets}
+	for _, b := range buckets {
+		usage.Usage.Bytes += b.Usage.Bytes
+		usage.Usage.Objects += b.Usage.Objects
+	}
+	return usage, nil
+}
+
+// GetBucketQuota returns the storage quota of a bucket
+func (s *Service) GetBucketQuota(bucketID string) (*domain.StorageQuota, error) {
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket.Quota, nil
+}
+
+// PutBucketQuota replaces the storage quota of a bucket
+// Lowering a quota below current usage is allowed; it only blocks further growth
+func (s *Service) PutBucketQuota(bucketID string, quota domain.StorageQuota) (*domain.StorageQuota, error) {
+	if err := validateStorageQuota(quota); err != nil {
+		return nil, err
+	}
+	bucket, err := s.bucketRepo.UpdateQuota(bucketID, quota)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket.Quota, nil
+}
+
+// GetBucketUsage returns the usage and quota of a bucket
+func (s *Service) GetBucketUsage(bucketID string) (*domain.BucketUsage, error) {
+	bucket, err := s.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.bucketRepo.GetUsage(bucket.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BucketUsage{
+		BucketID:   bucket.ID,
+		BucketName: bucket.Name,
+		Usage:      *usage,
+		Quota:      bucket.Quota,
+	}, nil
+}
+
+// GetProjectStorageQuota returns the storage quota of a project
+func (s *Service) GetProjectStorageQuota(projectID string) (*domain.StorageQuota, error) {
+	return s.projectRepo.GetStorageQuota(projectID)
+}
+
+// PutProjectStorageQuota replaces the storage quota of a project
+func (s *Service) PutProjectStorageQuota(projectID string, quota domain.StorageQuota) (*domain.StorageQuota, error) {
+	if err := validateStorageQuota(quota); err != nil {
+		return nil, err
+	}
+	if err := s.projectRepo.UpdateStorageQuota(projectID, quota); err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// GetProjectStorageUsage returns the storage usage of a project broken down by bucket
+func (s *Service) GetProjectStorageUsage(projectID string) (*domain.ProjectStorageUsage, error) {
+	quota, err := s.projectRepo.GetStorageQuota(projectID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.projectStorageUsage(projectID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Quota = *quota
+	return usage, nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testContent returns base64 content that decodes to size bytes
+func testContent(size int) string {
+	return base64.StdEncoding.EncodeToString(make([]byte, size))
+}
+
+// assertQuotaExceeded checks that err is a quota exceeded error for a resource and limit
+func assertQuotaExceeded(t *testing.T, err error, resource, limit string) {
+	t.Helper()
+
+	require.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+	details := err.(*domain.NahError).Details
+	assert.Equal(t, resource, details["resource"])
+	assert.Equal(t, limit, details["limit"])
+}
+
+func TestStorageQuota_Bucket(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	_, err := svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: 100, MaxObjects: 3})
+	require.NoError(t, err)
+
+	create := func(path string, size int) (*domain.Object, error) {
+		return svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: path, Content: testContent(size)})
+	}
+
+	a, err := create("a", 60)
+	require.NoError(t, err)
+	_, err = create("b", 41)
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+	_, err = create("b", 40)
+	require.NoError(t, err)
+
+	_, err = create("c", 1)
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+	_, err = svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: 200, MaxObjects: 2})
+	require.NoError(t, err)
+	_, err = create("c", 1)
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxObjects)
+
+	// Rewriting the same content adds nothing, new content adds its full size
+	// because the replaced content is kept as a noncurrent version
+	_, err = svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: 150})
+	require.NoError(t, err)
+	content := testContent(60)
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+	content = testContent(51)
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{Content: &content})
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+	content = testContent(50)
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+
+	// Updates without new content are not checked
+	contentType := "text/plain"
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{ContentType: &contentType})
+	require.NoError(t, err)
+
+	usage, err := svc.GetBucketUsage(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 150, Objects: 2}, usage.Usage)
+}
+
+func TestStorageQuota_LoweredBelowUsage(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	a, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a", Content: testContent(50)})
+	require.NoError(t, err)
+	b, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "b", Content: testContent(50)})
+	require.NoError(t, err)
+
+	// Lowering a quota below usage is allowed and only blocks growth
+	quota, err := svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: 60, MaxObjects: 1})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageQuota{MaxBytes: 60, MaxObjects: 1}, *quota)
+
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "c", Content: testContent(1)})
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+	content := testContent(51)
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{Content: &content})
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxBytes)
+
+	// Deleting still works, and frees room for new content
+	require.NoError(t, svc.DeleteObject(b.ID))
+	content = testContent(5)
+	_, err = svc.UpdateObject(a.ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+
+	usage, err := svc.GetBucketUsage(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 55, Objects: 1}, usage.Usage)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "c", Content: testContent(1)})
+	assertQuotaExceeded(t, err, "bucket", domain.QuotaLimitMaxObjects)
+
+	_, err = svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: -1})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+}
+
+func TestStorageQuota_Project(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	other, err := svc.CreateBucket(bucket.ProjectID, domain.CreateBucketRequest{Name: "other-bucket"})
+	require.NoError(t, err)
+
+	_, err = svc.PutProjectStorageQuota(bucket.ProjectID, domain.StorageQuota{MaxBytes: 100, MaxObjects: 3})
+	require.NoError(t, err)
+
+	// The project quota counts the objects of all its buckets, even without bucket quotas
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a", Content: testContent(60)})
+	require.NoError(t, err)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: other.ID, Path: "a", Content: testContent(41)})
+	assertQuotaExceeded(t, err, "project", domain.QuotaLimitMaxBytes)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: other.ID, Path: "a", Content: testContent(20)})
+	require.NoError(t, err)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: other.ID, Path: "b", Content: testContent(10)})
+	require.NoError(t, err)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "b", Content: testContent(1)})
+	assertQuotaExceeded(t, err, "project", domain.QuotaLimitMaxObjects)
+
+	usage, err := svc.GetProjectStorageUsage(bucket.ProjectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 90, Objects: 3}, usage.Usage)
+	assert.Len(t, usage.Buckets, 2)
+}
+
+func TestStorageQuota_ConcurrentUploads(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	_, err := svc.PutBucketQuota(bucket.ID, domain.StorageQuota{MaxBytes: 100, MaxObjects: 3})
+	require.NoError(t, err)
+
+	// Uploads racing for the last of the quota cannot all pass the check
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: fmt.Sprintf("obj-%d", i), Content: testContent(30)})
+			if err != nil {
+				assert.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	usage, err := svc.GetBucketUsage(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 90, Objects: 3}, usage.Usage)
+}
+
+func TestStorageQuota_NoncurrentVersions(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+
+	obj, err := svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a", Content: testContent(40)})
+	require.NoError(t, err)
+	for _, size := range []int{30, 20} {
+		content := testContent(size)
+		_, err = svc.UpdateObject(obj.ID, domain.UpdateObjectRequest{Content: &content})
+		require.NoError(t, err)
+	}
+
+	// Replaced content counts toward usage until its versions are removed
+	usage, err := svc.GetBucketUsage(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 90, Objects: 1}, usage.Usage)
+	projectUsage, err := svc.GetProjectStorageUsage(bucket.ProjectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 90, Objects: 1}, projectUsage.Usage)
+
+	_, err = svc.PutProjectStorageQuota(bucket.ProjectID, domain.StorageQuota{MaxBytes: 100})
+	require.NoError(t, err)
+	content := testContent(11)
+	_, err = svc.UpdateObject(obj.ID, domain.UpdateObjectRequest{Content: &content})
+	assertQuotaExceeded(t, err, "project", domain.QuotaLimitMaxBytes)
+
+	_, err = svc.PutBucketLifecycle(bucket.ID, domain.BucketLifecycle{Rules: []domain.LifecycleRule{{NoncurrentVersionExpirationDays: 1}}})
+	require.NoError(t, err)
+	_, err = svc.ApplyLifecycleRules(time.Now().AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	usage, err = svc.GetBucketUsage(bucket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Bytes: 20, Objects: 1}, usage.Usage)
+	_, err = svc.UpdateObject(obj.ID, domain.UpdateObjectRequest{Content: &content})
+	require.NoError(t, err)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/ratelimit"
+)
+
+// SetRateLimits sets the API request limits enforced by CheckRateLimit
+func (s *Service) SetRateLimits(limits domain.RateLimits) {
+	s.rateLimits = limits
+	if s.limiter == nil {
+		s.limiter = ratelimit.New()
+	}
+}
+
+// CheckRateLimit counts an API request of an org made with an API key against the global,
+// org and key limits. It returns the state of the most restrictive limit, and a too many
+// requests error if any limit is exhausted (in which case the request counts against none)
+func (s *Service) CheckRateLimit(orgID, keyID string) (ratelimit.Result, error) {
+	if s.limiter == nil {
+		return ratelimit.Result{Allowed: true}, nil
+	}
+
+	result := s.limiter.Allow(
+		ratelimit.Request{Key: "global", Limit: ratelimit.PerMinute(s.rateLimits.Global)},
+		ratelimit.Request{Key: "org:" + orgID, Limit: ratelimit.PerMinute(s.rateLimits.PerOrg)},
+		ratelimit.Request{Key: "key:" + keyID, Limit: ratelimit.PerMinute(s.rateLimits.PerKey)},
+	)
+	if !result.Allowed {
+		return result, domain.TooManyRequestsError("rate limit exceeded, retry later")
+	}
+	return result, nil
+}
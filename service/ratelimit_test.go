@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRateLimit(t *testing.T) {
+	svc := setupTestService(t)
+
+	// Unlimited until limits are set
+	for i := 0; i < 10; i++ {
+		_, err := svc.CheckRateLimit("org-1", "key-1")
+		require.NoError(t, err)
+	}
+
+	svc.SetRateLimits(domain.RateLimits{PerOrg: 3, PerKey: 2})
+
+	result, err := svc.CheckRateLimit("org-1", "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+
+	_, err = svc.CheckRateLimit("org-1", "key-1")
+	require.NoError(t, err)
+
+	// The key is exhausted, other keys of the org are not
+	result, err = svc.CheckRateLimit("org-1", "key-1")
+	assert.True(t, domain.IsTooManyRequests(err), "expected too many requests, got %v", err)
+	assert.Positive(t, result.RetryAfter)
+
+	result, err = svc.CheckRateLimit("org-1", "key-2")
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Limit)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Now the org is exhausted
+	_, err = svc.CheckRateLimit("org-1", "key-3")
+	assert.True(t, domain.IsTooManyRequests(err), "expected too many requests, got %v", err)
+	_, err = svc.CheckRateLimit("org-2", "key-4")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"regexp"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// regionNamePattern is what region and zone names may contain
+var regionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// cloneRegions copies regions, so that they can be changed without affecting the original
+func cloneRegions(regions []domain.Region) []domain.Region {
+	clone := make([]domain.Region, len(regions))
+	for i, r := range regions {
+		clone[i] = r
+		clone[i].Zones = append([]string{}, r.Zones...)
+	}
+	return clone
+}
+
+// SetRegions replaces the regions (domain.DefaultRegions by default)
+// Existing instances in regions that are removed keep running
+func (s *Service) SetRegions(regions []domain.Region) error {
+	if len(regions) == 0 {
+		return domain.InvalidInputError("at least one region is required", nil)
+	}
+	seen := map[string]bool{}
+	for _, r := range regions {
+		if !regionNamePattern.MatchString(r.Name) {
+			return domain.InvalidInputError("invalid region name", map[string]interface{}{"region": r.Name})
+		}
+		if seen[r.Name] {
+			return domain.AlreadyExistsError("region", "name", r.Name)
+		}
+		seen[r.Name] = true
+	}
+	for _, r := range regions {
+		for _, zone := range r.Zones {
+			if !regionNamePattern.MatchString(zone) {
+				return domain.InvalidInputError("invalid zone name", map[string]interface{}{"region": r.Name, "zone": zone})
+			}
+			if seen[zone] {
+				return domain.AlreadyExistsError("zone", "name", zone)
+			}
+			seen[zone] = true
+		}
+	}
+
+	s.regionsMu.Lock()
+	defer s.regionsMu.Unlock()
+	s.regions = cloneRegions(regions)
+	return nil
+}
+
+// ListRegions lists the regions
+func (s *Service) ListRegions() []domain.Region {
+	s.regionsMu.RLock()
+	defer s.regionsMu.RUnlock()
+	return cloneRegions(s.regions)
+}
+
+// GetRegion retrieves a region by name
+func (s *Service) GetRegion(name string) (*domain.Region, error) {
+	for _, r := range s.ListRegions() {
+		if r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, domain.NotFoundError("region", name)
+}
+
+// ListZones lists the zones of a region
+func (s *Service) ListZones(region string) ([]domain.Zone, error) {
+	r, err := s.GetRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	zones := []domain.Zone{}
+	for _, zone := range r.Zones {
+		zones = append(zones, domain.Zone{Name: zone, Region: r.Name})
+	}
+	return zones, nil
+}
+
+// UpdateRegion updates a region, e.g. marks it as down to simulate an outage
+// Changes are not persisted, so a restart restores the configured regions
+func (s *Service) UpdateRegion(name string, req domain.UpdateRegionRequest) (*domain.Region, error) {
+	s.regionsMu.Lock()
+	defer s.regionsMu.Unlock()
+	for i := range s.regions {
+		if s.regions[i].Name != name {
+			continue
+		}
+		if req.Down != nil {
+			s.regions[i].Down = *req.Down
+		}
+		region := cloneRegions(s.regions[i : i+1])[0]
+		return &region, nil
+	}
+	return nil, domain.NotFoundError("region", name)
+}
+
+// region finds the region of a request, reporting the valid ones if it does not exist
+func (s *Service) region(name string) (*domain.Region, error) {
+	if name == "" {
+		return nil, domain.InvalidInputError("region is required", nil)
+	}
+	regions := s.ListRegions()
+	var names []string
+	for _, r := range regions {
+		if r.Name == name {
+			return &r, nil
+		}
+		names = append(names, r.Name)
+	}
+	return nil, domain.InvalidInputError("invalid region", map[string]interface{}{
+		"valid_regions": names,
+		"actual":        name,
+	})
+}
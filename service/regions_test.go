@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegions_CreateInstance(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	create := func(name, region, zone string) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: region, Zone: zone, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	}
+
+	instance, err := create("web-1", "us-east-1", "us-east-1b")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1b", instance.Zone)
+	instance, err = svc.GetInstance(instance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1b", instance.Zone)
+
+	_, err = create("web-2", "us-east-1", "eu-west-1a")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = create("web-2", "mars-1", "")
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	// A region that is down rejects new instances until it is back up
+	down := true
+	region, err := svc.UpdateRegion("us-east-1", domain.UpdateRegionRequest{Down: &down})
+	require.NoError(t, err)
+	assert.True(t, region.Down)
+	_, err = create("web-2", "us-east-1", "")
+	assert.True(t, domain.IsServiceUnavailable(err), "expected service unavailable, got %v", err)
+	_, err = create("web-2", "eu-west-1", "")
+	require.NoError(t, err)
+
+	down = false
+	_, err = svc.UpdateRegion("us-east-1", domain.UpdateRegionRequest{Down: &down})
+	require.NoError(t, err)
+	_, err = create("web-3", "us-east-1", "")
+	require.NoError(t, err)
+
+	_, err = svc.UpdateRegion("mars-1", domain.UpdateRegionRequest{Down: &down})
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestSetRegions(t *testing.T) {
+	svc := setupTestService(t)
+
+	tests := []struct {
+		name    string
+		regions []domain.Region
+	}{
+		{"empty", nil},
+		{"bad name", []domain.Region{{Name: "US East"}}},
+		{"duplicate region", []domain.Region{{Name: "r1"}, {Name: "r1"}}},
+		{"duplicate zone", []domain.Region{{Name: "r1", Zones: []string{"z1"}}, {Name: "r2", Zones: []string{"z1"}}}},
+		{"zone named like a region", []domain.Region{{Name: "r1"}, {Name: "r2", Zones: []string{"r1"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, svc.SetRegions(tt.regions))
+		})
+	}
+
+	regions := []domain.Region{
+		{Name: "moon-1", Zones: []string{"moon-1a", "moon-1b"}},
+		{Name: "moon-2", Down: true},
+	}
+	require.NoError(t, svc.SetRegions(regions))
+	regions[0].Zones[0] = "changed"
+
+	assert.Len(t, svc.ListRegions(), 2)
+	zones, err := svc.ListZones("moon-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Zone{{Name: "moon-1a", Region: "moon-1"}, {Name: "moon-1b", Region: "moon-1"}}, zones)
+	_, err = svc.ListZones("us-east-1")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	region, err := svc.GetRegion("moon-2")
+	require.NoError(t, err)
+	assert.True(t, region.Down)
+
+	// Instance types are checked against the configured regions
+	assert.Error(t, svc.SetInstanceTypes([]domain.InstanceType{{Name: "a", CPU: 1, MemoryMB: 512, Regions: []string{"us-east-1"}}}))
+	assert.NoError(t, svc.SetInstanceTypes([]domain.InstanceType{{Name: "a", CPU: 1, MemoryMB: 512, Regions: []string{"moon-1"}}}))
+}
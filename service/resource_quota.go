@@ -0,0 +1,192 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// SetDefaultResourceQuota sets the resource quota of orgs without a quota of their own
+func (s *Service) SetDefaultResourceQuota(quota domain.ResourceQuota) error {
+	if err := validateResourceQuota(quota); err != nil {
+		return err
+	}
+	s.defaultResourceQuota = quota
+	return nil
+}
+
+// validateResourceQuota validates a resource quota (zero means unlimited)
+func validateResourceQuota(quota domain.ResourceQuota) error {
+	for field, value := range map[string]int{
+		domain.QuotaLimitMaxProjects:            quota.MaxProjects,
+		domain.QuotaLimitMaxInstancesPerProject: quota.MaxInstancesPerProject,
+		domain.QuotaLimitMaxCPUPerRegion:        quota.MaxCPUPerRegion,
+		domain.QuotaLimitMaxMemoryMBPerRegion:   quota.MaxMemoryMBPerRegion,
+	} {
+		if value < 0 {
+			return domain.InvalidInputError(field+" cannot be negative", map[string]interface{}{"actual": value})
+		}
+	}
+	return nil
+}
+
+// resourceQuota returns the quota in effect for an org and whether it is the org's own
+func (s *Service) resourceQuota(orgID string) (domain.ResourceQuota, bool, error) {
+	quota, err := s.orgRepo.GetResourceQuota(orgID)
+	if err != nil {
+		return domain.ResourceQuota{}, false, err
+	}
+	if quota == nil {
+		return s.defaultResourceQuota, false, nil
+	}
+	return *quota, true, nil
+}
+
+// GetResourceQuota returns the resource quota in effect for an org
+func (s *Service) GetResourceQuota(orgID string) (*domain.ResourceQuota, error) {
+	quota, _, err := s.resourceQuota(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// PutResourceQuota gives an org its own resource quota, replacing the server default
+// Existing resources over the new quota are kept; only new ones are refused
+func (s *Service) PutResourceQuota(orgID string, quota domain.ResourceQuota) (*domain.ResourceQuota, error) {
+	if err := validateResourceQuota(quota); err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.UpdateResourceQuota(orgID, &quota); err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// ResetResourceQuota reverts an org to the server's default resource quota
+func (s *Service) ResetResourceQuota(orgID string) (*domain.ResourceQuota, error) {
+	if err := s.orgRepo.UpdateResourceQuota(orgID, nil); err != nil {
+		return nil, err
+	}
+	quota := s.defaultResourceQuota
+	return &quota, nil
+}
+
+// GetResourceQuotaUsage returns the resource quota of an org with its projects and the
+// instances of each project by region
+func (s *Service) GetResourceQuotaUsage(orgID string) (*domain.ResourceQuotaUsage, error) {
+	quota, custom, err := s.resourceQuota(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectRepo.List(domain.ProjectListOptions{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &domain.ResourceQuotaUsage{
+		Quota:    quota,
+		Custom:   custom,
+		Projects: len(projects),
+		Usage:    []domain.ProjectInstanceUsage{},
+	}
+	for _, project := range projects {
+		projectUsage, err := s.projectInstanceUsage(project.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		projectUsage.ProjectSlug = project.Slug
+		usage.Usage = append(usage.Usage, *projectUsage)
+	}
+	return usage, nil
+}
+
+// projectInstanceUsage sums up the instances of a project, skipping the instance excludeID
+func (s *Service) projectInstanceUsage(projectID, excludeID string) (*domain.ProjectInstanceUsage, error) {
+	instances, err := s.instanceRepo.List(domain.InstanceListOptions{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &domain.ProjectInstanceUsage{
+		ProjectID: projectID,
+		Regions:   map[string]domain.RegionInstanceUsage{},
+	}
+	for _, instance := range instances {
+		if instance.ID == excludeID {
+			continue
+		}
+		usage.Instances++
+		region := usage.Regions[instance.Region]
+		region.Instances++
+		region.CPU += instance.CPU
+		region.MemoryMB += instance.MemoryMB
+		usage.Regions[instance.Region] = region
+	}
+	return usage, nil
+}
+
+// checkProjectCountQuota verifies that an org can create another project
+func (s *Service) checkProjectCountQuota(orgID string) error {
+	quota, _, err := s.resourceQuota(orgID)
+	if err != nil {
+		return err
+	}
+	if quota.MaxProjects == 0 {
+		return nil
+	}
+
+	projects, err := s.projectRepo.List(domain.ProjectListOptions{OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if len(projects)+1 > quota.MaxProjects {
+		return domain.QuotaExceededError("organization", domain.QuotaLimitMaxProjects, int64(quota.MaxProjects), int64(len(projects)+1))
+	}
+	return nil
+}
+
+// checkInstanceQuota verifies that a project has room for an instance with the given
+// resources in a region. For updates, existing is the instance being changed, whose
+// current resources are replaced rather than added to
+func (s *Service) checkInstanceQuota(projectID string, existing *domain.Instance, region string, cpu, memoryMB int) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+	quota, _, err := s.resourceQuota(project.OrgID)
+	if err != nil {
+		return err
+	}
+	if quota == (domain.ResourceQuota{}) {
+		return nil
+	}
+
+	var excludeID string
+	if existing != nil {
+		excludeID = existing.ID
+	}
+	usage, err := s.projectInstanceUsage(projectID, excludeID)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil && quota.MaxInstancesPerProject > 0 && usage.Instances+1 > quota.MaxInstancesPerProject {
+		return domain.QuotaExceededError("project", domain.QuotaLimitMaxInstancesPerProject, int64(quota.MaxInstancesPerProject), int64(usage.Instances+1))
+	}
+
+	// Only growth is checked, so that instances over a lowered quota can still shrink
+	inRegion := usage.Regions[region]
+	growsCPU := existing == nil || cpu > existing.CPU
+	if quota.MaxCPUPerRegion > 0 && growsCPU && inRegion.CPU+cpu > quota.MaxCPUPerRegion {
+		err := domain.QuotaExceededError("project", domain.QuotaLimitMaxCPUPerRegion, int64(quota.MaxCPUPerRegion), int64(inRegion.CPU+cpu))
+		err.Details["region"] = region
+		return err
+	}
+	growsMemory := existing == nil || memoryMB > existing.MemoryMB
+	if quota.MaxMemoryMBPerRegion > 0 && growsMemory && inRegion.MemoryMB+memoryMB > quota.MaxMemoryMBPerRegion {
+		err := domain.QuotaExceededError("project", domain.QuotaLimitMaxMemoryMBPerRegion, int64(quota.MaxMemoryMBPerRegion), int64(inRegion.MemoryMB+memoryMB))
+		err.Details["region"] = region
+		return err
+	}
+	return nil
+}
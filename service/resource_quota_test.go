@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceQuota_Instances(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	require.NoError(t, svc.SetDefaultResourceQuota(domain.ResourceQuota{MaxInstancesPerProject: 3, MaxCPUPerRegion: 4, MaxMemoryMBPerRegion: 4096}))
+
+	create := func(name, region string, cpu, memoryMB int) (*domain.Instance, error) {
+		return svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: region, CPU: cpu, MemoryMB: memoryMB, Image: "ubuntu-24.04"})
+	}
+
+	web, err := create("web-1", "us-east-1", 2, 2048)
+	require.NoError(t, err)
+	_, err = create("web-2", "us-east-1", 2, 1024)
+	require.NoError(t, err)
+
+	// The region is out of CPUs, other regions are not
+	_, err = create("web-3", "us-east-1", 1, 512)
+	require.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+	assert.Equal(t, domain.QuotaLimitMaxCPUPerRegion, err.(*domain.NahError).Details["limit"])
+	assert.Equal(t, "us-east-1", err.(*domain.NahError).Details["region"])
+	_, err = create("web-3", "eu-west-1", 1, 512)
+	require.NoError(t, err)
+
+	// Growing an instance counts its new size, shrinking is always allowed
+	cpu, memory := 3, 4096
+	_, err = svc.UpdateInstance(web.ID, domain.UpdateInstanceRequest{CPU: &cpu})
+	assert.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+	_, err = svc.UpdateInstance(web.ID, domain.UpdateInstanceRequest{MemoryMB: &memory})
+	assert.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+	cpu, memory = 1, 3072
+	_, err = svc.UpdateInstance(web.ID, domain.UpdateInstanceRequest{CPU: &cpu, MemoryMB: &memory})
+	require.NoError(t, err)
+
+	_, err = create("web-4", "eu-west-1", 1, 512)
+	require.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+	assert.Equal(t, domain.QuotaLimitMaxInstancesPerProject, err.(*domain.NahError).Details["limit"])
+
+	usage, err := svc.GetResourceQuotaUsage(project.OrgID)
+	require.NoError(t, err)
+	assert.False(t, usage.Custom)
+	assert.Equal(t, 1, usage.Projects)
+	require.Len(t, usage.Usage, 1)
+	assert.Equal(t, 3, usage.Usage[0].Instances)
+	assert.Equal(t, domain.RegionInstanceUsage{Instances: 2, CPU: 3, MemoryMB: 4096}, usage.Usage[0].Regions["us-east-1"])
+}
+
+func TestResourceQuota_ConcurrentCreates(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	require.NoError(t, svc.SetDefaultResourceQuota(domain.ResourceQuota{MaxProjects: 3, MaxInstancesPerProject: 3}))
+
+	// Runs n creates at once and returns how many succeeded
+	concurrently := func(n int, create func(i int) error) int {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		created := 0
+		start := make(chan struct{})
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				err := create(i)
+				if err != nil {
+					assert.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+					return
+				}
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		return created
+	}
+
+	created := concurrently(10, func(i int) error {
+		_, err := svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: fmt.Sprintf("web-%d", i), Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+		return err
+	})
+	assert.Equal(t, 3, created)
+	instances, err := svc.ListInstances(domain.InstanceListOptions{ProjectID: project.ID})
+	require.NoError(t, err)
+	assert.Len(t, instances, 3)
+
+	created = concurrently(10, func(i int) error {
+		_, err := svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: fmt.Sprintf("project-%d", i), Name: "Project"})
+		return err
+	})
+	assert.Equal(t, 2, created)
+	projects, err := svc.ListProjects(domain.ProjectListOptions{OrgID: project.OrgID})
+	require.NoError(t, err)
+	assert.Len(t, projects, 3)
+}
+
+func TestResourceQuota_OrgOverride(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	require.NoError(t, svc.SetDefaultResourceQuota(domain.ResourceQuota{MaxProjects: 1}))
+	_, err := svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "second", Name: "Second"})
+	require.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+
+	_, err = svc.PutResourceQuota(project.OrgID, domain.ResourceQuota{MaxProjects: 2})
+	require.NoError(t, err)
+	_, err = svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "second", Name: "Second"})
+	require.NoError(t, err)
+
+	usage, err := svc.GetResourceQuotaUsage(project.OrgID)
+	require.NoError(t, err)
+	assert.True(t, usage.Custom)
+	assert.Equal(t, 2, usage.Quota.MaxProjects)
+
+	quota, err := svc.ResetResourceQuota(project.OrgID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, quota.MaxProjects)
+	_, err = svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "third", Name: "Third"})
+	assert.True(t, domain.IsQuotaExceeded(err), "expected quota exceeded, got %v", err)
+
+	_, err = svc.PutResourceQuota(project.OrgID, domain.ResourceQuota{MaxCPUPerRegion: -1})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = svc.PutResourceQuota("missing", domain.ResourceQuota{})
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
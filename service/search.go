@@ -0,0 +1,87 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+const (
+	defaultSearchLimit  = 50
+	maxSearchLimit      = 200
+	maxSearchQueryBytes = 256
+)
+
+// SearchRepository defines the interface for searching and listing the resources of an org
+type SearchRepository interface {
+	Search(opts domain.SearchOptions) ([]*domain.SearchResult, error)
+	Recent(opts domain.SearchOptions) ([]*domain.RecentResource, error)
+}
+
+// searchKindScopes are the resource kinds that can be searched and the scope
+// an API key needs to see each of them
+var searchKindScopes = map[string]string{
+	domain.ResourceKindProject:  domain.ScopeProjectsRead,
+	domain.ResourceKindInstance: domain.ScopeInstancesRead,
+	domain.ResourceKindMetadata: domain.ScopeMetadataRead,
+	domain.ResourceKindBucket:   domain.ScopeBucketsRead,
+	domain.ResourceKindObject:   domain.ScopeObjectsRead,
+}
+
+// limitSearch limits search options to the resources an API key can read
+// Without a key (console sessions and admin requests) nothing is left out
+func limitSearch(opts *domain.SearchOptions, key *domain.APIKey) {
+	if key == nil {
+		return
+	}
+	opts.Kinds = []string{}
+	for kind, scope := range searchKindScopes {
+		if key.HasScope(scope) {
+			opts.Kinds = append(opts.Kinds, kind)
+		}
+	}
+	sort.Strings(opts.Kinds)
+	opts.ProjectIDs = key.ProjectIDs
+}
+
+// SetSearchRepository sets the repository used by Search
+func (s *Service) SetSearchRepository(repo SearchRepository) {
+	s.searchRepo = repo
+}
+
+// Search finds the projects, instances, buckets, objects and metadata of an org
+// whose name or path contains the query
+// With an API key, only the resources it can read are returned
+func (s *Service) Search(opts domain.SearchOptions, key *domain.APIKey) ([]*domain.SearchResult, error) {
+	if s.searchRepo == nil {
+		return nil, domain.InternalError("search is not configured")
+	}
+
+	opts.Query = strings.TrimSpace(opts.Query)
+	if opts.Query == "" {
+		return nil, domain.InvalidInputError("search query cannot be empty", nil)
+	}
+	if len(opts.Query) > maxSearchQueryBytes {
+		return nil, domain.InvalidInputError("search query too long", map[string]interface{}{
+			"max_length": maxSearchQueryBytes,
+		})
+	}
+	if _, ok := searchKindScopes[opts.Kind]; opts.Kind != "" && !ok {
+		return nil, domain.InvalidInputError("invalid kind", map[string]interface{}{
+			"kind": opts.Kind,
+		})
+	}
+	if opts.Limit < 0 {
+		return nil, domain.InvalidInputError("limit cannot be negative", nil)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultSearchLimit
+	}
+	if opts.Limit > maxSearchLimit {
+		opts.Limit = maxSearchLimit
+	}
+
+	limitSearch(&opts, key)
+	return s.searchRepo.Search(opts)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	_, err := svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: "web-42", Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	require.NoError(t, err)
+
+	results, err := svc.Search(domain.SearchOptions{OrgID: project.OrgID, Query: "  web-4  "}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, domain.ResourceKindInstance, results[0].Kind)
+	assert.Equal(t, "web-42", results[0].Name)
+	assert.Equal(t, "test-project", results[0].ProjectSlug)
+
+	// Other orgs see nothing
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	results, err = svc.Search(domain.SearchOptions{OrgID: other.ID, Query: "web-42"}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestSearch_APIKey(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+	other, err := svc.CreateProject(project.OrgID, domain.CreateProjectRequest{Slug: "other-project", Name: "Other Project"})
+	require.NoError(t, err)
+
+	// Hidden matches sort before the visible ones
+	for _, name := range []string{"web-a1", "web-a2", "web-a3"} {
+		_, err := svc.CreateBucket(project.ID, domain.CreateBucketRequest{Name: name})
+		require.NoError(t, err)
+	}
+	for _, p := range []*domain.Project{project, other} {
+		_, err := svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: p.ID, Name: "web-z-" + p.Slug, Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+		require.NoError(t, err)
+	}
+
+	names := func(key *domain.APIKey, limit int) []string {
+		results, err := svc.Search(domain.SearchOptions{OrgID: project.OrgID, Query: "web-", Limit: limit}, key)
+		require.NoError(t, err)
+		names := []string{}
+		for _, result := range results {
+			names = append(names, result.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"web-a1", "web-a2"}, names(nil, 2))
+	assert.Equal(t, []string{"web-z-other-project", "web-z-test-project"}, names(&domain.APIKey{Scopes: []string{domain.ScopeInstancesRead}}, 2))
+	assert.Equal(t, []string{"web-z-other-project"}, names(&domain.APIKey{Scopes: []string{domain.ScopeInstancesRead, domain.ScopeBucketsRead}, ProjectIDs: []string{other.ID}}, 2))
+	assert.Empty(t, names(&domain.APIKey{Scopes: []string{domain.ScopeOrgRead}}, 2))
+}
+
+func TestSearch_InvalidInput(t *testing.T) {
+	svc := setupTestService(t)
+
+	for name, opts := range map[string]domain.SearchOptions{
+		"empty query":    {OrgID: "org", Query: "   "},
+		"long query":     {OrgID: "org", Query: strings.Repeat("a", maxSearchQueryBytes+1)},
+		"unknown kind":   {OrgID: "org", Query: "web", Kind: "volume"},
+		"negative limit": {OrgID: "org", Query: "web", Limit: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Search(opts, nil)
+			assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+		})
+	}
+}
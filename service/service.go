@@ -5,9 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/hypertf/nahcloud/domain"
 	"github.com/hypertf/nahcloud/pkg/endec"
+	"github.com/hypertf/nahcloud/pkg/ratelimit"
 )
 
 // Service provides business logic for NahCloud operations
@@ -19,6 +22,34 @@ type Service struct {
 	metadataRepo MetadataRepository
 	bucketRepo   BucketRepository
 	objectRepo   ObjectRepository
+
+	searchRepo SearchRepository
+	oidcRepo   OIDCTrustPolicyRepository
+	oidc       OIDCVerifier
+	rateLimits domain.RateLimits
+	limiter    *ratelimit.Limiter
+
+	signingSecret []byte
+	events        *eventBus
+
+	adminTokenHash      string
+	orgCreationDisabled bool
+
+	defaultResourceQuota domain.ResourceQuota
+	instanceTypes        []domain.InstanceType
+	imageRepo            ImageRepository
+
+	regionsMu sync.RWMutex
+	regions   []domain.Region
+
+	networkRepo NetworkRepository
+	subnetRepo  SubnetRepository
+	ipMu        sync.Mutex // serializes private IP allocation with subnet and network changes
+	quotaMu     sync.Mutex // serializes resource quota checks with the writes they allow
+	storageMu   sync.Mutex // serializes storage quota checks with the object writes they allow
+
+	firewallRuleRepo FirewallRuleRepository
+	volumeRepo       VolumeRepository
 }
 
 // OrganizationRepository defines the interface for organization data operations
@@ -29,6 +60,8 @@ type OrganizationRepository interface {
 	List(opts domain.OrganizationListOptions) ([]*domain.Organization, error)
 	Update(id string, req domain.UpdateOrganizationRequest) (*domain.Organization, error)
 	Delete(id string) error
+	GetResourceQuota(id string) (*domain.ResourceQuota, error)
+	UpdateResourceQuota(id string, quota *domain.ResourceQuota) error
 }
 
 // APIKeyRepository defines the interface for API key data operations
@@ -39,6 +72,9 @@ type APIKeyRepository interface {
 	ListByOrgID(orgID string) ([]*domain.APIKey, error)
 	UpdateLastUsed(id string) error
 	Delete(id string) error
+	DeleteByOrgID(orgID string) error
+	Rotate(id, tokenHash string, previousExpiresAt time.Time) (*domain.APIKey, error)
+	DeleteExpired(orgID, source string, now time.Time) error
 }
 
 // ProjectRepository defines the interface for project data operations
@@ -49,6 +85,8 @@ type ProjectRepository interface {
 	GetByName(name string) (*domain.Project, error)
 	List(opts domain.ProjectListOptions) ([]*domain.Project, error)
 	Update(id string, req domain.UpdateProjectRequest) (*domain.Project, error)
+	GetStorageQuota(id string) (*domain.StorageQuota, error)
+	UpdateStorageQuota(id string, quota domain.StorageQuota) error
 	Delete(id string) error
 }
 
@@ -78,6 +116,14 @@ type BucketRepository interface {
 	GetByName(projectID, name string) (*domain.Bucket, error)
 	List(opts domain.BucketListOptions) ([]*domain.Bucket, error)
 	Update(id string, req domain.UpdateBucketRequest) (*domain.Bucket, error)
+	Rename(id, name string) (*domain.Bucket, error)
+	UpdateLifecycle(id string, rules []domain.LifecycleRule) (*domain.Bucket, error)
+	UpdateQuota(id string, quota domain.StorageQuota) (*domain.Bucket, error)
+	UpdateNotifications(id string, notifications []domain.Notification) (*domain.Bucket, error)
+	SaveDelivery(d *domain.NotificationDelivery) error
+	ListDeliveries(bucketID string, limit int) ([]*domain.NotificationDelivery, error)
+	GetUsage(id string) (*domain.StorageUsage, error)
+	ListUsage(projectID string) ([]domain.BucketUsage, error)
 	Delete(id string) error
 }
 
@@ -85,9 +131,13 @@ type BucketRepository interface {
 type ObjectRepository interface {
 	Create(req domain.CreateObjectRequest) (*domain.Object, error)
 	GetByID(id string) (*domain.Object, error)
+	GetByPath(bucketID, path string) (*domain.Object, error)
 	Update(id string, req domain.UpdateObjectRequest) (*domain.Object, error)
+	Move(id, bucketID, path string, overwrite bool) (*domain.Object, error)
 	List(opts domain.ObjectListOptions) ([]*domain.Object, error)
 	Delete(id string) error
+	NoncurrentSize(id string) (int64, error)
+	DeleteNoncurrentVersions(bucketID, prefix string, archivedBefore time.Time) (int, error)
 }
 
 // NewService creates a new service instance
@@ -100,6 +150,8 @@ func NewService(orgRepo OrganizationRepository, apiKeyRepo APIKeyRepository, pro
 		metadataRepo: metadataRepo,
 		bucketRepo:   bucketRepo,
 		objectRepo:   objectRepo,
+		events:       newEventBus(),
+		regions:      cloneRegions(domain.DefaultRegions),
 	}
 }
 
@@ -198,8 +250,40 @@ func validateInstanceName(name string) error {
 	return nil
 }
 
+// resourceNamePattern is what the names of networks, subnets and the like may contain
+var resourceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validateResourceName validates the name of a resource that, like an instance, may only
+// contain alphanumeric characters, dashes and underscores
+func validateResourceName(name string, resourceType string) error {
+	if err := validateName(name, resourceType); err != nil {
+		return err
+	}
+	if !resourceNamePattern.MatchString(name) {
+		return domain.InvalidInputError(resourceType+" name can only contain alphanumeric characters, dashes, and underscores", nil)
+	}
+	return nil
+}
+
 // validateInstanceSpecs validates instance specifications
 func validateInstanceSpecs(cpu int, memoryMB int, image string) error {
+	if err := validateInstanceSize(cpu, memoryMB); err != nil {
+		return err
+	}
+	if image == "" {
+		return domain.InvalidInputError("image cannot be empty", nil)
+	}
+	if len(image) > 255 {
+		return domain.InvalidInputError("image name too long", map[string]interface{}{
+			"max_length": 255,
+			"actual":     len(image),
+		})
+	}
+	return nil
+}
+
+// validateInstanceSize validates the CPU and memory of an instance or instance type
+func validateInstanceSize(cpu int, memoryMB int) error {
 	if cpu <= 0 {
 		return domain.InvalidInputError("CPU must be positive", map[string]interface{}{"cpu": cpu})
 	}
@@ -218,15 +302,6 @@ func validateInstanceSpecs(cpu int, memoryMB int, image string) error {
 			"actual":        memoryMB,
 		})
 	}
-	if image == "" {
-		return domain.InvalidInputError("image cannot be empty", nil)
-	}
-	if len(image) > 255 {
-		return domain.InvalidInputError("image name too long", map[string]interface{}{
-			"max_length": 255,
-			"actual":     len(image),
-		})
-	}
 	return nil
 }
 
@@ -241,22 +316,6 @@ func validateInstanceStatus(status string) error {
 	return nil
 }
 
-// validateInstanceRegion validates instance region
-func validateInstanceRegion(region string) error {
-	if region == "" {
-		return domain.InvalidInputError("region is required", nil)
-	}
-	for _, validRegion := range domain.ValidRegions {
-		if region == validRegion {
-			return nil
-		}
-	}
-	return domain.InvalidInputError("invalid region", map[string]interface{}{
-		"valid_regions": domain.ValidRegions,
-		"actual":        region,
-	})
-}
-
 // validateObjectPath validates an object path
 func validateObjectPath(path string) error {
 	if path == "" {
@@ -301,7 +360,7 @@ func (s *Service) CreateOrganization(req domain.CreateOrganizationRequest) (*dom
 	}
 
 	// Create initial API key for the org
-	apiKeyWithToken, err := s.createAPIKey(orgID, "default")
+	apiKeyWithToken, err := s.createAPIKey(&domain.APIKey{OrgID: orgID, Name: "default"})
 	if err != nil {
 		// Rollback org creation on API key failure
 		s.orgRepo.Delete(orgID)
@@ -326,12 +385,38 @@ func (s *Service) GetOrganizationBySlug(slug string) (*domain.Organization, erro
 
 // GetOrganizationByToken retrieves an organization by validating the provided API key token
 func (s *Service) GetOrganizationByToken(token string) (*domain.Organization, error) {
+	_, org, err := s.AuthenticateAPIKey(token)
+	return org, err
+}
+
+// AuthenticateAPIKey validates an API key token and returns the key and its organization
+// Unknown and expired keys are rejected as unauthorized
+func (s *Service) AuthenticateAPIKey(token string) (*domain.APIKey, *domain.Organization, error) {
+	apiKey, err := s.lookupAPIKey(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Update last used timestamp (fire and forget)
+	go s.apiKeyRepo.UpdateLastUsed(apiKey.ID)
+
+	org, err := s.orgRepo.GetByID(apiKey.OrgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return apiKey, org, nil
+}
+
+// lookupAPIKey finds the API key of a token, which is either the key's current token or
+// its previous token during a rotation's overlap
+func (s *Service) lookupAPIKey(token string) (*domain.APIKey, error) {
 	// Validate token format (must be an API key)
 	if _, err := endec.ValidateToken(token, endec.PrefixAPI); err != nil {
 		return nil, domain.UnauthorizedError("invalid token format")
 	}
 
-	apiKey, err := s.apiKeyRepo.GetByTokenHash(hashToken(token))
+	tokenHash := hashToken(token)
+	apiKey, err := s.apiKeyRepo.GetByTokenHash(tokenHash)
 	if err != nil {
 		if domain.IsNotFound(err) {
 			return nil, domain.UnauthorizedError("invalid token")
@@ -339,10 +424,14 @@ func (s *Service) GetOrganizationByToken(token string) (*domain.Organization, er
 		return nil, err
 	}
 
-	// Update last used timestamp (fire and forget)
-	go s.apiKeyRepo.UpdateLastUsed(apiKey.ID)
-
-	return s.orgRepo.GetByID(apiKey.OrgID)
+	now := time.Now()
+	if apiKey.TokenHash != tokenHash && (apiKey.PreviousTokenExpiresAt == nil || !now.Before(*apiKey.PreviousTokenExpiresAt)) {
+		return nil, domain.UnauthorizedError("token has been rotated")
+	}
+	if apiKey.Expired(now) {
+		return nil, domain.UnauthorizedError("API key has expired")
+	}
+	return apiKey, nil
 }
 
 // ListOrganizations lists organizations with optional filtering
@@ -369,7 +458,8 @@ func (s *Service) DeleteOrganization(id string) error {
 // API Key operations
 
 // createAPIKey is an internal helper to create an API key
-func (s *Service) createAPIKey(orgID, name string) (*domain.APIKeyWithToken, error) {
+// It generates the ID and token of apiKey and stores it
+func (s *Service) createAPIKey(apiKey *domain.APIKey) (*domain.APIKeyWithToken, error) {
 	keyID, err := generateID()
 	if err != nil {
 		return nil, domain.InternalError("failed to generate API key ID")
@@ -381,12 +471,8 @@ func (s *Service) createAPIKey(orgID, name string) (*domain.APIKeyWithToken, err
 		return nil, domain.InternalError("failed to generate token")
 	}
 
-	apiKey := &domain.APIKey{
-		ID:        keyID,
-		OrgID:     orgID,
-		Name:      name,
-		TokenHash: hashToken(token),
-	}
+	apiKey.ID = keyID
+	apiKey.TokenHash = hashToken(token)
 
 	if err := s.apiKeyRepo.Create(apiKey); err != nil {
 		return nil, err
@@ -399,7 +485,9 @@ func (s *Service) createAPIKey(orgID, name string) (*domain.APIKeyWithToken, err
 }
 
 // CreateAPIKey creates a new API key for an organization
-func (s *Service) CreateAPIKey(orgID string, req domain.CreateAPIKeyRequest) (*domain.APIKeyWithToken, error) {
+// creator is the key making the request (nil for callers with full access); it can only
+// create keys with at most its own scopes, projects and lifetime
+func (s *Service) CreateAPIKey(orgID string, req domain.CreateAPIKeyRequest, creator *domain.APIKey) (*domain.APIKeyWithToken, error) {
 	// Verify organization exists
 	if _, err := s.orgRepo.GetByID(orgID); err != nil {
 		return nil, err
@@ -410,7 +498,130 @@ func (s *Service) CreateAPIKey(orgID string, req domain.CreateAPIKeyRequest) (*d
 		name = "unnamed"
 	}
 
-	return s.createAPIKey(orgID, name)
+	apiKey, err := s.apiKeyGrant(orgID, req.Scopes, req.Projects)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Name = name
+
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC().Truncate(time.Second)
+		if !expiresAt.After(time.Now()) {
+			return nil, domain.InvalidInputError("expires_at must be in the future", nil)
+		}
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if creator != nil && !creator.CanGrant(apiKey) {
+		return nil, domain.ForbiddenError("API key cannot create a key with more access than its own", nil)
+	}
+
+	return s.createAPIKey(apiKey)
+}
+
+// apiKeyGrant validates scopes and resolves projects (by slug or ID) into a key of the org
+// with that access, dropping duplicates
+func (s *Service) apiKeyGrant(orgID string, scopes, projects []string) (*domain.APIKey, error) {
+	apiKey := &domain.APIKey{OrgID: orgID}
+
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if !domain.IsValidScope(scope) {
+			return nil, domain.InvalidInputError("invalid scope", map[string]interface{}{
+				"scope":  scope,
+				"scopes": domain.Scopes,
+			})
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			apiKey.Scopes = append(apiKey.Scopes, scope)
+		}
+	}
+
+	seen = map[string]bool{}
+	for _, ref := range projects {
+		project, err := s.resolveAPIKeyProject(orgID, ref)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[project.ID] {
+			seen[project.ID] = true
+			apiKey.ProjectIDs = append(apiKey.ProjectIDs, project.ID)
+		}
+	}
+
+	return apiKey, nil
+}
+
+// resolveAPIKeyProject finds a project of the org by slug or ID
+func (s *Service) resolveAPIKeyProject(orgID, ref string) (*domain.Project, error) {
+	project, err := s.projectRepo.GetBySlug(orgID, ref)
+	if err == nil || !domain.IsNotFound(err) {
+		return project, err
+	}
+	project, err = s.projectRepo.GetByID(ref)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil, domain.ForeignKeyViolationError("project", "slug", ref)
+		}
+		return nil, err
+	}
+	if project.OrgID != orgID {
+		return nil, domain.ForeignKeyViolationError("project", "slug", ref)
+	}
+	return project, nil
+}
+
+// GetAPIKey retrieves an API key of an organization
+func (s *Service) GetAPIKey(orgID, keyID string) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.OrgID != orgID {
+		return nil, domain.NotFoundError("api_key", keyID)
+	}
+	return key, nil
+}
+
+// RotateAPIKey issues a new token for an API key
+// The previous token stays valid for the requested overlap so that clients can switch
+// over; creator is the key making the request, as for CreateAPIKey
+func (s *Service) RotateAPIKey(orgID, keyID string, req domain.RotateAPIKeyRequest, creator *domain.APIKey) (*domain.APIKeyWithToken, error) {
+	overlap := domain.DefaultRotationOverlap
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+		if overlap < 0 || overlap > domain.MaxRotationOverlap {
+			return nil, domain.InvalidInputError("overlap_seconds out of range", map[string]interface{}{
+				"min":    0,
+				"max":    int(domain.MaxRotationOverlap.Seconds()),
+				"actual": *req.OverlapSeconds,
+			})
+		}
+	}
+
+	key, err := s.GetAPIKey(orgID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if creator != nil && !creator.CanGrant(key) {
+		return nil, domain.ForbiddenError("API key cannot rotate a key with more access than its own", nil)
+	}
+
+	token, err := endec.CreateToken(endec.PrefixAPI, 24)
+	if err != nil {
+		return nil, domain.InternalError("failed to generate token")
+	}
+
+	key, err = s.apiKeyRepo.Rotate(keyID, hashToken(token), time.Now().Add(overlap))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.APIKeyWithToken{
+		APIKey: *key,
+		Token:  token,
+	}, nil
 }
 
 // ListAPIKeys lists all API keys for an organization
@@ -419,14 +630,15 @@ func (s *Service) ListAPIKeys(orgID string) ([]*domain.APIKey, error) {
 }
 
 // DeleteAPIKey deletes an API key
-func (s *Service) DeleteAPIKey(orgID, keyID string) error {
+// creator is the key making the request, as for CreateAPIKey
+func (s *Service) DeleteAPIKey(orgID, keyID string, creator *domain.APIKey) error {
 	// Verify the key belongs to the org
-	key, err := s.apiKeyRepo.GetByID(keyID)
+	key, err := s.GetAPIKey(orgID, keyID)
 	if err != nil {
 		return err
 	}
-	if key.OrgID != orgID {
-		return domain.NotFoundError("api_key", keyID)
+	if creator != nil && !creator.CanGrant(key) {
+		return domain.ForbiddenError("API key cannot delete a key with more access than its own", nil)
 	}
 
 	return s.apiKeyRepo.Delete(keyID)
@@ -452,6 +664,13 @@ func (s *Service) CreateProject(orgID string, req domain.CreateProjectRequest) (
 		return nil, err
 	}
 
+	// Held until the project is stored, so concurrent creates cannot both pass the check
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	if err := s.checkProjectCountQuota(orgID); err != nil {
+		return nil, err
+	}
+
 	id, err := generateID()
 	if err != nil {
 		return nil, domain.InternalError("failed to generate ID")
@@ -468,6 +687,7 @@ func (s *Service) CreateProject(orgID string, req domain.CreateProjectRequest) (
 		return nil, err
 	}
 
+	s.publishEvent(domain.ResourceKindProject, domain.ResourceActionCreated, orgID, project.ID)
 	return project, nil
 }
 
@@ -494,12 +714,25 @@ func (s *Service) UpdateProject(id string, req domain.UpdateProjectRequest) (*do
 		}
 	}
 
-	return s.projectRepo.Update(id, req)
+	project, err := s.projectRepo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.publishEvent(domain.ResourceKindProject, domain.ResourceActionUpdated, project.OrgID, project.ID)
+	return project, nil
 }
 
 // DeleteProject deletes a project
 func (s *Service) DeleteProject(id string) error {
-	return s.projectRepo.Delete(id)
+	project, err := s.projectRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.projectRepo.Delete(id); err != nil {
+		return err
+	}
+	s.publishEvent(domain.ResourceKindProject, domain.ResourceActionDeleted, project.OrgID, id)
+	return nil
 }
 
 // Instance operations
@@ -510,9 +743,27 @@ func (s *Service) CreateInstance(req domain.CreateInstanceRequest) (*domain.Inst
 		return nil, err
 	}
 
-	if err := validateInstanceRegion(req.Region); err != nil {
+	region, err := s.region(req.Region)
+	if err != nil {
 		return nil, err
 	}
+	if req.Zone != "" && !region.HasZone(req.Zone) {
+		return nil, domain.InvalidInputError("invalid zone for region", map[string]interface{}{
+			"region":      region.Name,
+			"valid_zones": region.Zones,
+			"actual":      req.Zone,
+		})
+	}
+
+	if req.InstanceType != "" {
+		instanceType, err := s.instanceType(req.InstanceType, req.Region)
+		if err != nil {
+			return nil, err
+		}
+		if req.CPU, req.MemoryMB, err = instanceTypeSize(instanceType, req.CPU, req.MemoryMB); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := validateInstanceSpecs(req.CPU, req.MemoryMB, req.Image); err != nil {
 		return nil, err
@@ -527,7 +778,7 @@ func (s *Service) CreateInstance(req domain.CreateInstanceRequest) (*domain.Inst
 	}
 
 	// Verify project exists
-	_, err := s.projectRepo.GetByID(req.ProjectID)
+	project, err := s.projectRepo.GetByID(req.ProjectID)
 	if err != nil {
 		if domain.IsNotFound(err) {
 			return nil, domain.ForeignKeyViolationError("project", "id", req.ProjectID)
@@ -535,37 +786,96 @@ func (s *Service) CreateInstance(req domain.CreateInstanceRequest) (*domain.Inst
 		return nil, err
 	}
 
+	image, err := s.resolveImage(project.OrgID, req.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	var subnet *domain.Subnet
+	if req.SubnetID != "" {
+		// Held until the instance is stored, so its subnet cannot be deleted in between
+		s.ipMu.Lock()
+		defer s.ipMu.Unlock()
+		if subnet, err = s.instanceSubnet(req.ProjectID, req.SubnetID, req.Region); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validateInstanceFirewallRules(req.ProjectID, req.FirewallRuleIDs); err != nil {
+		return nil, err
+	}
+
+	// Checked after validation, so invalid requests fail the same way whether the region is up or not
+	if region.Down {
+		return nil, domain.ServiceUnavailableError("region " + region.Name + " is down")
+	}
+
+	// Held until the instance is stored, so concurrent creates cannot both pass the check
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	if err := s.checkInstanceQuota(req.ProjectID, nil, req.Region, req.CPU, req.MemoryMB); err != nil {
+		return nil, err
+	}
+
 	id, err := generateID()
 	if err != nil {
 		return nil, domain.InternalError("failed to generate ID")
 	}
 
 	instance := &domain.Instance{
-		ID:        id,
-		ProjectID: req.ProjectID,
-		Name:      req.Name,
-		Region:    req.Region,
-		CPU:       req.CPU,
-		MemoryMB:  req.MemoryMB,
-		Image:     req.Image,
-		Status:    status,
+		ID:           id,
+		ProjectID:    req.ProjectID,
+		Name:         req.Name,
+		Region:       req.Region,
+		Zone:         req.Zone,
+		InstanceType: req.InstanceType,
+		CPU:          req.CPU,
+		MemoryMB:     req.MemoryMB,
+		Image:        image.Name,
+		Status:       status,
+	}
+	if len(req.FirewallRuleIDs) > 0 {
+		instance.FirewallRuleIDs = req.FirewallRuleIDs
+	}
+
+	if subnet != nil {
+		if instance.PrivateIP, err = s.allocateIP(subnet); err != nil {
+			return nil, err
+		}
+		instance.SubnetID = subnet.ID
 	}
 
 	if err := s.instanceRepo.Create(instance); err != nil {
 		return nil, err
 	}
 
+	instance.Volumes = []string{}
+
+	s.publishProjectEvent(domain.ResourceKindInstance, domain.ResourceActionCreated, instance.ProjectID, "", instance.ID)
 	return instance, nil
 }
 
 // GetInstance retrieves an instance by ID
 func (s *Service) GetInstance(id string) (*domain.Instance, error) {
-	return s.instanceRepo.GetByID(id)
+	instance, err := s.instanceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setInstanceVolumes(instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
 }
 
 // ListInstances lists instances with optional filtering
 func (s *Service) ListInstances(opts domain.InstanceListOptions) ([]*domain.Instance, error) {
-	return s.instanceRepo.List(opts)
+	instances, err := s.instanceRepo.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setInstanceVolumes(instances...); err != nil {
+		return nil, err
+	}
+	return instances, nil
 }
 
 // UpdateInstance updates an existing instance
@@ -576,6 +886,11 @@ func (s *Service) UpdateInstance(id string, req domain.UpdateInstanceRequest) (*
 		return nil, err
 	}
 
+	// An instance created from a family still matches it after newer images are released
+	if req.Image != nil && *req.Image != current.Image && *req.Image == s.imageFamily(current) {
+		req.Image = &current.Image
+	}
+
 	// Image changes require instance recreation - only error if actually changing
 	if req.Image != nil && *req.Image != current.Image {
 		return nil, domain.InvalidInputError(
@@ -595,6 +910,31 @@ func (s *Service) UpdateInstance(id string, req domain.UpdateInstanceRequest) (*
 		}
 	}
 
+	// The size of an instance with a type comes from its type
+	if req.InstanceType != nil && *req.InstanceType != "" {
+		instanceType, err := s.instanceType(*req.InstanceType, current.Region)
+		if err != nil {
+			return nil, err
+		}
+		var cpu, memory int
+		if req.CPU != nil {
+			cpu = *req.CPU
+		}
+		if req.MemoryMB != nil {
+			memory = *req.MemoryMB
+		}
+		if cpu, memory, err = instanceTypeSize(instanceType, cpu, memory); err != nil {
+			return nil, err
+		}
+		req.CPU, req.MemoryMB = &cpu, &memory
+	} else if req.InstanceType == nil && current.InstanceType != "" {
+		if (req.CPU != nil && *req.CPU != current.CPU) || (req.MemoryMB != nil && *req.MemoryMB != current.MemoryMB) {
+			return nil, domain.InvalidInputError("cpu and memory_mb are set by the instance type; change instance_type, or set it to \"\" for a custom size", map[string]interface{}{
+				"instance_type": current.InstanceType,
+			})
+		}
+	}
+
 	if req.CPU != nil || req.MemoryMB != nil {
 		// Validate complete specs using current values as defaults
 		cpu := current.CPU
@@ -611,6 +951,11 @@ func (s *Service) UpdateInstance(id string, req domain.UpdateInstanceRequest) (*
 		if err := validateInstanceSpecs(cpu, memory, image); err != nil {
 			return nil, err
 		}
+		s.quotaMu.Lock()
+		defer s.quotaMu.Unlock()
+		if err := s.checkInstanceQuota(current.ProjectID, current, current.Region, cpu, memory); err != nil {
+			return nil, err
+		}
 	}
 
 	if req.Status != nil {
@@ -619,12 +964,40 @@ func (s *Service) UpdateInstance(id string, req domain.UpdateInstanceRequest) (*
 		}
 	}
 
-	return s.instanceRepo.Update(id, req)
+	if req.FirewallRuleIDs != nil {
+		if err := s.validateInstanceFirewallRules(current.ProjectID, *req.FirewallRuleIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	instance, err := s.instanceRepo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setInstanceVolumes(instance); err != nil {
+		return nil, err
+	}
+	s.publishProjectEvent(domain.ResourceKindInstance, domain.ResourceActionUpdated, instance.ProjectID, "", instance.ID)
+	return instance, nil
 }
 
 // DeleteInstance deletes an instance
 func (s *Service) DeleteInstance(id string) error {
-	return s.instanceRepo.Delete(id)
+	instance, err := s.instanceRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.instanceRepo.Delete(id); err != nil {
+		return err
+	}
+	// Volumes outlive their instance
+	if s.volumeRepo != nil {
+		if err := s.volumeRepo.DetachAll(id); err != nil {
+			return err
+		}
+	}
+	s.publishProjectEvent(domain.ResourceKindInstance, domain.ResourceActionDeleted, instance.ProjectID, "", id)
+	return nil
 }
 
 // Metadata operations
@@ -647,7 +1020,12 @@ func (s *Service) CreateMetadata(req domain.CreateMetadataRequest) (*domain.Meta
 		return nil, err
 	}
 
-	return s.metadataRepo.Create(req)
+	metadata, err := s.metadataRepo.Create(req)
+	if err != nil {
+		return nil, err
+	}
+	s.publishEvent(domain.ResourceKindMetadata, domain.ResourceActionCreated, metadata.OrgID, metadata.ID)
+	return metadata, nil
 }
 
 // GetMetadata retrieves metadata by ID
@@ -677,7 +1055,12 @@ func (s *Service) UpdateMetadata(id string, req domain.UpdateMetadataRequest) (*
 		return nil, domain.InvalidInputError("metadata ID cannot be empty", nil)
 	}
 
-	return s.metadataRepo.Update(id, req)
+	metadata, err := s.metadataRepo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.publishEvent(domain.ResourceKindMetadata, domain.ResourceActionUpdated, metadata.OrgID, metadata.ID)
+	return metadata, nil
 }
 
 // ListMetadata lists metadata with optional prefix filtering
@@ -691,7 +1074,15 @@ func (s *Service) DeleteMetadata(id string) error {
 		return domain.InvalidInputError("metadata ID cannot be empty", nil)
 	}
 
-	return s.metadataRepo.Delete(id)
+	metadata, err := s.metadataRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.metadataRepo.Delete(id); err != nil {
+		return err
+	}
+	s.publishEvent(domain.ResourceKindMetadata, domain.ResourceActionDeleted, metadata.OrgID, id)
+	return nil
 }
 
 // Bucket operations
@@ -716,6 +1107,7 @@ func (s *Service) CreateBucket(projectID string, req domain.CreateBucketRequest)
 	if err := s.bucketRepo.Create(b); err != nil {
 		return nil, err
 	}
+	s.publishProjectEvent(domain.ResourceKindBucket, domain.ResourceActionCreated, projectID, b.ID, b.ID)
 	return b, nil
 }
 
@@ -760,9 +1152,40 @@ func (s *Service) UpdateBucket(id string, req domain.UpdateBucketRequest) (*doma
 	return current, nil
 }
 
+// RenameBucket changes a bucket's name (and so its ID), keeping its objects and settings.
+// Unlike UpdateBucket this is not exposed to API clients, which treat the name as immutable.
+func (s *Service) RenameBucket(id, name string) (*domain.Bucket, error) {
+	if err := validateBucketName(name); err != nil {
+		return nil, err
+	}
+	current, err := s.bucketRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name == current.Name {
+		return current, nil
+	}
+	bucket, err := s.bucketRepo.Rename(id, name)
+	if err != nil {
+		return nil, err
+	}
+	// The ID changes with the name, so watchers see the old bucket go and the new one appear
+	s.publishProjectEvent(domain.ResourceKindBucket, domain.ResourceActionDeleted, current.ProjectID, current.ID, current.ID)
+	s.publishProjectEvent(domain.ResourceKindBucket, domain.ResourceActionCreated, bucket.ProjectID, bucket.ID, bucket.ID)
+	return bucket, nil
+}
+
 // DeleteBucket deletes a bucket
 func (s *Service) DeleteBucket(id string) error {
-	return s.bucketRepo.Delete(id)
+	bucket, err := s.bucketRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.bucketRepo.Delete(id); err != nil {
+		return err
+	}
+	s.publishProjectEvent(domain.ResourceKindBucket, domain.ResourceActionDeleted, bucket.ProjectID, id, id)
+	return nil
 }
 
 // Object operations
@@ -779,13 +1202,25 @@ func (s *Service) CreateObject(req domain.CreateObjectRequest) (*domain.Object,
 		return nil, domain.InvalidInputError("content cannot be empty", nil)
 	}
 	// Verify bucket exists
-	if _, err := s.bucketRepo.GetByID(req.BucketID); err != nil {
+	bucket, err := s.bucketRepo.GetByID(req.BucketID)
+	if err != nil {
 		if domain.IsNotFound(err) {
 			return nil, domain.ForeignKeyViolationError("bucket", "id", req.BucketID)
 		}
 		return nil, err
 	}
-	return s.objectRepo.Create(req)
+	// Held until the object is stored, so concurrent uploads cannot both pass the check
+	s.storageMu.Lock()
+	defer s.storageMu.Unlock()
+	if err := s.checkStorageQuota(bucket, domain.ContentSize(req.Content), 1); err != nil {
+		return nil, err
+	}
+	obj, err := s.objectRepo.Create(req)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyObjectEvent(bucket, obj, domain.ObjectEventCreated)
+	return obj, nil
 }
 
 // GetObject retrieves an object by ID
@@ -805,10 +1240,44 @@ func (s *Service) UpdateObject(id string, req domain.UpdateObjectRequest) (*doma
 			return nil, err
 		}
 	}
-	return s.objectRepo.Update(id, req)
+	if req.Content != nil {
+		// Taken before the size being replaced is read, so it cannot change under the check
+		s.storageMu.Lock()
+		defer s.storageMu.Unlock()
+	}
+	existing, err := s.objectRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := s.bucketRepo.GetByID(existing.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	// New content adds to usage: the replaced content is kept as a noncurrent version
+	if req.Content != nil && *req.Content != existing.Content {
+		if err := s.checkStorageQuota(bucket, domain.ContentSize(*req.Content), 0); err != nil {
+			return nil, err
+		}
+	}
+	obj, err := s.objectRepo.Update(id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyObjectEvent(bucket, obj, domain.ObjectEventUpdated)
+	return obj, nil
 }
 
 // DeleteObject deletes an object
 func (s *Service) DeleteObject(id string) error {
-	return s.objectRepo.Delete(id)
+	obj, err := s.objectRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.objectRepo.Delete(id); err != nil {
+		return err
+	}
+	if bucket, err := s.bucketRepo.GetByID(obj.BucketID); err == nil {
+		s.notifyObjectEvent(bucket, obj, domain.ObjectEventDeleted)
+	}
+	return nil
 }
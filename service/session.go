@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+)
+
+// sessionTokenClaims is the signed payload of a session token
+type sessionTokenClaims struct {
+	OrgID      string   `json:"o"`
+	KeyID      string   `json:"k"`
+	Scopes     []string `json:"s,omitempty"`
+	ProjectIDs []string `json:"p,omitempty"`
+	ExpiresAt  int64    `json:"e"`
+}
+
+// CreateSessionToken mints a session token from the API key a request authenticated with
+// The token has at most the key's access and expires no later than the key
+func (s *Service) CreateSessionToken(orgID string, req domain.CreateSessionTokenRequest, key *domain.APIKey) (*domain.SessionToken, error) {
+	if key == nil || key.OrgID != orgID {
+		return nil, domain.ForbiddenError("session tokens can only be minted with an API key of the organization", nil)
+	}
+
+	ttl := domain.DefaultSessionTokenTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+		if ttl < domain.MinSessionTokenTTL || ttl > domain.MaxSessionTokenTTL {
+			return nil, domain.InvalidInputError("ttl_seconds out of range", map[string]interface{}{
+				"min":    int(domain.MinSessionTokenTTL.Seconds()),
+				"max":    int(domain.MaxSessionTokenTTL.Seconds()),
+				"actual": req.TTLSeconds,
+			})
+		}
+	}
+
+	grant, err := s.apiKeyGrant(orgID, req.Scopes, req.Projects)
+	if err != nil {
+		return nil, err
+	}
+	if len(grant.Scopes) == 0 {
+		grant.Scopes = key.Scopes
+	}
+	if len(grant.ProjectIDs) == 0 {
+		grant.ProjectIDs = key.ProjectIDs
+	}
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(expiresAt) {
+		expiresAt = key.ExpiresAt.UTC().Truncate(time.Second)
+	}
+	grant.ExpiresAt = &expiresAt
+	if !key.CanGrant(grant) {
+		return nil, domain.ForbiddenError("API key cannot mint a session token with more access than its own", nil)
+	}
+
+	token, err := s.signToken(endec.PrefixSession, sessionTokenClaims{
+		OrgID:      orgID,
+		KeyID:      key.ID,
+		Scopes:     grant.Scopes,
+		ProjectIDs: grant.ProjectIDs,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SessionToken{
+		Token:      token,
+		OrgID:      orgID,
+		APIKeyID:   key.ID,
+		Scopes:     grant.Scopes,
+		ProjectIDs: grant.ProjectIDs,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// AuthenticateSessionToken verifies a session token and returns the access it carries as an
+// API key with the ID of the key it was minted from. Only the signature and expiry are
+// checked, so the token stays valid until it expires even if its key is deleted or rotated
+func (s *Service) AuthenticateSessionToken(token string) (*domain.APIKey, error) {
+	var claims sessionTokenClaims
+	if err := s.verifyToken(token, endec.PrefixSession, &claims); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+	if !time.Now().Before(expiresAt) {
+		return nil, domain.UnauthorizedError("session token has expired")
+	}
+
+	return &domain.APIKey{
+		ID:         claims.KeyID,
+		OrgID:      claims.OrgID,
+		Scopes:     claims.Scopes,
+		ProjectIDs: claims.ProjectIDs,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// IsSessionToken reports whether a token is a session token rather than an API key
+func IsSessionToken(token string) bool {
+	return strings.HasPrefix(token, "nah_"+endec.PrefixSession+"_")
+}
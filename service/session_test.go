@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionToken(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	project := createTestProject(t, svc)
+
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{
+		Name:   "ci",
+		Scopes: []string{domain.ScopeInstancesWrite, domain.ScopeTFStateRead},
+	}, nil)
+	require.NoError(t, err)
+
+	session, err := svc.CreateSessionToken(project.OrgID, domain.CreateSessionTokenRequest{
+		Scopes:   []string{domain.ScopeInstancesRead},
+		Projects: []string{"test-project"},
+	}, &key.APIKey)
+	require.NoError(t, err)
+	assert.True(t, IsSessionToken(session.Token))
+	assert.Equal(t, key.ID, session.APIKeyID)
+	assert.WithinDuration(t, time.Now().Add(domain.DefaultSessionTokenTTL), session.ExpiresAt, 5*time.Second)
+
+	authKey, err := svc.AuthenticateSessionToken(session.Token)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authKey.ID)
+	assert.Equal(t, project.OrgID, authKey.OrgID)
+	assert.Equal(t, []string{domain.ScopeInstancesRead}, authKey.Scopes)
+	assert.Equal(t, []string{project.ID}, authKey.ProjectIDs)
+	assert.False(t, authKey.HasScope(domain.ScopeInstancesWrite))
+
+	// Without narrowing, the token has the key's access
+	session, err = svc.CreateSessionToken(project.OrgID, domain.CreateSessionTokenRequest{}, &key.APIKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.Scopes, session.Scopes)
+	assert.Empty(t, session.ProjectIDs)
+
+	// The token is verified without the database, so it outlives its key
+	require.NoError(t, svc.DeleteAPIKey(project.OrgID, key.ID, nil))
+	_, err = svc.AuthenticateSessionToken(session.Token)
+	assert.NoError(t, err)
+
+	// Tampered tokens and API keys are rejected
+	_, err = svc.AuthenticateSessionToken(session.Token + "2")
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+	_, err = svc.AuthenticateSessionToken(key.Token)
+	assert.True(t, domain.IsUnauthorized(err), "expected unauthorized, got %v", err)
+}
+
+func TestSessionToken_Limits(t *testing.T) {
+	svc := setupTestService(t)
+	svc.SetSigningSecret([]byte("test-secret"))
+	project := createTestProject(t, svc)
+
+	expiresAt := time.Now().Add(10 * time.Minute)
+	key, err := svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{
+		Scopes:    []string{domain.ScopeInstancesRead},
+		ExpiresAt: &expiresAt,
+	}, nil)
+	require.NoError(t, err)
+
+	// Sessions end no later than their key
+	session, err := svc.CreateSessionToken(project.OrgID, domain.CreateSessionTokenRequest{}, &key.APIKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.ExpiresAt.Unix(), session.ExpiresAt.Unix())
+
+	_, err = svc.CreateSessionToken(project.OrgID, domain.CreateSessionTokenRequest{Scopes: []string{domain.ScopeInstancesWrite}}, &key.APIKey)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+
+	for _, ttl := range []int{30, -60, int(domain.MaxSessionTokenTTL.Seconds()) + 1} {
+		_, err = svc.CreateSessionToken(project.OrgID, domain.CreateSessionTokenRequest{TTLSeconds: ttl}, &key.APIKey)
+		assert.True(t, domain.IsInvalidInput(err), "expected invalid input for %d, got %v", ttl, err)
+	}
+
+	other, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "other-org", Name: "Other Org"})
+	require.NoError(t, err)
+	_, err = svc.CreateSessionToken(other.ID, domain.CreateSessionTokenRequest{}, &key.APIKey)
+	assert.True(t, domain.IsForbidden(err), "expected forbidden, got %v", err)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/pkg/endec"
+)
+
+// SetSigningSecret sets the server-side secret used to sign stateless tokens
+func (s *Service) SetSigningSecret(secret []byte) {
+	s.signingSecret = secret
+}
+
+// signature computes the HMAC-SHA256 of a token payload, bound to the token prefix
+func (s *Service) signature(prefix string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.signingSecret)
+	mac.Write([]byte(prefix))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// signToken encodes claims as JSON, appends an HMAC signature and returns a nah token
+func (s *Service) signToken(prefix string, claims any) (string, error) {
+	if len(s.signingSecret) == 0 {
+		return "", domain.InternalError("signing secret is not configured")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", domain.InternalError("failed to encode token claims")
+	}
+	token, err := endec.CreateTokenFromData(prefix, append(payload, s.signature(prefix, payload)...))
+	if err != nil {
+		return "", domain.InternalError("failed to create token")
+	}
+	return token, nil
+}
+
+// verifyToken checks the prefix and signature of a token created by signToken and decodes its claims
+func (s *Service) verifyToken(token, prefix string, claims any) error {
+	if len(s.signingSecret) == 0 {
+		return domain.InternalError("signing secret is not configured")
+	}
+	data, err := endec.ValidateToken(token, prefix)
+	if err != nil || len(data) <= sha256.Size {
+		return domain.UnauthorizedError("invalid token format")
+	}
+	payload, sig := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(sig, s.signature(prefix, payload)) {
+		return domain.UnauthorizedError("invalid token signature")
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return domain.UnauthorizedError("invalid token payload")
+	}
+	return nil
+}
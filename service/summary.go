@@ -0,0 +1,91 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// summaryRecentChanges is the number of recently changed resources in an org summary
+const summaryRecentChanges = 10
+
+// GetOrgSummary returns an inventory of an org's resources: counts of projects,
+// instances (by region and status), buckets and stored objects, Terraform states and
+// their held locks, the most recently changed resources and the org's API keys
+// With an API key, the locks, recent changes and API keys are limited to what it can read
+func (s *Service) GetOrgSummary(orgID string, key *domain.APIKey) (*domain.OrgSummary, error) {
+	if _, err := s.orgRepo.GetByID(orgID); err != nil {
+		return nil, err
+	}
+	if s.searchRepo == nil {
+		return nil, domain.InternalError("search is not configured")
+	}
+
+	summary := &domain.OrgSummary{
+		Instances: domain.InstanceSummary{
+			ByRegion: map[string]int{},
+			ByStatus: map[string]int{},
+		},
+		TFStateLocks: []*domain.TFStateSummary{},
+		APIKeys:      []*domain.APIKey{},
+	}
+	canRead := func(scope string) bool {
+		return key == nil || key.HasScope(scope)
+	}
+
+	projects, err := s.projectRepo.List(domain.ProjectListOptions{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	summary.Projects = len(projects)
+
+	for _, project := range projects {
+		instances, err := s.instanceRepo.List(domain.InstanceListOptions{ProjectID: project.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			summary.Instances.Total++
+			summary.Instances.ByRegion[instance.Region]++
+			summary.Instances.ByStatus[instance.Status]++
+		}
+
+		usages, err := s.bucketRepo.ListUsage(project.ID)
+		if err != nil {
+			return nil, err
+		}
+		summary.Buckets += len(usages)
+		for _, u := range usages {
+			summary.Storage.Bytes += u.Usage.Bytes
+			summary.Storage.Objects += u.Usage.Objects
+		}
+	}
+
+	states, err := s.ListTFStates(orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		// A state that is locked but not written yet has no content
+		if state.Size > 0 {
+			summary.TFStates++
+		}
+		if state.Lock != nil && canRead(domain.ScopeTFStateRead) {
+			summary.TFStateLocks = append(summary.TFStateLocks, state)
+		}
+	}
+
+	recent := domain.SearchOptions{OrgID: orgID, Limit: summaryRecentChanges}
+	limitSearch(&recent, key)
+	summary.RecentChanges, err = s.searchRepo.Recent(recent)
+	if err != nil {
+		return nil, err
+	}
+
+	if canRead(domain.ScopeAPIKeysRead) {
+		summary.APIKeys, err = s.apiKeyRepo.ListByOrgID(orgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrgSummary(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+
+	for _, req := range []domain.CreateInstanceRequest{
+		{Name: "web-1", Region: "us-east-1", Status: "running"},
+		{Name: "web-2", Region: "us-east-1", Status: "stopped"},
+		{Name: "web-3", Region: "eu-west-1", Status: "running"},
+	} {
+		req.ProjectID, req.CPU, req.MemoryMB, req.Image = project.ID, 1, 512, "ubuntu-24.04"
+		_, err := svc.CreateInstance(req)
+		require.NoError(t, err)
+	}
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "a.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+	require.NoError(t, svc.SetTFState(project.OrgID, "prod", `{"version":4,"serial":1,"lineage":"l"}`))
+	locked, _, err := svc.TryLockTFState(project.OrgID, "prod", `{"ID":"lock-1","Who":"ci"}`)
+	require.NoError(t, err)
+	require.False(t, locked)
+
+	summary, err := svc.GetOrgSummary(project.OrgID, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.Projects)
+	assert.Equal(t, 3, summary.Instances.Total)
+	assert.Equal(t, map[string]int{"us-east-1": 2, "eu-west-1": 1}, summary.Instances.ByRegion)
+	assert.Equal(t, map[string]int{"running": 2, "stopped": 1}, summary.Instances.ByStatus)
+	assert.Equal(t, 1, summary.Buckets)
+	assert.Equal(t, domain.StorageUsage{Bytes: 5, Objects: 1}, summary.Storage)
+	assert.Equal(t, 1, summary.TFStates)
+	require.Len(t, summary.TFStateLocks, 1)
+	assert.Equal(t, "ci", summary.TFStateLocks[0].Lock.Who)
+	// Project, bucket, 3 instances, the object and the state and its lock
+	assert.Len(t, summary.RecentChanges, 8)
+
+	_, err = svc.GetOrgSummary("missing", nil)
+	assert.True(t, domain.IsNotFound(err))
+}
+
+func TestGetOrgSummary_ScopedKey(t *testing.T) {
+	svc := setupTestService(t)
+	bucket := createTestBucket(t, svc)
+	project, err := svc.GetProject(bucket.ProjectID)
+	require.NoError(t, err)
+
+	_, err = svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: "web-1", Region: "us-east-1", CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+	require.NoError(t, err)
+	_, err = svc.CreateObject(domain.CreateObjectRequest{BucketID: bucket.ID, Path: "secret-plans.txt", Content: "aGVsbG8="})
+	require.NoError(t, err)
+	require.NoError(t, svc.SetTFState(project.OrgID, "prod", `{"version":4,"serial":1,"lineage":"l"}`))
+	_, _, err = svc.TryLockTFState(project.OrgID, "prod", `{"ID":"lock-1","Who":"ci"}`)
+	require.NoError(t, err)
+	_, err = svc.CreateAPIKey(project.OrgID, domain.CreateAPIKeyRequest{Name: "deploy"}, nil)
+	require.NoError(t, err)
+	keys, err := svc.apiKeyRepo.ListByOrgID(project.OrgID)
+	require.NoError(t, err)
+
+	recentKinds := func(summary *domain.OrgSummary) []string {
+		kinds := []string{}
+		for _, resource := range summary.RecentChanges {
+			kinds = append(kinds, resource.Kind)
+		}
+		return kinds
+	}
+
+	// org:read alone only gets the counts
+	summary, err := svc.GetOrgSummary(project.OrgID, &domain.APIKey{Scopes: []string{domain.ScopeOrgRead}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Instances.Total)
+	assert.Equal(t, 1, summary.TFStates)
+	assert.Empty(t, summary.TFStateLocks)
+	assert.Empty(t, summary.APIKeys)
+	assert.Empty(t, summary.RecentChanges)
+
+	summary, err = svc.GetOrgSummary(project.OrgID, &domain.APIKey{Scopes: []string{domain.ScopeOrgRead, domain.ScopeInstancesRead, domain.ScopeTFStateRead, domain.ScopeAPIKeysWrite}})
+	require.NoError(t, err)
+	require.Len(t, summary.TFStateLocks, 1)
+	assert.Len(t, summary.APIKeys, len(keys))
+	assert.Equal(t, []string{domain.ResourceKindInstance}, recentKinds(summary))
+
+	// Keys without scopes see everything
+	summary, err = svc.GetOrgSummary(project.OrgID, &domain.APIKey{})
+	require.NoError(t, err)
+	assert.Len(t, summary.TFStateLocks, 1)
+	assert.Len(t, summary.APIKeys, len(keys))
+	assert.ElementsMatch(t, []string{
+		domain.ResourceKindProject, domain.ResourceKindInstance, domain.ResourceKindBucket, domain.ResourceKindObject,
+		domain.ResourceKindMetadata, domain.ResourceKindMetadata,
+	}, recentKinds(summary))
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/storage/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestService creates a service backed by a new in-memory SQLite database
+func setupTestService(t *testing.T) *Service {
+	t.Helper()
+
+	// A named shared-cache database so that all pooled connections (including
+	// those used by background goroutines) see the same in-memory data
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared&_fk=1"
+	db, err := sqlite.NewDB(dsn)
+	require.NoError(t, err, "Failed to create test database")
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	svc := NewService(
+		sqlite.NewOrganizationRepository(db),
+		sqlite.NewAPIKeyRepository(db),
+		sqlite.NewProjectRepository(db),
+		sqlite.NewInstanceRepository(db),
+		sqlite.NewMetadataRepository(db),
+		sqlite.NewBucketRepository(db),
+		sqlite.NewObjectRepository(db),
+	)
+	svc.SetSearchRepository(sqlite.NewSearchRepository(db))
+	svc.SetImageRepository(sqlite.NewImageRepository(db))
+	svc.SetNetworkRepositories(sqlite.NewNetworkRepository(db), sqlite.NewSubnetRepository(db))
+	svc.SetFirewallRuleRepository(sqlite.NewFirewallRuleRepository(db))
+	svc.SetVolumeRepository(sqlite.NewVolumeRepository(db))
+	svc.SetOIDC(sqlite.NewOIDCTrustPolicyRepository(db), fakeVerifier{})
+	return svc
+}
+
+// createTestProject creates an org and a project and returns the project
+func createTestProject(t *testing.T, svc *Service) *domain.Project {
+	t.Helper()
+
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+	project, err := svc.CreateProject(org.ID, domain.CreateProjectRequest{Slug: "test-project", Name: "Test Project"})
+	require.NoError(t, err)
+	return project
+}
+
+// createTestBucket creates an org, a project and a bucket and returns the bucket
+func createTestBucket(t *testing.T, svc *Service) *domain.Bucket {
+	t.Helper()
+
+	project := createTestProject(t, svc)
+	bucket, err := svc.CreateBucket(project.ID, domain.CreateBucketRequest{Name: "test-bucket"})
+	require.NoError(t, err)
+	return bucket
+}
@@ -2,30 +2,23 @@ package service
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 
 	"github.com/hypertf/nahcloud/domain"
 )
 
-func tfStatePath(stateID string) string      { return "tfstate/" + stateID }
-func tfStateLockPath(stateID string) string { return "tfstate/" + stateID + ".lock" }
+const (
+	tfStatePrefix     = "tfstate/"
+	tfStateLockSuffix = ".lock"
+)
 
-// metadataByExactPath finds metadata by exact path using prefix listing
-func (s *Service) metadataByExactPath(path string) (*domain.Metadata, error) {
-	items, err := s.metadataRepo.List(domain.MetadataListOptions{Prefix: path})
-	if err != nil {
-		return nil, err
-	}
-	for _, m := range items {
-		if m.Path == path {
-			return m, nil
-		}
-	}
-	return nil, domain.NotFoundError("metadata", path)
-}
+func tfStatePath(stateID string) string     { return tfStatePrefix + stateID }
+func tfStateLockPath(stateID string) string { return tfStatePrefix + stateID + tfStateLockSuffix }
 
 // GetTFState returns the raw state JSON for a given state ID
-func (s *Service) GetTFState(stateID string) (string, error) {
-	m, err := s.metadataByExactPath(tfStatePath(stateID))
+func (s *Service) GetTFState(orgID, stateID string) (string, error) {
+	m, err := s.metadataRepo.GetByPath(orgID, tfStatePath(stateID))
 	if err != nil {
 		return "", err
 	}
@@ -33,12 +26,12 @@ func (s *Service) GetTFState(stateID string) (string, error) {
 }
 
 // SetTFState creates or updates the state JSON for a given state ID
-func (s *Service) SetTFState(stateID string, stateJSON string) error {
+func (s *Service) SetTFState(orgID, stateID string, stateJSON string) error {
 	path := tfStatePath(stateID)
-	m, err := s.metadataByExactPath(path)
+	m, err := s.metadataRepo.GetByPath(orgID, path)
 	if err != nil {
 		if domain.IsNotFound(err) {
-			_, err := s.metadataRepo.Create(domain.CreateMetadataRequest{Path: path, Value: stateJSON})
+			_, err := s.metadataRepo.Create(domain.CreateMetadataRequest{OrgID: orgID, Path: path, Value: stateJSON})
 			return err
 		}
 		return err
@@ -48,9 +41,8 @@ func (s *Service) SetTFState(stateID string, stateJSON string) error {
 }
 
 // DeleteTFState deletes the state entry if it exists
-func (s *Service) DeleteTFState(stateID string) error {
-	path := tfStatePath(stateID)
-	m, err := s.metadataByExactPath(path)
+func (s *Service) DeleteTFState(orgID, stateID string) error {
+	m, err := s.metadataRepo.GetByPath(orgID, tfStatePath(stateID))
 	if err != nil {
 		if domain.IsNotFound(err) {
 			return nil
@@ -61,8 +53,8 @@ func (s *Service) DeleteTFState(stateID string) error {
 }
 
 // GetTFStateLock returns the current lock JSON and parsed lock info if present
-func (s *Service) GetTFStateLock(stateID string) (string, *domain.TFStateLock, error) {
-	m, err := s.metadataByExactPath(tfStateLockPath(stateID))
+func (s *Service) GetTFStateLock(orgID, stateID string) (string, *domain.TFStateLock, error) {
+	m, err := s.metadataRepo.GetByPath(orgID, tfStateLockPath(stateID))
 	if err != nil {
 		return "", nil, err
 	}
@@ -75,12 +67,12 @@ func (s *Service) GetTFStateLock(stateID string) (string, *domain.TFStateLock, e
 }
 
 // TryLockTFState attempts to acquire a lock; returns existing lock JSON if already locked
-func (s *Service) TryLockTFState(stateID string, lockJSON string) (alreadyLocked bool, existingLockJSON string, err error) {
+func (s *Service) TryLockTFState(orgID, stateID string, lockJSON string) (alreadyLocked bool, existingLockJSON string, err error) {
 	path := tfStateLockPath(stateID)
-	m, err := s.metadataByExactPath(path)
+	m, err := s.metadataRepo.GetByPath(orgID, path)
 	if err != nil {
 		if domain.IsNotFound(err) {
-			_, err := s.metadataRepo.Create(domain.CreateMetadataRequest{Path: path, Value: lockJSON})
+			_, err := s.metadataRepo.Create(domain.CreateMetadataRequest{OrgID: orgID, Path: path, Value: lockJSON})
 			return false, "", err
 		}
 		return false, "", err
@@ -90,9 +82,8 @@ func (s *Service) TryLockTFState(stateID string, lockJSON string) (alreadyLocked
 }
 
 // UnlockTFState removes the lock if present
-func (s *Service) UnlockTFState(stateID string) (existed bool, lockJSON string, err error) {
-	path := tfStateLockPath(stateID)
-	m, err := s.metadataByExactPath(path)
+func (s *Service) UnlockTFState(orgID, stateID string) (existed bool, lockJSON string, err error) {
+	m, err := s.metadataRepo.GetByPath(orgID, tfStateLockPath(stateID))
 	if err != nil {
 		if domain.IsNotFound(err) {
 			return false, "", nil
@@ -105,6 +96,51 @@ func (s *Service) UnlockTFState(stateID string) (existed bool, lockJSON string,
 	return true, m.Value, nil
 }
 
+// ListTFStates summarizes the Terraform states stored in an org, sorted by ID
+// States that aren't valid JSON are still listed, with only their ID and size set,
+// as are locks for states that haven't been written yet
+func (s *Service) ListTFStates(orgID string) ([]*domain.TFStateSummary, error) {
+	items, err := s.metadataRepo.List(domain.MetadataListOptions{OrgID: orgID, Prefix: tfStatePrefix})
+	if err != nil {
+		return nil, err
+	}
+
+	locks := map[string]*domain.TFStateLock{}
+	var summaries []*domain.TFStateSummary
+	for _, m := range items {
+		id := strings.TrimPrefix(m.Path, tfStatePrefix)
+		if lockedID, ok := strings.CutSuffix(id, tfStateLockSuffix); ok {
+			lock := &domain.TFStateLock{}
+			if err := json.Unmarshal([]byte(m.Value), lock); err != nil {
+				lock = &domain.TFStateLock{Info: m.Value}
+			}
+			locks[lockedID] = lock
+			continue
+		}
+
+		summary := &domain.TFStateSummary{ID: id, Size: len(m.Value), UpdatedAt: m.UpdatedAt}
+		var state domain.TFState
+		if err := json.Unmarshal([]byte(m.Value), &state); err == nil {
+			summary.Serial = state.Serial
+			summary.Lineage = state.Lineage
+			summary.TerraformVersion = state.TerraformVersion
+			summary.Resources = len(state.Resources)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	for _, summary := range summaries {
+		summary.Lock = locks[summary.ID]
+		delete(locks, summary.ID)
+	}
+	// Terraform locks a state before writing it for the first time
+	for id, lock := range locks {
+		summaries = append(summaries, &domain.TFStateSummary{ID: id, Lock: lock})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries, nil
+}
+
 // updateMetadataValue is a tiny helper to update only value by ID
 func (s *Service) updateMetadataValue(id string, value string) error {
 	req := domain.UpdateMetadataRequest{Value: &value}
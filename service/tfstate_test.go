@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTFState_ScopedToOrg(t *testing.T) {
+	svc := setupTestService(t)
+
+	orgA, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "org-a", Name: "Org A"})
+	require.NoError(t, err)
+	orgB, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "org-b", Name: "Org B"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SetTFState(orgA.ID, "prod", `{"serial":1}`))
+	require.NoError(t, svc.SetTFState(orgB.ID, "prod", `{"serial":2}`))
+
+	state, err := svc.GetTFState(orgA.ID, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, `{"serial":1}`, state)
+
+	locked, _, err := svc.TryLockTFState(orgA.ID, "prod", `{"ID":"lock-a"}`)
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	// The lock in org A doesn't affect org B
+	_, _, err = svc.GetTFStateLock(orgB.ID, "prod")
+	assert.True(t, domain.IsNotFound(err))
+
+	require.NoError(t, svc.DeleteTFState(orgB.ID, "prod"))
+	_, err = svc.GetTFState(orgA.ID, "prod")
+	assert.NoError(t, err)
+}
+
+func TestListTFStates(t *testing.T) {
+	svc := setupTestService(t)
+
+	org, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: "test-org", Name: "Test Org"})
+	require.NoError(t, err)
+
+	state := `{"version":4,"terraform_version":"1.9.0","serial":3,"lineage":"abc","resources":[{"mode":"managed","type":"nah_instance","name":"web","instances":[{}]}]}`
+	require.NoError(t, svc.SetTFState(org.ID, "prod", state))
+	require.NoError(t, svc.SetTFState(org.ID, "broken", "not json"))
+	_, _, err = svc.TryLockTFState(org.ID, "prod", `{"ID":"lock-1","Who":"ci"}`)
+	require.NoError(t, err)
+	_, _, err = svc.TryLockTFState(org.ID, "new", `{"ID":"lock-2"}`)
+	require.NoError(t, err)
+
+	states, err := svc.ListTFStates(org.ID)
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+
+	assert.Equal(t, "broken", states[0].ID)
+	assert.Zero(t, states[0].Serial)
+	assert.Nil(t, states[0].Lock)
+
+	assert.Equal(t, "new", states[1].ID)
+	require.NotNil(t, states[1].Lock)
+	assert.Equal(t, "lock-2", states[1].Lock.ID)
+
+	assert.Equal(t, "prod", states[2].ID)
+	assert.Equal(t, int64(3), states[2].Serial)
+	assert.Equal(t, "abc", states[2].Lineage)
+	assert.Equal(t, "1.9.0", states[2].TerraformVersion)
+	assert.Equal(t, 1, states[2].Resources)
+	require.NotNil(t, states[2].Lock)
+	assert.Equal(t, "ci", states[2].Lock.Who)
+}
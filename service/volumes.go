@@ -0,0 +1,222 @@
+package service
+
+import (
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// VolumeRepository defines the interface for volume data operations
+type VolumeRepository interface {
+	Create(volume *domain.Volume) error
+	GetByID(id string) (*domain.Volume, error)
+	List(opts domain.VolumeListOptions) ([]*domain.Volume, error)
+	Update(id string, req domain.UpdateVolumeRequest) (*domain.Volume, error)
+	Attach(id, instanceID string) (*domain.Volume, error)
+	Detach(id string) (*domain.Volume, error)
+	DetachAll(instanceID string) error
+	Delete(id string) error
+}
+
+// Volume size limits in GB
+const (
+	minVolumeSizeGB = 1
+	maxVolumeSizeGB = 16384
+)
+
+// SetVolumeRepository sets the repository of volumes
+func (s *Service) SetVolumeRepository(repo VolumeRepository) {
+	s.volumeRepo = repo
+}
+
+// validateVolumeSize validates the size of a volume
+func validateVolumeSize(sizeGB int) error {
+	if sizeGB < minVolumeSizeGB || sizeGB > maxVolumeSizeGB {
+		return domain.InvalidInputError("size_gb out of range", map[string]interface{}{
+			"min":    minVolumeSizeGB,
+			"max":    maxVolumeSizeGB,
+			"actual": sizeGB,
+		})
+	}
+	return nil
+}
+
+// CreateVolume creates a volume in a project
+func (s *Service) CreateVolume(projectID string, req domain.CreateVolumeRequest) (*domain.Volume, error) {
+	if s.volumeRepo == nil {
+		return nil, domain.InternalError("volumes are not configured")
+	}
+	if err := validateResourceName(req.Name, "volume"); err != nil {
+		return nil, err
+	}
+	if err := validateVolumeSize(req.SizeGB); err != nil {
+		return nil, err
+	}
+	if req.Type == "" {
+		req.Type = domain.VolumeTypeStandard
+	}
+	validType := false
+	for _, volumeType := range domain.ValidVolumeTypes {
+		validType = validType || req.Type == volumeType
+	}
+	if !validType {
+		return nil, domain.InvalidInputError("invalid volume type", map[string]interface{}{
+			"valid_types": domain.ValidVolumeTypes,
+			"actual":      req.Type,
+		})
+	}
+
+	region, err := s.region(req.Region)
+	if err != nil {
+		return nil, err
+	}
+	if req.Zone != "" && !region.HasZone(req.Zone) {
+		return nil, domain.InvalidInputError("invalid zone for region", map[string]interface{}{
+			"region":      region.Name,
+			"valid_zones": region.Zones,
+			"actual":      req.Zone,
+		})
+	}
+	if region.Down {
+		return nil, domain.ServiceUnavailableError("region " + region.Name + " is down")
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, domain.InternalError("failed to generate volume ID")
+	}
+	volume := &domain.Volume{
+		ID:        id,
+		ProjectID: projectID,
+		Name:      req.Name,
+		SizeGB:    req.SizeGB,
+		Type:      req.Type,
+		Region:    req.Region,
+		Zone:      req.Zone,
+	}
+	if err := s.volumeRepo.Create(volume); err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// GetVolume retrieves a volume of a project
+func (s *Service) GetVolume(projectID, id string) (*domain.Volume, error) {
+	if s.volumeRepo == nil {
+		return nil, domain.InternalError("volumes are not configured")
+	}
+	volume, err := s.volumeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if volume.ProjectID != projectID {
+		return nil, domain.NotFoundError("volume", id)
+	}
+	return volume, nil
+}
+
+// ListVolumes lists volumes with optional filtering
+func (s *Service) ListVolumes(opts domain.VolumeListOptions) ([]*domain.Volume, error) {
+	if s.volumeRepo == nil {
+		return nil, domain.InternalError("volumes are not configured")
+	}
+	return s.volumeRepo.List(opts)
+}
+
+// UpdateVolume updates a volume of a project
+func (s *Service) UpdateVolume(projectID, id string, req domain.UpdateVolumeRequest) (*domain.Volume, error) {
+	current, err := s.GetVolume(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if err := validateResourceName(*req.Name, "volume"); err != nil {
+			return nil, err
+		}
+	}
+	if req.SizeGB != nil {
+		if err := validateVolumeSize(*req.SizeGB); err != nil {
+			return nil, err
+		}
+		if *req.SizeGB < current.SizeGB {
+			return nil, domain.InvalidInputError("volumes cannot shrink", map[string]interface{}{
+				"current_size_gb":   current.SizeGB,
+				"requested_size_gb": *req.SizeGB,
+			})
+		}
+	}
+	return s.volumeRepo.Update(id, req)
+}
+
+// DeleteVolume deletes a volume of a project, which must not be attached to an instance
+func (s *Service) DeleteVolume(projectID, id string) error {
+	if _, err := s.GetVolume(projectID, id); err != nil {
+		return err
+	}
+	return s.volumeRepo.Delete(id)
+}
+
+// AttachVolume attaches a volume of a project to an instance of the same project and region
+// Attaching a volume to the instance it is attached to does nothing
+func (s *Service) AttachVolume(projectID, id string, req domain.AttachVolumeRequest) (*domain.Volume, error) {
+	volume, err := s.GetVolume(projectID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := s.instanceRepo.GetByID(req.InstanceID)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	if instance == nil || instance.ProjectID != projectID {
+		return nil, domain.ForeignKeyViolationError("instance", "id", req.InstanceID)
+	}
+
+	if instance.Region != volume.Region {
+		return nil, domain.InvalidInputError("volume and instance are in different regions", map[string]interface{}{
+			"volume_region":   volume.Region,
+			"instance_region": instance.Region,
+		})
+	}
+	if volume.Zone != "" && instance.Zone != "" && volume.Zone != instance.Zone {
+		return nil, domain.InvalidInputError("volume and instance are in different zones", map[string]interface{}{
+			"volume_zone":   volume.Zone,
+			"instance_zone": instance.Zone,
+		})
+	}
+
+	return s.volumeRepo.Attach(volume.ID, instance.ID)
+}
+
+// DetachVolume detaches a volume of a project from its instance
+// Detaching a volume that is not attached does nothing
+func (s *Service) DetachVolume(projectID, id string) (*domain.Volume, error) {
+	if _, err := s.GetVolume(projectID, id); err != nil {
+		return nil, err
+	}
+	return s.volumeRepo.Detach(id)
+}
+
+// setInstanceVolumes sets the IDs of the volumes attached to each of the instances
+func (s *Service) setInstanceVolumes(instances ...*domain.Instance) error {
+	// One query per project rather than per instance
+	byProject := map[string][]*domain.Volume{}
+	for _, instance := range instances {
+		instance.Volumes = []string{}
+		if s.volumeRepo == nil {
+			continue
+		}
+		volumes, ok := byProject[instance.ProjectID]
+		if !ok {
+			var err error
+			if volumes, err = s.volumeRepo.List(domain.VolumeListOptions{ProjectID: instance.ProjectID}); err != nil {
+				return err
+			}
+			byProject[instance.ProjectID] = volumes
+		}
+		for _, volume := range volumes {
+			if volume.InstanceID == instance.ID {
+				instance.Volumes = append(instance.Volumes, volume.ID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumes_Create(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	tests := []struct {
+		name string
+		req  domain.CreateVolumeRequest
+	}{
+		{"bad name", domain.CreateVolumeRequest{Name: "data disk", SizeGB: 10, Region: "us-east-1"}},
+		{"no size", domain.CreateVolumeRequest{Name: "data", Region: "us-east-1"}},
+		{"too large", domain.CreateVolumeRequest{Name: "data", SizeGB: 20000, Region: "us-east-1"}},
+		{"bad type", domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Type: "tape", Region: "us-east-1"}},
+		{"bad region", domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Region: "mars-1"}},
+		{"bad zone", domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Region: "us-east-1", Zone: "eu-west-1a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateVolume(project.ID, tt.req)
+			assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+		})
+	}
+
+	volume, err := svc.CreateVolume(project.ID, domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Region: "us-east-1"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.VolumeTypeStandard, volume.Type)
+	assert.Equal(t, domain.VolumeStatusAvailable, volume.Status)
+
+	_, err = svc.CreateVolume(project.ID, domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Region: "us-east-1"})
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+
+	// Volumes grow but do not shrink
+	size := 20
+	volume, err = svc.UpdateVolume(project.ID, volume.ID, domain.UpdateVolumeRequest{SizeGB: &size})
+	require.NoError(t, err)
+	assert.Equal(t, 20, volume.SizeGB)
+	size = 5
+	_, err = svc.UpdateVolume(project.ID, volume.ID, domain.UpdateVolumeRequest{SizeGB: &size})
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+
+	_, err = svc.GetVolume("other-project", volume.ID)
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
+
+func TestVolumes_Attach(t *testing.T) {
+	svc := setupTestService(t)
+	project := createTestProject(t, svc)
+
+	createInstance := func(name, region, zone string) *domain.Instance {
+		instance, err := svc.CreateInstance(domain.CreateInstanceRequest{ProjectID: project.ID, Name: name, Region: region, Zone: zone, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04"})
+		require.NoError(t, err)
+		return instance
+	}
+	web1 := createInstance("web-1", "us-east-1", "us-east-1a")
+	web2 := createInstance("web-2", "us-east-1", "")
+	eu := createInstance("eu", "eu-west-1", "")
+	other := createInstance("web-3", "us-east-1", "us-east-1b")
+	assert.Equal(t, []string{}, web1.Volumes)
+
+	volume, err := svc.CreateVolume(project.ID, domain.CreateVolumeRequest{Name: "data", SizeGB: 10, Region: "us-east-1", Zone: "us-east-1a"})
+	require.NoError(t, err)
+
+	attach := func(instanceID string) (*domain.Volume, error) {
+		return svc.AttachVolume(project.ID, volume.ID, domain.AttachVolumeRequest{InstanceID: instanceID})
+	}
+
+	_, err = attach(eu.ID)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = attach(other.ID)
+	assert.True(t, domain.IsInvalidInput(err), "expected invalid input, got %v", err)
+	_, err = attach("missing")
+	assert.True(t, domain.IsForeignKeyViolation(err), "expected foreign key violation, got %v", err)
+
+	volume, err = attach(web1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VolumeStatusInUse, volume.Status)
+	assert.Equal(t, web1.ID, volume.InstanceID)
+
+	// Attaching again to the same instance does nothing, another instance has to wait
+	_, err = attach(web1.ID)
+	require.NoError(t, err)
+	_, err = attach(web2.ID)
+	assert.True(t, domain.IsConflict(err), "expected conflict, got %v", err)
+
+	instance, err := svc.GetInstance(web1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{volume.ID}, instance.Volumes)
+
+	// Attached volumes cannot be deleted
+	assert.True(t, domain.IsConflict(svc.DeleteVolume(project.ID, volume.ID)), "expected conflict")
+
+	volume, err = svc.DetachVolume(project.ID, volume.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VolumeStatusAvailable, volume.Status)
+	assert.Empty(t, volume.InstanceID)
+
+	// Deleting an instance detaches its volumes
+	volume, err = attach(web2.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteInstance(web2.ID))
+	volume, err = svc.GetVolume(project.ID, volume.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VolumeStatusAvailable, volume.Status)
+
+	require.NoError(t, svc.DeleteVolume(project.ID, volume.ID))
+}
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -18,13 +19,43 @@ func NewAPIKeyRepository(db *DB) *APIKeyRepository {
 	return &APIKeyRepository{db: db}
 }
 
+// apiKeyColumns is the column list used by all API key queries (matches scanAPIKey)
+const apiKeyColumns = `id, org_id, name, token_hash, scopes, project_ids, expires_at, created_at, last_used_at,
+	rotated_at, COALESCE(previous_token_hash, ''), previous_token_expires_at, source`
+
+// scanAPIKey scans an API key row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var scopes, projectIDs string
+	if err := row.Scan(&key.ID, &key.OrgID, &key.Name, &key.TokenHash, &scopes, &projectIDs, &key.ExpiresAt, &key.CreatedAt, &key.LastUsedAt,
+		&key.RotatedAt, &key.PreviousTokenHash, &key.PreviousTokenExpiresAt, &key.Source); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode API key scopes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(projectIDs), &key.ProjectIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode API key projects: %w", err)
+	}
+	return key, nil
+}
+
 // Create creates a new API key
 func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 	key.CreatedAt = time.Now()
 
-	query := `INSERT INTO api_keys (id, org_id, name, token_hash, created_at) VALUES (?, ?, ?, ?, ?)`
+	scopes, err := json.Marshal(append([]string{}, key.Scopes...))
+	if err != nil {
+		return fmt.Errorf("failed to encode API key scopes: %w", err)
+	}
+	projectIDs, err := json.Marshal(append([]string{}, key.ProjectIDs...))
+	if err != nil {
+		return fmt.Errorf("failed to encode API key projects: %w", err)
+	}
+
+	query := `INSERT INTO api_keys (id, org_id, name, token_hash, scopes, project_ids, expires_at, created_at, source) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query, key.ID, key.OrgID, key.Name, key.TokenHash, key.CreatedAt)
+	_, err = r.db.Exec(query, key.ID, key.OrgID, key.Name, key.TokenHash, string(scopes), string(projectIDs), key.ExpiresAt, key.CreatedAt, key.Source)
 	if err != nil {
 		return fmt.Errorf("failed to create API key: %w", err)
 	}
@@ -34,17 +65,9 @@ func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 
 // GetByID retrieves an API key by ID
 func (r *APIKeyRepository) GetByID(id string) (*domain.APIKey, error) {
-	key := &domain.APIKey{}
-	query := `SELECT id, org_id, name, token_hash, created_at, last_used_at FROM api_keys WHERE id = ?`
-
-	err := r.db.QueryRow(query, id).Scan(
-		&key.ID,
-		&key.OrgID,
-		&key.Name,
-		&key.TokenHash,
-		&key.CreatedAt,
-		&key.LastUsedAt,
-	)
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("api_key", id)
@@ -55,19 +78,12 @@ func (r *APIKeyRepository) GetByID(id string) (*domain.APIKey, error) {
 	return key, nil
 }
 
-// GetByTokenHash retrieves an API key by token hash
+// GetByTokenHash retrieves an API key by the hash of its current or previous token
+// Callers check PreviousTokenExpiresAt when the hash matched the previous token
 func (r *APIKeyRepository) GetByTokenHash(tokenHash string) (*domain.APIKey, error) {
-	key := &domain.APIKey{}
-	query := `SELECT id, org_id, name, token_hash, created_at, last_used_at FROM api_keys WHERE token_hash = ?`
-
-	err := r.db.QueryRow(query, tokenHash).Scan(
-		&key.ID,
-		&key.OrgID,
-		&key.Name,
-		&key.TokenHash,
-		&key.CreatedAt,
-		&key.LastUsedAt,
-	)
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE token_hash = ? OR previous_token_hash = ?`
+
+	key, err := scanAPIKey(r.db.QueryRow(query, tokenHash, tokenHash))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("api_key", "token")
@@ -80,7 +96,7 @@ func (r *APIKeyRepository) GetByTokenHash(tokenHash string) (*domain.APIKey, err
 
 // ListByOrgID retrieves all API keys for an organization
 func (r *APIKeyRepository) ListByOrgID(orgID string) ([]*domain.APIKey, error) {
-	query := `SELECT id, org_id, name, token_hash, created_at, last_used_at FROM api_keys WHERE org_id = ? ORDER BY created_at DESC`
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE org_id = ? ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query, orgID)
 	if err != nil {
@@ -90,15 +106,7 @@ func (r *APIKeyRepository) ListByOrgID(orgID string) ([]*domain.APIKey, error) {
 
 	var keys []*domain.APIKey
 	for rows.Next() {
-		key := &domain.APIKey{}
-		err := rows.Scan(
-			&key.ID,
-			&key.OrgID,
-			&key.Name,
-			&key.TokenHash,
-			&key.CreatedAt,
-			&key.LastUsedAt,
-		)
+		key, err := scanAPIKey(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
 		}
@@ -108,6 +116,26 @@ func (r *APIKeyRepository) ListByOrgID(orgID string) ([]*domain.APIKey, error) {
 	return keys, nil
 }
 
+// Rotate replaces the token hash of an API key, keeping the current hash as the previous
+// one until previousExpiresAt. A previous hash from an earlier rotation is dropped
+func (r *APIKeyRepository) Rotate(id, tokenHash string, previousExpiresAt time.Time) (*domain.APIKey, error) {
+	query := `UPDATE api_keys SET previous_token_hash = token_hash, previous_token_expires_at = ?, token_hash = ?, rotated_at = ? WHERE id = ?`
+	result, err := r.db.Exec(query, previousExpiresAt, tokenHash, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, domain.NotFoundError("api_key", id)
+	}
+
+	return r.GetByID(id)
+}
+
 // UpdateLastUsed updates the last_used_at timestamp
 func (r *APIKeyRepository) UpdateLastUsed(id string) error {
 	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
@@ -135,3 +163,20 @@ func (r *APIKeyRepository) Delete(id string) error {
 
 	return nil
 }
+
+// DeleteByOrgID deletes all API keys of an organization
+func (r *APIKeyRepository) DeleteByOrgID(orgID string) error {
+	if _, err := r.db.Exec(`DELETE FROM api_keys WHERE org_id = ?`, orgID); err != nil {
+		return fmt.Errorf("failed to delete API keys: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the API keys of an organization from a source that expired before now
+func (r *APIKeyRepository) DeleteExpired(orgID, source string, now time.Time) error {
+	query := `DELETE FROM api_keys WHERE org_id = ? AND source = ? AND expires_at IS NOT NULL AND expires_at <= ?`
+	if _, err := r.db.Exec(query, orgID, source, now.UTC()); err != nil {
+		return fmt.Errorf("failed to delete expired API keys: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -19,6 +20,25 @@ func NewBucketRepository(db *DB) *BucketRepository {
 	return &BucketRepository{db: db}
 }
 
+// bucketColumns is the column list used by all bucket queries (matches scanBucket)
+const bucketColumns = `id, project_id, name, lifecycle_rules, max_bytes, max_objects, notifications, created_at, updated_at`
+
+// scanBucket scans a bucket row selected with bucketColumns
+func scanBucket(row rowScanner) (*domain.Bucket, error) {
+	bucket := &domain.Bucket{}
+	var rules, notifications string
+	if err := row.Scan(&bucket.ID, &bucket.ProjectID, &bucket.Name, &rules, &bucket.Quota.MaxBytes, &bucket.Quota.MaxObjects, &notifications, &bucket.CreatedAt, &bucket.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(rules), &bucket.LifecycleRules); err != nil {
+		return nil, fmt.Errorf("failed to decode lifecycle rules: %w", err)
+	}
+	if err := json.Unmarshal([]byte(notifications), &bucket.Notifications); err != nil {
+		return nil, fmt.Errorf("failed to decode notifications: %w", err)
+	}
+	return bucket, nil
+}
+
 // Create creates a new bucket
 func (r *BucketRepository) Create(bucket *domain.Bucket) error {
 	now := time.Now()
@@ -44,9 +64,8 @@ func (r *BucketRepository) Create(bucket *domain.Bucket) error {
 
 // GetByID retrieves a bucket by ID
 func (r *BucketRepository) GetByID(id string) (*domain.Bucket, error) {
-	bucket := &domain.Bucket{}
-	query := `SELECT id, project_id, name, created_at, updated_at FROM buckets WHERE id = ?`
-	err := r.db.QueryRow(query, id).Scan(&bucket.ID, &bucket.ProjectID, &bucket.Name, &bucket.CreatedAt, &bucket.UpdatedAt)
+	query := `SELECT ` + bucketColumns + ` FROM buckets WHERE id = ?`
+	bucket, err := scanBucket(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("bucket", id)
@@ -58,9 +77,8 @@ func (r *BucketRepository) GetByID(id string) (*domain.Bucket, error) {
 
 // GetByName retrieves a bucket by project ID and name
 func (r *BucketRepository) GetByName(projectID, name string) (*domain.Bucket, error) {
-	bucket := &domain.Bucket{}
-	query := `SELECT id, project_id, name, created_at, updated_at FROM buckets WHERE project_id = ? AND name = ?`
-	err := r.db.QueryRow(query, projectID, name).Scan(&bucket.ID, &bucket.ProjectID, &bucket.Name, &bucket.CreatedAt, &bucket.UpdatedAt)
+	query := `SELECT ` + bucketColumns + ` FROM buckets WHERE project_id = ? AND name = ?`
+	bucket, err := scanBucket(r.db.QueryRow(query, projectID, name))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("bucket", name)
@@ -74,7 +92,7 @@ func (r *BucketRepository) GetByName(projectID, name string) (*domain.Bucket, er
 func (r *BucketRepository) List(opts domain.BucketListOptions) ([]*domain.Bucket, error) {
 	var buckets []*domain.Bucket
 	var args []interface{}
-	query := `SELECT id, project_id, name, created_at, updated_at FROM buckets`
+	query := `SELECT ` + bucketColumns + ` FROM buckets`
 	var conditions []string
 
 	if opts.ProjectID != "" {
@@ -99,8 +117,8 @@ func (r *BucketRepository) List(opts domain.BucketListOptions) ([]*domain.Bucket
 	defer rows.Close()
 
 	for rows.Next() {
-		b := &domain.Bucket{}
-		if err := rows.Scan(&b.ID, &b.ProjectID, &b.Name, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		b, err := scanBucket(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan bucket: %w", err)
 		}
 		buckets = append(buckets, b)
@@ -130,6 +148,192 @@ func (r *BucketRepository) Update(id string, req domain.UpdateBucketRequest) (*d
 	return b, nil
 }
 
+// Rename gives a bucket a new name. Since the bucket ID is its name, the row is
+// copied under the new ID and its objects and deliveries are moved over in one transaction
+func (r *BucketRepository) Rename(id, name string) (*domain.Bucket, error) {
+	if _, err := r.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO buckets (id, project_id, name, lifecycle_rules, max_bytes, max_objects, notifications, created_at, updated_at)
+		SELECT ?, project_id, ?, lifecycle_rules, max_bytes, max_objects, notifications, created_at, ? FROM buckets WHERE id = ?`
+	if _, err := tx.Exec(query, name, name, time.Now(), id); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(strings.ToLower(err.Error()), "primary key constraint failed") {
+			return nil, domain.AlreadyExistsError("bucket", "name", name)
+		}
+		return nil, fmt.Errorf("failed to rename bucket: %w", err)
+	}
+	for _, table := range []string{"objects", "notification_deliveries"} {
+		if _, err := tx.Exec(`UPDATE `+table+` SET bucket_id = ? WHERE bucket_id = ?`, name, id); err != nil {
+			return nil, fmt.Errorf("failed to move %s to renamed bucket: %w", table, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM buckets WHERE id = ?`, id); err != nil {
+		return nil, fmt.Errorf("failed to remove old bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetByID(name)
+}
+
+// UpdateLifecycle replaces the lifecycle rules of a bucket
+func (r *BucketRepository) UpdateLifecycle(id string, rules []domain.LifecycleRule) (*domain.Bucket, error) {
+	b, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []domain.LifecycleRule{}
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lifecycle rules: %w", err)
+	}
+	b.LifecycleRules = rules
+	b.UpdatedAt = time.Now()
+	query := `UPDATE buckets SET lifecycle_rules = ?, updated_at = ? WHERE id = ?`
+	if _, err := r.db.Exec(query, string(encoded), b.UpdatedAt, id); err != nil {
+		return nil, fmt.Errorf("failed to update bucket lifecycle: %w", err)
+	}
+	return b, nil
+}
+
+// UpdateNotifications replaces the notification configuration of a bucket
+func (r *BucketRepository) UpdateNotifications(id string, notifications []domain.Notification) (*domain.Bucket, error) {
+	b, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	encoded, err := json.Marshal(notifications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notifications: %w", err)
+	}
+	b.Notifications = notifications
+	b.UpdatedAt = time.Now()
+	query := `UPDATE buckets SET notifications = ?, updated_at = ? WHERE id = ?`
+	if _, err := r.db.Exec(query, string(encoded), b.UpdatedAt, id); err != nil {
+		return nil, fmt.Errorf("failed to update bucket notifications: %w", err)
+	}
+	return b, nil
+}
+
+// SaveDelivery inserts or updates a notification delivery record
+func (r *BucketRepository) SaveDelivery(d *domain.NotificationDelivery) error {
+	query := `INSERT INTO notification_deliveries
+		(id, bucket_id, notification_id, event_id, event_type, url, status, attempts, response_code, error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			response_code = excluded.response_code,
+			error = excluded.error,
+			updated_at = excluded.updated_at`
+	_, err := r.db.Exec(query, d.ID, d.BucketID, d.NotificationID, d.EventID, d.EventType, d.URL,
+		d.Status, d.Attempts, d.ResponseCode, d.Error, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("bucket", "id", d.BucketID)
+		}
+		return fmt.Errorf("failed to save notification delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the most recent notification deliveries of a bucket, newest first
+func (r *BucketRepository) ListDeliveries(bucketID string, limit int) ([]*domain.NotificationDelivery, error) {
+	query := `SELECT id, bucket_id, notification_id, event_id, event_type, url, status, attempts, response_code, error, created_at, updated_at
+		FROM notification_deliveries WHERE bucket_id = ? ORDER BY created_at DESC LIMIT ?`
+	rows, err := r.db.Query(query, bucketID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.NotificationDelivery{}
+	for rows.Next() {
+		d := &domain.NotificationDelivery{}
+		if err := rows.Scan(&d.ID, &d.BucketID, &d.NotificationID, &d.EventID, &d.EventType, &d.URL,
+			&d.Status, &d.Attempts, &d.ResponseCode, &d.Error, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateQuota replaces the storage quota of a bucket
+func (r *BucketRepository) UpdateQuota(id string, quota domain.StorageQuota) (*domain.Bucket, error) {
+	b, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	b.Quota = quota
+	b.UpdatedAt = time.Now()
+	query := `UPDATE buckets SET max_bytes = ?, max_objects = ?, updated_at = ? WHERE id = ?`
+	if _, err := r.db.Exec(query, quota.MaxBytes, quota.MaxObjects, b.UpdatedAt, id); err != nil {
+		return nil, fmt.Errorf("failed to update bucket quota: %w", err)
+	}
+	return b, nil
+}
+
+// versionSizes sums the noncurrent version bytes of each object, to be joined on object_id
+const versionSizes = `(SELECT object_id, SUM(size) AS size FROM object_versions GROUP BY object_id)`
+
+// GetUsage returns the bytes and object count currently stored in a bucket
+// Bytes include the noncurrent versions of its objects
+func (r *BucketRepository) GetUsage(id string) (*domain.StorageUsage, error) {
+	usage := &domain.StorageUsage{}
+	query := `SELECT COALESCE(SUM(o.size + COALESCE(v.size, 0)), 0), COUNT(*)
+		FROM objects o LEFT JOIN ` + versionSizes + ` v ON v.object_id = o.id
+		WHERE o.bucket_id = ?`
+	if err := r.db.QueryRow(query, id).Scan(&usage.Bytes, &usage.Objects); err != nil {
+		return nil, fmt.Errorf("failed to get bucket usage: %w", err)
+	}
+	return usage, nil
+}
+
+// ListUsage returns the usage and quota of every bucket in a project, ordered by name
+func (r *BucketRepository) ListUsage(projectID string) ([]domain.BucketUsage, error) {
+	query := `SELECT b.id, b.name, b.max_bytes, b.max_objects, COALESCE(SUM(o.size + COALESCE(v.size, 0)), 0), COUNT(o.id)
+		FROM buckets b LEFT JOIN objects o ON o.bucket_id = b.id
+		LEFT JOIN ` + versionSizes + ` v ON v.object_id = o.id
+		WHERE b.project_id = ?
+		GROUP BY b.id
+		ORDER BY b.name`
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket usage: %w", err)
+	}
+	defer rows.Close()
+
+	usages := []domain.BucketUsage{}
+	for rows.Next() {
+		var u domain.BucketUsage
+		if err := rows.Scan(&u.BucketID, &u.BucketName, &u.Quota.MaxBytes, &u.Quota.MaxObjects, &u.Usage.Bytes, &u.Usage.Objects); err != nil {
+			return nil, fmt.Errorf("failed to scan bucket usage: %w", err)
+		}
+		usages = append(usages, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bucket usage: %w", err)
+	}
+	return usages, nil
+}
+
 // Delete deletes a bucket by ID (and cascades to delete its objects)
 func (r *BucketRepository) Delete(id string) error {
 	// Ensure bucket exists
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRepository_Rename(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	createTestOrg(t, db)
+
+	require.NoError(t, NewProjectRepository(db).Create(&domain.Project{ID: "p1", OrgID: testOrgID, Slug: "web", Name: "Web"}))
+	repo := NewBucketRepository(db)
+	objects := NewObjectRepository(db)
+	for _, name := range []string{"assets", "taken"} {
+		require.NoError(t, repo.Create(&domain.Bucket{ID: name, ProjectID: "p1", Name: name}))
+	}
+	_, err := repo.UpdateQuota("assets", domain.StorageQuota{MaxBytes: 100})
+	require.NoError(t, err)
+	obj, err := objects.Create(domain.CreateObjectRequest{BucketID: "assets", Path: "css/site.css", Content: "Ym9keQ=="})
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, repo.SaveDelivery(&domain.NotificationDelivery{
+		ID: "d1", BucketID: "assets", NotificationID: "n1", EventID: "e1", EventType: domain.ObjectEventCreated,
+		URL: "https://example.com/hook", Status: "delivered", CreatedAt: now, UpdatedAt: now,
+	}))
+
+	// Names are bucket IDs, so a taken name fails and leaves the bucket as it was
+	_, err = repo.Rename("assets", "taken")
+	assert.True(t, domain.IsAlreadyExists(err), "expected already exists, got %v", err)
+	_, err = repo.GetByID("assets")
+	require.NoError(t, err)
+
+	_, err = repo.Rename("missing", "other")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	bucket, err := repo.Rename("assets", "static")
+	require.NoError(t, err)
+	assert.Equal(t, "static", bucket.ID)
+	assert.Equal(t, "static", bucket.Name)
+	assert.Equal(t, "p1", bucket.ProjectID)
+	assert.Equal(t, int64(100), bucket.Quota.MaxBytes)
+
+	_, err = repo.GetByID("assets")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+
+	// Objects and deliveries follow the bucket
+	moved, err := objects.GetByID(obj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "static", moved.BucketID)
+	deliveries, err := repo.ListDeliveries("static", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "d1", deliveries[0].ID)
+	deliveries, err = repo.ListDeliveries("assets", 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
@@ -15,6 +15,14 @@ const (
 // DB wraps the SQLite database connection
 type DB struct {
 	*sql.DB
+
+	// fts5 is set when SQLite was built with FTS5 and search uses the search_index table
+	fts5 bool
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
 // NewDB creates a new SQLite database connection and initializes the schema
@@ -65,6 +73,7 @@ func (db *DB) initSchema() error {
 			id TEXT PRIMARY KEY,
 			slug TEXT UNIQUE NOT NULL,
 			name TEXT NOT NULL,
+			resource_quota TEXT,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)`,
@@ -73,15 +82,37 @@ func (db *DB) initSchema() error {
 			org_id TEXT NOT NULL,
 			name TEXT NOT NULL DEFAULT '',
 			token_hash TEXT UNIQUE NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			project_ids TEXT NOT NULL DEFAULT '[]',
+			expires_at DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			last_used_at DATETIME,
+			rotated_at DATETIME,
+			previous_token_hash TEXT,
+			previous_token_expires_at DATETIME,
+			source TEXT NOT NULL DEFAULT '',
 			FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS oidc_trust_policies (
+			id TEXT PRIMARY KEY,
+			org_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			issuer TEXT NOT NULL,
+			claims TEXT NOT NULL DEFAULT '{}',
+			scopes TEXT NOT NULL DEFAULT '[]',
+			project_ids TEXT NOT NULL DEFAULT '[]',
+			token_ttl_seconds INTEGER NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+			UNIQUE(org_id, name)
+		)`,
 		`CREATE TABLE IF NOT EXISTS projects (
 			id TEXT PRIMARY KEY,
 			org_id TEXT NOT NULL,
 			slug TEXT NOT NULL,
 			name TEXT NOT NULL,
+			storage_max_bytes INTEGER NOT NULL DEFAULT 0,
+			storage_max_objects INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
@@ -92,6 +123,11 @@ func (db *DB) initSchema() error {
 			project_id TEXT NOT NULL,
 			name TEXT NOT NULL,
 			region TEXT NOT NULL DEFAULT 'us-east-1',
+			zone TEXT NOT NULL DEFAULT '',
+			subnet_id TEXT NOT NULL DEFAULT '',
+			private_ip TEXT NOT NULL DEFAULT '',
+			firewall_rule_ids TEXT NOT NULL DEFAULT '[]',
+			instance_type TEXT NOT NULL DEFAULT '',
 			cpu INTEGER NOT NULL,
 			memory_mb INTEGER NOT NULL,
 			image TEXT NOT NULL,
@@ -101,6 +137,70 @@ func (db *DB) initSchema() error {
 			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
 			UNIQUE(project_id, name)
 		)`,
+		`CREATE TABLE IF NOT EXISTS images (
+			id TEXT PRIMARY KEY,
+			org_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			family TEXT NOT NULL,
+			version TEXT NOT NULL,
+			os TEXT NOT NULL,
+			deprecated INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+			UNIQUE(org_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS networks (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			cidr TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			UNIQUE(project_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS subnets (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			network_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			region TEXT NOT NULL,
+			cidr TEXT NOT NULL,
+			gateway_ip TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE,
+			UNIQUE(project_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS firewall_rules (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			ingress TEXT NOT NULL DEFAULT '[]',
+			egress TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			UNIQUE(project_id, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS volumes (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			size_gb INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			region TEXT NOT NULL,
+			zone TEXT NOT NULL DEFAULT '',
+			instance_id TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			FOREIGN KEY (instance_id) REFERENCES instances(id) ON DELETE SET NULL,
+			UNIQUE(project_id, name)
+		)`,
 		`CREATE TABLE IF NOT EXISTS metadata (
 			id TEXT PRIMARY KEY,
 			org_id TEXT NOT NULL,
@@ -115,6 +215,10 @@ func (db *DB) initSchema() error {
 			id TEXT PRIMARY KEY,
 			project_id TEXT NOT NULL,
 			name TEXT NOT NULL,
+			lifecycle_rules TEXT NOT NULL DEFAULT '[]',
+			max_bytes INTEGER NOT NULL DEFAULT 0,
+			max_objects INTEGER NOT NULL DEFAULT 0,
+			notifications TEXT NOT NULL DEFAULT '[]',
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
@@ -125,11 +229,41 @@ func (db *DB) initSchema() error {
 			bucket_id TEXT NOT NULL,
 			path TEXT NOT NULL,
 			content TEXT NOT NULL,
+			size INTEGER NOT NULL DEFAULT 0,
+			content_type TEXT NOT NULL DEFAULT '',
+			metadata TEXT NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL DEFAULT 1,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (bucket_id) REFERENCES buckets(id) ON DELETE CASCADE,
 			UNIQUE(bucket_id, path)
 		)`,
+		`CREATE TABLE IF NOT EXISTS object_versions (
+			id TEXT PRIMARY KEY,
+			object_id TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			size INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL,
+			archived_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (object_id) REFERENCES objects(id) ON DELETE CASCADE,
+			UNIQUE(object_id, version)
+		)`,
+		`CREATE TABLE IF NOT EXISTS notification_deliveries (
+			id TEXT PRIMARY KEY,
+			bucket_id TEXT NOT NULL,
+			notification_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			url TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_code INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (bucket_id) REFERENCES buckets(id) ON DELETE CASCADE
+		)`,
 	}
 
 	for _, schema := range schemas {
@@ -143,6 +277,10 @@ func (db *DB) initSchema() error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if err := db.initSearchIndex(); err != nil {
+		return fmt.Errorf("failed to initialize search index: %w", err)
+	}
+
 	return nil
 }
 
@@ -154,9 +292,117 @@ func (db *DB) runMigrations() error {
 		return fmt.Errorf("failed to migrate to organizations: %w", err)
 	}
 
+	// Columns added after the initial schema
+	// backfill, if set, runs once right after the column is added
+	columns := []struct{ table, column, definition, backfill string }{
+		{"buckets", "lifecycle_rules", "TEXT NOT NULL DEFAULT '[]'", ""},
+		{"objects", "version", "INTEGER NOT NULL DEFAULT 1", ""},
+		{"buckets", "max_bytes", "INTEGER NOT NULL DEFAULT 0", ""},
+		{"buckets", "max_objects", "INTEGER NOT NULL DEFAULT 0", ""},
+		{"projects", "storage_max_bytes", "INTEGER NOT NULL DEFAULT 0", ""},
+		{"projects", "storage_max_objects", "INTEGER NOT NULL DEFAULT 0", ""},
+		// Decoded size of the base64 content (length / 4 * 3 minus padding)
+		{"objects", "size", "INTEGER NOT NULL DEFAULT 0", `UPDATE objects SET size = length(content) / 4 * 3 -
+			CASE WHEN content LIKE '%==' THEN 2 WHEN content LIKE '%=' THEN 1 ELSE 0 END`},
+		{"objects", "content_type", "TEXT NOT NULL DEFAULT ''", ""},
+		{"object_versions", "size", "INTEGER NOT NULL DEFAULT 0", `UPDATE object_versions SET size = length(content) / 4 * 3 -
+			CASE WHEN content LIKE '%==' THEN 2 WHEN content LIKE '%=' THEN 1 ELSE 0 END`},
+		{"objects", "metadata", "TEXT NOT NULL DEFAULT '{}'", ""},
+		{"buckets", "notifications", "TEXT NOT NULL DEFAULT '[]'", ""},
+		{"api_keys", "scopes", "TEXT NOT NULL DEFAULT '[]'", ""},
+		{"api_keys", "project_ids", "TEXT NOT NULL DEFAULT '[]'", ""},
+		{"api_keys", "expires_at", "DATETIME", ""},
+		{"api_keys", "rotated_at", "DATETIME", ""},
+		{"api_keys", "previous_token_hash", "TEXT", ""},
+		{"api_keys", "previous_token_expires_at", "DATETIME", ""},
+		// Keys minted before the column existed were named after their trust policy
+		{"api_keys", "source", "TEXT NOT NULL DEFAULT ''", `UPDATE api_keys SET source = 'oidc' WHERE name LIKE 'oidc:%' AND expires_at IS NOT NULL`},
+		// NULL means the server's default quota
+		{"organizations", "resource_quota", "TEXT", ""},
+		{"instances", "instance_type", "TEXT NOT NULL DEFAULT ''", ""},
+		{"instances", "zone", "TEXT NOT NULL DEFAULT ''", ""},
+		{"instances", "subnet_id", "TEXT NOT NULL DEFAULT ''", ""},
+		{"instances", "private_ip", "TEXT NOT NULL DEFAULT ''", ""},
+		{"instances", "firewall_rule_ids", "TEXT NOT NULL DEFAULT '[]'", ""},
+	}
+	for _, c := range columns {
+		added, err := db.addColumnIfMissing(c.table, c.column, c.definition)
+		if err != nil {
+			return err
+		}
+		if added && c.backfill != "" {
+			if _, err := db.Exec(c.backfill); err != nil {
+				return fmt.Errorf("failed to backfill %s.%s: %w", c.table, c.column, err)
+			}
+		}
+	}
+
+	// instances.subnet_id is '' for instances outside a subnet, so it cannot be a foreign key
+	// These triggers enforce one
+	subnetTriggers := []string{
+		`CREATE TRIGGER IF NOT EXISTS instances_subnet_insert BEFORE INSERT ON instances
+			WHEN NEW.subnet_id != '' AND NOT EXISTS (SELECT 1 FROM subnets WHERE id = NEW.subnet_id)
+			BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: instances.subnet_id'); END`,
+		`CREATE TRIGGER IF NOT EXISTS instances_subnet_update BEFORE UPDATE OF subnet_id ON instances
+			WHEN NEW.subnet_id != '' AND NOT EXISTS (SELECT 1 FROM subnets WHERE id = NEW.subnet_id)
+			BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: instances.subnet_id'); END`,
+		`CREATE TRIGGER IF NOT EXISTS subnets_instances_delete BEFORE DELETE ON subnets
+			WHEN EXISTS (SELECT 1 FROM instances WHERE subnet_id = OLD.id)
+			BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: instances.subnet_id'); END`,
+	}
+	for _, trigger := range subnetTriggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return fmt.Errorf("failed to create subnet trigger: %w", err)
+		}
+	}
+
+	// API keys are looked up by their previous token during a rotation's overlap
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_api_keys_previous_token_hash ON api_keys(previous_token_hash)`); err != nil {
+		return fmt.Errorf("failed to create api_keys index: %w", err)
+	}
+
 	return nil
 }
 
+// hasColumn reports whether a table has a column with the given name
+func (db *DB) hasColumn(table, column string) (bool, error) {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s table info: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, dtype string
+		var notnull, pk int
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &dtype, &notnull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already present
+// It reports whether the column was added
+func (db *DB) addColumnIfMissing(table, column, definition string) (bool, error) {
+	exists, err := db.hasColumn(table, column)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if _, err := db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition); err != nil {
+		return false, fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+	return true, nil
+}
+
 // migrateToOrganizations migrates existing data to the new organization-based schema
 func (db *DB) migrateToOrganizations() error {
 	// Check if organizations table is empty
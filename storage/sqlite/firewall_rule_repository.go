@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// FirewallRuleRepository handles firewall rule data operations
+type FirewallRuleRepository struct {
+	db *DB
+}
+
+// NewFirewallRuleRepository creates a new firewall rule repository
+func NewFirewallRuleRepository(db *DB) *FirewallRuleRepository {
+	return &FirewallRuleRepository{db: db}
+}
+
+// firewallRuleColumns is the column list used by all firewall rule queries (matches scanFirewallRule)
+const firewallRuleColumns = `id, project_id, name, description, ingress, egress, created_at, updated_at`
+
+// scanFirewallRule scans a firewall rule row selected with firewallRuleColumns
+func scanFirewallRule(row rowScanner) (*domain.FirewallRule, error) {
+	rule := &domain.FirewallRule{}
+	var ingress, egress string
+	if err := row.Scan(&rule.ID, &rule.ProjectID, &rule.Name, &rule.Description, &ingress, &egress, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(ingress), &rule.Ingress); err != nil {
+		return nil, fmt.Errorf("failed to decode firewall rule ingress: %w", err)
+	}
+	if err := json.Unmarshal([]byte(egress), &rule.Egress); err != nil {
+		return nil, fmt.Errorf("failed to decode firewall rule egress: %w", err)
+	}
+	return rule, nil
+}
+
+// encodeFirewallEntries encodes the entries of one direction of a firewall rule
+func encodeFirewallEntries(entries []domain.FirewallRuleEntry) (string, error) {
+	encoded, err := json.Marshal(append([]domain.FirewallRuleEntry{}, entries...))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode firewall rule entries: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Create creates a new firewall rule
+func (r *FirewallRuleRepository) Create(rule *domain.FirewallRule) error {
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	ingress, err := encodeFirewallEntries(rule.Ingress)
+	if err != nil {
+		return err
+	}
+	egress, err := encodeFirewallEntries(rule.Egress)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO firewall_rules (` + firewallRuleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query, rule.ID, rule.ProjectID, rule.Name, rule.Description, ingress, egress, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: firewall_rules.project_id, firewall_rules.name") {
+			return domain.AlreadyExistsError("firewall rule", "name", rule.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("project", "id", rule.ProjectID)
+		}
+		return fmt.Errorf("failed to create firewall rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a firewall rule by ID
+func (r *FirewallRuleRepository) GetByID(id string) (*domain.FirewallRule, error) {
+	query := `SELECT ` + firewallRuleColumns + ` FROM firewall_rules WHERE id = ?`
+
+	rule, err := scanFirewallRule(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("firewall rule", id)
+		}
+		return nil, fmt.Errorf("failed to get firewall rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// ListByProjectID retrieves the firewall rules of a project
+func (r *FirewallRuleRepository) ListByProjectID(projectID string) ([]*domain.FirewallRule, error) {
+	query := `SELECT ` + firewallRuleColumns + ` FROM firewall_rules WHERE project_id = ? ORDER BY name`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list firewall rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.FirewallRule{}
+	for rows.Next() {
+		rule, err := scanFirewallRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan firewall rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating firewall rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Update updates a firewall rule
+func (r *FirewallRuleRepository) Update(id string, req domain.UpdateFirewallRuleRequest) (*domain.FirewallRule, error) {
+	existing, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	if req.Description != nil {
+		existing.Description = *req.Description
+	}
+	if req.Ingress != nil {
+		existing.Ingress = *req.Ingress
+	}
+	if req.Egress != nil {
+		existing.Egress = *req.Egress
+	}
+	existing.UpdatedAt = time.Now()
+
+	ingress, err := encodeFirewallEntries(existing.Ingress)
+	if err != nil {
+		return nil, err
+	}
+	egress, err := encodeFirewallEntries(existing.Egress)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE firewall_rules SET name = ?, description = ?, ingress = ?, egress = ?, updated_at = ? WHERE id = ?`
+	_, err = r.db.Exec(query, existing.Name, existing.Description, ingress, egress, existing.UpdatedAt, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: firewall_rules.project_id, firewall_rules.name") {
+			return nil, domain.AlreadyExistsError("firewall rule", "name", existing.Name)
+		}
+		return nil, fmt.Errorf("failed to update firewall rule: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Delete deletes a firewall rule by ID
+func (r *FirewallRuleRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM firewall_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete firewall rule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.NotFoundError("firewall rule", id)
+	}
+
+	return nil
+}
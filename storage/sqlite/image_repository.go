@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// ImageRepository handles private image data operations
+type ImageRepository struct {
+	db *DB
+}
+
+// NewImageRepository creates a new image repository
+func NewImageRepository(db *DB) *ImageRepository {
+	return &ImageRepository{db: db}
+}
+
+// imageColumns is the column list used by all image queries (matches scanImage)
+const imageColumns = `id, org_id, name, family, version, os, deprecated, created_at, updated_at`
+
+// scanImage scans an image row selected with imageColumns
+func scanImage(row rowScanner) (*domain.Image, error) {
+	image := &domain.Image{}
+	if err := row.Scan(&image.ID, &image.OrgID, &image.Name, &image.Family, &image.Version, &image.OS, &image.Deprecated, &image.CreatedAt, &image.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// Create creates a new image
+func (r *ImageRepository) Create(image *domain.Image) error {
+	now := time.Now()
+	image.CreatedAt = now
+	image.UpdatedAt = now
+
+	query := `INSERT INTO images (` + imageColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, image.ID, image.OrgID, image.Name, image.Family, image.Version, image.OS, image.Deprecated, image.CreatedAt, image.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: images.org_id, images.name") {
+			return domain.AlreadyExistsError("image", "name", image.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("organization", "id", image.OrgID)
+		}
+		return fmt.Errorf("failed to create image: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an image by ID
+func (r *ImageRepository) GetByID(id string) (*domain.Image, error) {
+	query := `SELECT ` + imageColumns + ` FROM images WHERE id = ?`
+
+	image, err := scanImage(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("image", id)
+		}
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	return image, nil
+}
+
+// List retrieves the images of an organization, optionally of one family
+func (r *ImageRepository) List(opts domain.ImageListOptions) ([]*domain.Image, error) {
+	query := `SELECT ` + imageColumns + ` FROM images WHERE org_id = ?`
+	args := []interface{}{opts.OrgID}
+	if opts.Family != "" {
+		query += ` AND family = ?`
+		args = append(args, opts.Family)
+	}
+	query += ` ORDER BY family, created_at, name`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	defer rows.Close()
+
+	images := []*domain.Image{}
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan image: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating images: %w", err)
+	}
+
+	return images, nil
+}
+
+// SetDeprecated marks an image as deprecated or not
+func (r *ImageRepository) SetDeprecated(id string, deprecated bool) (*domain.Image, error) {
+	result, err := r.db.Exec(`UPDATE images SET deprecated = ?, updated_at = ? WHERE id = ?`, deprecated, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update image: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, domain.NotFoundError("image", id)
+	}
+
+	return r.GetByID(id)
+}
+
+// Delete deletes an image by ID
+func (r *ImageRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM images WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.NotFoundError("image", id)
+	}
+
+	return nil
+}
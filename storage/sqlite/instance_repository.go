@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -19,19 +20,60 @@ func NewInstanceRepository(db *DB) *InstanceRepository {
 	return &InstanceRepository{db: db}
 }
 
+// instanceColumns is the column list used by all instance queries (matches scanInstance)
+const instanceColumns = `id, project_id, name, region, zone, subnet_id, private_ip, firewall_rule_ids, instance_type, cpu, memory_mb, image, status, created_at, updated_at`
+
+// scanInstance scans an instance row selected with instanceColumns
+func scanInstance(row rowScanner) (*domain.Instance, error) {
+	instance := &domain.Instance{}
+	var firewallRuleIDs string
+	err := row.Scan(
+		&instance.ID,
+		&instance.ProjectID,
+		&instance.Name,
+		&instance.Region,
+		&instance.Zone,
+		&instance.SubnetID,
+		&instance.PrivateIP,
+		&firewallRuleIDs,
+		&instance.InstanceType,
+		&instance.CPU,
+		&instance.MemoryMB,
+		&instance.Image,
+		&instance.Status,
+		&instance.CreatedAt,
+		&instance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(firewallRuleIDs), &instance.FirewallRuleIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode instance firewall rules: %w", err)
+	}
+	return instance, nil
+}
+
 // Create creates a new instance
 func (r *InstanceRepository) Create(instance *domain.Instance) error {
 	now := time.Now()
 	instance.CreatedAt = now
 	instance.UpdatedAt = now
 
-	query := `INSERT INTO instances (id, project_id, name, region, cpu, memory_mb, image, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	firewallRuleIDs, err := json.Marshal(append([]string{}, instance.FirewallRuleIDs...))
+	if err != nil {
+		return fmt.Errorf("failed to encode instance firewall rules: %w", err)
+	}
 
-	_, err := r.db.Exec(query, instance.ID, instance.ProjectID, instance.Name, instance.Region, instance.CPU, instance.MemoryMB, instance.Image, instance.Status, instance.CreatedAt, instance.UpdatedAt)
+	query := `INSERT INTO instances (` + instanceColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.Exec(query, instance.ID, instance.ProjectID, instance.Name, instance.Region, instance.Zone, instance.SubnetID, instance.PrivateIP, string(firewallRuleIDs), instance.InstanceType, instance.CPU, instance.MemoryMB, instance.Image, instance.Status, instance.CreatedAt, instance.UpdatedAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: instances.project_id, instances.name") {
 			return domain.AlreadyExistsError("instance", "name", instance.Name)
 		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed: instances.subnet_id") {
+			return domain.ForeignKeyViolationError("subnet", "id", instance.SubnetID)
+		}
 		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
 			return domain.ForeignKeyViolationError("project", "id", instance.ProjectID)
 		}
@@ -43,21 +85,9 @@ func (r *InstanceRepository) Create(instance *domain.Instance) error {
 
 // GetByID retrieves an instance by ID
 func (r *InstanceRepository) GetByID(id string) (*domain.Instance, error) {
-	instance := &domain.Instance{}
-	query := `SELECT id, project_id, name, region, cpu, memory_mb, image, status, created_at, updated_at FROM instances WHERE id = ?`
+	query := `SELECT ` + instanceColumns + ` FROM instances WHERE id = ?`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&instance.ID,
-		&instance.ProjectID,
-		&instance.Name,
-		&instance.Region,
-		&instance.CPU,
-		&instance.MemoryMB,
-		&instance.Image,
-		&instance.Status,
-		&instance.CreatedAt,
-		&instance.UpdatedAt,
-	)
+	instance, err := scanInstance(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("instance", id)
@@ -73,7 +103,7 @@ func (r *InstanceRepository) List(opts domain.InstanceListOptions) ([]*domain.In
 	var instances []*domain.Instance
 	var args []interface{}
 
-	query := `SELECT id, project_id, name, region, cpu, memory_mb, image, status, created_at, updated_at FROM instances`
+	query := `SELECT ` + instanceColumns + ` FROM instances`
 	var conditions []string
 
 	if opts.ProjectID != "" {
@@ -96,6 +126,11 @@ func (r *InstanceRepository) List(opts domain.InstanceListOptions) ([]*domain.In
 		args = append(args, opts.Status)
 	}
 
+	if opts.SubnetID != "" {
+		conditions = append(conditions, "subnet_id = ?")
+		args = append(args, opts.SubnetID)
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
@@ -109,19 +144,7 @@ func (r *InstanceRepository) List(opts domain.InstanceListOptions) ([]*domain.In
 	defer rows.Close()
 
 	for rows.Next() {
-		instance := &domain.Instance{}
-		err := rows.Scan(
-			&instance.ID,
-			&instance.ProjectID,
-			&instance.Name,
-			&instance.Region,
-			&instance.CPU,
-			&instance.MemoryMB,
-			&instance.Image,
-			&instance.Status,
-			&instance.CreatedAt,
-			&instance.UpdatedAt,
-		)
+		instance, err := scanInstance(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan instance: %w", err)
 		}
@@ -147,6 +170,9 @@ func (r *InstanceRepository) Update(id string, req domain.UpdateInstanceRequest)
 	if req.Name != nil {
 		existing.Name = *req.Name
 	}
+	if req.InstanceType != nil {
+		existing.InstanceType = *req.InstanceType
+	}
 	if req.CPU != nil {
 		existing.CPU = *req.CPU
 	}
@@ -159,11 +185,19 @@ func (r *InstanceRepository) Update(id string, req domain.UpdateInstanceRequest)
 	if req.Status != nil {
 		existing.Status = *req.Status
 	}
+	if req.FirewallRuleIDs != nil {
+		existing.FirewallRuleIDs = *req.FirewallRuleIDs
+	}
 	existing.UpdatedAt = time.Now()
 
-	query := `UPDATE instances SET name = ?, cpu = ?, memory_mb = ?, image = ?, status = ?, updated_at = ? WHERE id = ?`
+	firewallRuleIDs, err := json.Marshal(append([]string{}, existing.FirewallRuleIDs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode instance firewall rules: %w", err)
+	}
+
+	query := `UPDATE instances SET name = ?, instance_type = ?, cpu = ?, memory_mb = ?, image = ?, status = ?, firewall_rule_ids = ?, updated_at = ? WHERE id = ?`
 	
-	_, err = r.db.Exec(query, existing.Name, existing.CPU, existing.MemoryMB, existing.Image, existing.Status, existing.UpdatedAt, id)
+	_, err = r.db.Exec(query, existing.Name, existing.InstanceType, existing.CPU, existing.MemoryMB, existing.Image, existing.Status, string(firewallRuleIDs), existing.UpdatedAt, id)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: instances.project_id, instances.name") {
 			return nil, domain.AlreadyExistsError("instance", "name", existing.Name)
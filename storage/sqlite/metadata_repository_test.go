@@ -13,6 +13,7 @@ func TestMetadataRepository_Create(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	tests := []struct {
 		name        string
@@ -23,6 +24,7 @@ func TestMetadataRepository_Create(t *testing.T) {
 		{
 			name: "simple create",
 			req: domain.CreateMetadataRequest{
+				OrgID: testOrgID,
 				Path:  "/config/app.yaml",
 				Value: "database: localhost",
 			},
@@ -31,6 +33,7 @@ func TestMetadataRepository_Create(t *testing.T) {
 		{
 			name: "create with nested path",
 			req: domain.CreateMetadataRequest{
+				OrgID: testOrgID,
 				Path:  "/config/auth/ldap.yaml",
 				Value: "server: ldap.example.com",
 			},
@@ -39,6 +42,7 @@ func TestMetadataRepository_Create(t *testing.T) {
 		{
 			name: "create with empty value",
 			req: domain.CreateMetadataRequest{
+				OrgID: testOrgID,
 				Path:  "/empty",
 				Value: "",
 			},
@@ -47,6 +51,7 @@ func TestMetadataRepository_Create(t *testing.T) {
 		{
 			name: "duplicate path should fail",
 			req: domain.CreateMetadataRequest{
+				OrgID: testOrgID,
 				Path:  "/config/app.yaml", // Same as first test
 				Value: "different value",
 			},
@@ -85,9 +90,11 @@ func TestMetadataRepository_GetByID(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	// Create test metadata
 	req := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  "/config/app.yaml",
 		Value: "database: localhost",
 	}
@@ -148,9 +155,11 @@ func TestMetadataRepository_Update(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	// Create test metadata
 	req1 := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  "/config/app.yaml",
 		Value: "database: localhost",
 	}
@@ -158,6 +167,7 @@ func TestMetadataRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 
 	req2 := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  "/config/other.yaml",
 		Value: "other: value",
 	}
@@ -259,15 +269,16 @@ func TestMetadataRepository_List(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	// Set up test data
 	testData := []domain.CreateMetadataRequest{
-		{Path: "/config/app.yaml", Value: "app config"},
-		{Path: "/config/database.yaml", Value: "db config"},
-		{Path: "/config/auth/ldap.yaml", Value: "ldap config"},
-		{Path: "/config/auth/oauth.yaml", Value: "oauth config"},
-		{Path: "/data/users.json", Value: "users data"},
-		{Path: "/data/logs/app.log", Value: "log data"},
+		{OrgID: testOrgID, Path: "/config/app.yaml", Value: "app config"},
+		{OrgID: testOrgID, Path: "/config/database.yaml", Value: "db config"},
+		{OrgID: testOrgID, Path: "/config/auth/ldap.yaml", Value: "ldap config"},
+		{OrgID: testOrgID, Path: "/config/auth/oauth.yaml", Value: "oauth config"},
+		{OrgID: testOrgID, Path: "/data/users.json", Value: "users data"},
+		{OrgID: testOrgID, Path: "/data/logs/app.log", Value: "log data"},
 	}
 
 	var createdMetadata []*domain.Metadata
@@ -376,9 +387,11 @@ func TestMetadataRepository_Delete(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	// Create test metadata
 	req := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  "/config/app.yaml",
 		Value: "database: localhost",
 	}
@@ -438,11 +451,13 @@ func TestMetadataRepository_PathUniqueness(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	path := "/config/app.yaml"
 
 	// Create first metadata
 	req1 := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  path,
 		Value: "first value",
 	}
@@ -451,6 +466,7 @@ func TestMetadataRepository_PathUniqueness(t *testing.T) {
 
 	// Try to create another with same path
 	req2 := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  path,
 		Value: "second value",
 	}
@@ -472,16 +488,18 @@ func TestMetadataRepository_pathExists(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMetadataRepository(db)
+	createTestOrg(t, db)
 
 	path := "/config/app.yaml"
 
 	// Initially should not exist
-	exists, err := repo.pathExists(path)
+	exists, err := repo.pathExists(testOrgID, path)
 	require.NoError(t, err)
 	assert.False(t, exists)
 
 	// Create metadata
 	req := domain.CreateMetadataRequest{
+		OrgID: testOrgID,
 		Path:  path,
 		Value: "test value",
 	}
@@ -489,12 +507,12 @@ func TestMetadataRepository_pathExists(t *testing.T) {
 	require.NoError(t, err)
 
 	// Now should exist
-	exists, err = repo.pathExists(path)
+	exists, err = repo.pathExists(testOrgID, path)
 	require.NoError(t, err)
 	assert.True(t, exists)
 
 	// Different path should not exist
-	exists, err = repo.pathExists("/different/path")
+	exists, err = repo.pathExists(testOrgID, "/different/path")
 	require.NoError(t, err)
 	assert.False(t, exists)
 }
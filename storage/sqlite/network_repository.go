@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// NetworkRepository handles network data operations
+type NetworkRepository struct {
+	db *DB
+}
+
+// NewNetworkRepository creates a new network repository
+func NewNetworkRepository(db *DB) *NetworkRepository {
+	return &NetworkRepository{db: db}
+}
+
+// networkColumns is the column list used by all network queries (matches scanNetwork)
+const networkColumns = `id, project_id, name, cidr, created_at, updated_at`
+
+// scanNetwork scans a network row selected with networkColumns
+func scanNetwork(row rowScanner) (*domain.Network, error) {
+	network := &domain.Network{}
+	if err := row.Scan(&network.ID, &network.ProjectID, &network.Name, &network.CIDR, &network.CreatedAt, &network.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// Create creates a new network
+func (r *NetworkRepository) Create(network *domain.Network) error {
+	now := time.Now()
+	network.CreatedAt = now
+	network.UpdatedAt = now
+
+	query := `INSERT INTO networks (` + networkColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, network.ID, network.ProjectID, network.Name, network.CIDR, network.CreatedAt, network.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: networks.project_id, networks.name") {
+			return domain.AlreadyExistsError("network", "name", network.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("project", "id", network.ProjectID)
+		}
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a network by ID
+func (r *NetworkRepository) GetByID(id string) (*domain.Network, error) {
+	query := `SELECT ` + networkColumns + ` FROM networks WHERE id = ?`
+
+	network, err := scanNetwork(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("network", id)
+		}
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+
+	return network, nil
+}
+
+// ListByProjectID retrieves the networks of a project
+func (r *NetworkRepository) ListByProjectID(projectID string) ([]*domain.Network, error) {
+	query := `SELECT ` + networkColumns + ` FROM networks WHERE project_id = ? ORDER BY name`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	defer rows.Close()
+
+	networks := []*domain.Network{}
+	for rows.Next() {
+		network, err := scanNetwork(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan network: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating networks: %w", err)
+	}
+
+	return networks, nil
+}
+
+// Update updates a network
+func (r *NetworkRepository) Update(id string, req domain.UpdateNetworkRequest) (*domain.Network, error) {
+	existing, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	existing.UpdatedAt = time.Now()
+
+	_, err = r.db.Exec(`UPDATE networks SET name = ?, updated_at = ? WHERE id = ?`, existing.Name, existing.UpdatedAt, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: networks.project_id, networks.name") {
+			return nil, domain.AlreadyExistsError("network", "name", existing.Name)
+		}
+		return nil, fmt.Errorf("failed to update network: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Delete deletes a network by ID
+func (r *NetworkRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM networks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete network: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.NotFoundError("network", id)
+	}
+
+	return nil
+}
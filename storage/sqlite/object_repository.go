@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -20,21 +21,57 @@ func NewObjectRepository(db *DB) *ObjectRepository {
 	return &ObjectRepository{db: db}
 }
 
+// objectColumns is the column list used by all object queries (matches scanObject)
+const objectColumns = `id, bucket_id, path, content, size, content_type, metadata, version, created_at, updated_at`
+
+// scanObject scans an object row selected with objectColumns
+func scanObject(row rowScanner) (*domain.Object, error) {
+	obj := &domain.Object{}
+	var metadata string
+	if err := row.Scan(&obj.ID, &obj.BucketID, &obj.Path, &obj.Content, &obj.Size, &obj.ContentType, &metadata, &obj.Version, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(metadata), &obj.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode object metadata: %w", err)
+	}
+	return obj, nil
+}
+
+// encodeObjectMetadata encodes object metadata for the metadata column
+func encodeObjectMetadata(metadata map[string]string) (string, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode object metadata: %w", err)
+	}
+	return string(encoded), nil
+}
+
 // Create creates a new object (assumes bucket existence validated by service)
 func (r *ObjectRepository) Create(req domain.CreateObjectRequest) (*domain.Object, error) {
 	// Ensure unique path within bucket
 	id := uuid.New().String()
 	now := time.Now()
 	obj := &domain.Object{
-		ID:       id,
-		BucketID: req.BucketID,
-		Path:     req.Path,
-		Content:  req.Content,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:          id,
+		BucketID:    req.BucketID,
+		Path:        req.Path,
+		Content:     req.Content,
+		Size:        domain.ContentSize(req.Content),
+		ContentType: req.ContentType,
+		Metadata:    req.Metadata,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
-	query := `INSERT INTO objects (id, bucket_id, path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := r.db.Exec(query, obj.ID, obj.BucketID, obj.Path, obj.Content, obj.CreatedAt, obj.UpdatedAt)
+	metadata, err := encodeObjectMetadata(obj.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO objects (id, bucket_id, path, content, size, content_type, metadata, version, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query, obj.ID, obj.BucketID, obj.Path, obj.Content, obj.Size, obj.ContentType, metadata, obj.Version, obj.CreatedAt, obj.UpdatedAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: objects.bucket_id, objects.path") {
 			return nil, domain.AlreadyExistsError("object", "path", obj.Path)
@@ -49,9 +86,8 @@ func (r *ObjectRepository) Create(req domain.CreateObjectRequest) (*domain.Objec
 
 // GetByID retrieves an object by ID
 func (r *ObjectRepository) GetByID(id string) (*domain.Object, error) {
-	obj := &domain.Object{}
-	query := `SELECT id, bucket_id, path, content, created_at, updated_at FROM objects WHERE id = ?`
-	err := r.db.QueryRow(query, id).Scan(&obj.ID, &obj.BucketID, &obj.Path, &obj.Content, &obj.CreatedAt, &obj.UpdatedAt)
+	query := `SELECT ` + objectColumns + ` FROM objects WHERE id = ?`
+	obj, err := scanObject(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.NotFoundError("object", id)
@@ -61,28 +97,107 @@ func (r *ObjectRepository) GetByID(id string) (*domain.Object, error) {
 	return obj, nil
 }
 
+// GetByPath retrieves an object by bucket ID and path
+func (r *ObjectRepository) GetByPath(bucketID, path string) (*domain.Object, error) {
+	query := `SELECT ` + objectColumns + ` FROM objects WHERE bucket_id = ? AND path = ?`
+	obj, err := scanObject(r.db.QueryRow(query, bucketID, path))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("object", path)
+		}
+		return nil, fmt.Errorf("failed to get object by path: %w", err)
+	}
+	return obj, nil
+}
+
 // Update updates an existing object
+// When the content changes, the previous content is kept as a noncurrent version
 func (r *ObjectRepository) Update(id string, req domain.UpdateObjectRequest) (*domain.Object, error) {
 	obj, err := r.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	if req.Path != nil {
 		obj.Path = *req.Path
 	}
-	if req.Content != nil {
+	if req.Content != nil && *req.Content != obj.Content {
+		archive := `INSERT INTO object_versions (id, object_id, version, content, size, created_at, archived_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(archive, uuid.New().String(), obj.ID, obj.Version, obj.Content, obj.Size, obj.UpdatedAt, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to archive object version: %w", err)
+		}
 		obj.Content = *req.Content
+		obj.Size = domain.ContentSize(obj.Content)
+		obj.Version++
+	}
+	if req.ContentType != nil {
+		obj.ContentType = *req.ContentType
+	}
+	if req.Metadata != nil {
+		obj.Metadata = *req.Metadata
+	}
+	metadata, err := encodeObjectMetadata(obj.Metadata)
+	if err != nil {
+		return nil, err
 	}
 	obj.UpdatedAt = time.Now()
 
-	query := `UPDATE objects SET path = ?, content = ?, updated_at = ? WHERE id = ?`
-	_, err = r.db.Exec(query, obj.Path, obj.Content, obj.UpdatedAt, id)
+	query := `UPDATE objects SET path = ?, content = ?, size = ?, content_type = ?, metadata = ?, version = ?, updated_at = ? WHERE id = ?`
+	_, err = tx.Exec(query, obj.Path, obj.Content, obj.Size, obj.ContentType, metadata, obj.Version, obj.UpdatedAt, id)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: objects.bucket_id, objects.path") {
 			return nil, domain.AlreadyExistsError("object", "path", obj.Path)
 		}
 		return nil, fmt.Errorf("failed to update object: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit object update: %w", err)
+	}
+	return obj, nil
+}
+
+// Move moves an object to another bucket and/or path, keeping its ID and version history
+// If overwrite is set, an object already at the destination is deleted first
+func (r *ObjectRepository) Move(id, bucketID, path string, overwrite bool) (*domain.Object, error) {
+	obj, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if overwrite {
+		if _, err := tx.Exec(`DELETE FROM objects WHERE bucket_id = ? AND path = ? AND id != ?`, bucketID, path, id); err != nil {
+			return nil, fmt.Errorf("failed to delete destination object: %w", err)
+		}
+	}
+
+	obj.BucketID = bucketID
+	obj.Path = path
+	obj.UpdatedAt = time.Now()
+	query := `UPDATE objects SET bucket_id = ?, path = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.Exec(query, obj.BucketID, obj.Path, obj.UpdatedAt, id); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: objects.bucket_id, objects.path") {
+			return nil, domain.AlreadyExistsError("object", "path", obj.Path)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return nil, domain.ForeignKeyViolationError("bucket", "id", obj.BucketID)
+		}
+		return nil, fmt.Errorf("failed to move object: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit object move: %w", err)
+	}
 	return obj, nil
 }
 
@@ -92,7 +207,7 @@ func (r *ObjectRepository) List(opts domain.ObjectListOptions) ([]*domain.Object
 		objects []*domain.Object
 		args    []interface{}
 	)
-	query := `SELECT id, bucket_id, path, content, created_at, updated_at FROM objects`
+	query := `SELECT ` + objectColumns + ` FROM objects`
 	var conditions []string
 	if opts.BucketID != "" {
 		conditions = append(conditions, "bucket_id = ?")
@@ -112,8 +227,8 @@ func (r *ObjectRepository) List(opts domain.ObjectListOptions) ([]*domain.Object
 	}
 	defer rows.Close()
 	for rows.Next() {
-		o := &domain.Object{}
-		if err := rows.Scan(&o.ID, &o.BucketID, &o.Path, &o.Content, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		o, err := scanObject(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan object: %w", err)
 		}
 		objects = append(objects, o)
@@ -137,3 +252,31 @@ func (r *ObjectRepository) Delete(id string) error {
 	}
 	return nil
 }
+
+// NoncurrentSize returns the bytes stored in the noncurrent versions of an object
+func (r *ObjectRepository) NoncurrentSize(id string) (int64, error) {
+	var size int64
+	query := `SELECT COALESCE(SUM(size), 0) FROM object_versions WHERE object_id = ?`
+	if err := r.db.QueryRow(query, id).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get noncurrent object size: %w", err)
+	}
+	return size, nil
+}
+
+// DeleteNoncurrentVersions removes noncurrent versions of objects in a bucket
+// whose path starts with prefix and that were replaced before the given time
+// The prefix is matched exactly: unlike LIKE, substr is case-sensitive and has no wildcards
+func (r *ObjectRepository) DeleteNoncurrentVersions(bucketID, prefix string, archivedBefore time.Time) (int, error) {
+	query := `DELETE FROM object_versions WHERE archived_at < ? AND object_id IN (
+		SELECT id FROM objects WHERE bucket_id = ? AND substr(path, 1, length(?)) = ?
+	)`
+	result, err := r.db.Exec(query, archivedBefore, bucketID, prefix, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete noncurrent object versions: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return int(n), nil
+}
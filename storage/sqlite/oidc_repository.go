@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// OIDCTrustPolicyRepository handles OIDC trust policy data operations
+type OIDCTrustPolicyRepository struct {
+	db *DB
+}
+
+// NewOIDCTrustPolicyRepository creates a new OIDC trust policy repository
+func NewOIDCTrustPolicyRepository(db *DB) *OIDCTrustPolicyRepository {
+	return &OIDCTrustPolicyRepository{db: db}
+}
+
+// oidcPolicyColumns is the column list used by all OIDC trust policy queries (matches scanOIDCPolicy)
+const oidcPolicyColumns = `id, org_id, name, issuer, claims, scopes, project_ids, token_ttl_seconds, created_at`
+
+// scanOIDCPolicy scans an OIDC trust policy row selected with oidcPolicyColumns
+func scanOIDCPolicy(row rowScanner) (*domain.OIDCTrustPolicy, error) {
+	policy := &domain.OIDCTrustPolicy{}
+	var claims, scopes, projectIDs string
+	if err := row.Scan(&policy.ID, &policy.OrgID, &policy.Name, &policy.Issuer, &claims, &scopes, &projectIDs, &policy.TokenTTLSeconds, &policy.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(claims), &policy.Claims); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC policy claims: %w", err)
+	}
+	if err := json.Unmarshal([]byte(scopes), &policy.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC policy scopes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(projectIDs), &policy.ProjectIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC policy projects: %w", err)
+	}
+	return policy, nil
+}
+
+// Create creates a new OIDC trust policy
+func (r *OIDCTrustPolicyRepository) Create(policy *domain.OIDCTrustPolicy) error {
+	policy.CreatedAt = time.Now()
+
+	claims, err := json.Marshal(policy.Claims)
+	if err != nil {
+		return fmt.Errorf("failed to encode OIDC policy claims: %w", err)
+	}
+	scopes, err := json.Marshal(append([]string{}, policy.Scopes...))
+	if err != nil {
+		return fmt.Errorf("failed to encode OIDC policy scopes: %w", err)
+	}
+	projectIDs, err := json.Marshal(append([]string{}, policy.ProjectIDs...))
+	if err != nil {
+		return fmt.Errorf("failed to encode OIDC policy projects: %w", err)
+	}
+
+	query := `INSERT INTO oidc_trust_policies (` + oidcPolicyColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query, policy.ID, policy.OrgID, policy.Name, policy.Issuer, string(claims), string(scopes), string(projectIDs), policy.TokenTTLSeconds, policy.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: oidc_trust_policies.org_id, oidc_trust_policies.name") {
+			return domain.AlreadyExistsError("oidc_trust_policy", "name", policy.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("organization", "id", policy.OrgID)
+		}
+		return fmt.Errorf("failed to create OIDC trust policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an OIDC trust policy by ID
+func (r *OIDCTrustPolicyRepository) GetByID(id string) (*domain.OIDCTrustPolicy, error) {
+	query := `SELECT ` + oidcPolicyColumns + ` FROM oidc_trust_policies WHERE id = ?`
+
+	policy, err := scanOIDCPolicy(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("oidc_trust_policy", id)
+		}
+		return nil, fmt.Errorf("failed to get OIDC trust policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ListByOrgID retrieves the OIDC trust policies of an organization, oldest first
+func (r *OIDCTrustPolicyRepository) ListByOrgID(orgID string) ([]*domain.OIDCTrustPolicy, error) {
+	query := `SELECT ` + oidcPolicyColumns + ` FROM oidc_trust_policies WHERE org_id = ? ORDER BY created_at, name`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OIDC trust policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*domain.OIDCTrustPolicy{}
+	for rows.Next() {
+		policy, err := scanOIDCPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan OIDC trust policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating OIDC trust policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Delete deletes an OIDC trust policy by ID
+func (r *OIDCTrustPolicyRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM oidc_trust_policies WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete OIDC trust policy: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.NotFoundError("oidc_trust_policy", id)
+	}
+
+	return nil
+}
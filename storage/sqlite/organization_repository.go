@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -186,3 +187,44 @@ func (r *OrganizationRepository) Delete(id string) error {
 
 	return nil
 }
+
+// GetResourceQuota returns the resource quota of an organization, or nil if it uses the server default
+func (r *OrganizationRepository) GetResourceQuota(id string) (*domain.ResourceQuota, error) {
+	var data sql.NullString
+	if err := r.db.QueryRow(`SELECT resource_quota FROM organizations WHERE id = ?`, id).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("organization", id)
+		}
+		return nil, fmt.Errorf("failed to get organization resource quota: %w", err)
+	}
+	if !data.Valid {
+		return nil, nil
+	}
+	quota := &domain.ResourceQuota{}
+	if err := json.Unmarshal([]byte(data.String), quota); err != nil {
+		return nil, fmt.Errorf("failed to decode organization resource quota: %w", err)
+	}
+	return quota, nil
+}
+
+// UpdateResourceQuota replaces the resource quota of an organization (nil reverts to the server default)
+func (r *OrganizationRepository) UpdateResourceQuota(id string, quota *domain.ResourceQuota) error {
+	var data sql.NullString
+	if quota != nil {
+		encoded, err := json.Marshal(quota)
+		if err != nil {
+			return fmt.Errorf("failed to encode organization resource quota: %w", err)
+		}
+		data = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	query := `UPDATE organizations SET resource_quota = ?, updated_at = ? WHERE id = ?`
+	result, err := r.db.Exec(query, data, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update organization resource quota: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.NotFoundError("organization", id)
+	}
+	return nil
+}
@@ -191,6 +191,32 @@ func (r *ProjectRepository) Update(id string, req domain.UpdateProjectRequest) (
 	return existing, nil
 }
 
+// GetStorageQuota returns the storage quota shared by all buckets of a project
+func (r *ProjectRepository) GetStorageQuota(id string) (*domain.StorageQuota, error) {
+	quota := &domain.StorageQuota{}
+	query := `SELECT storage_max_bytes, storage_max_objects FROM projects WHERE id = ?`
+	if err := r.db.QueryRow(query, id).Scan(&quota.MaxBytes, &quota.MaxObjects); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("project", id)
+		}
+		return nil, fmt.Errorf("failed to get project storage quota: %w", err)
+	}
+	return quota, nil
+}
+
+// UpdateStorageQuota replaces the storage quota of a project
+func (r *ProjectRepository) UpdateStorageQuota(id string, quota domain.StorageQuota) error {
+	query := `UPDATE projects SET storage_max_bytes = ?, storage_max_objects = ?, updated_at = ? WHERE id = ?`
+	result, err := r.db.Exec(query, quota.MaxBytes, quota.MaxObjects, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update project storage quota: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.NotFoundError("project", id)
+	}
+	return nil
+}
+
 // Delete deletes a project by ID
 func (r *ProjectRepository) Delete(id string) error {
 	// First check if project exists
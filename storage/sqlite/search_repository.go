@@ -0,0 +1,288 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// searchSource describes how rows of a table appear in search results
+// Expressions refer to the table as t and to its project (if joined) as p
+type searchSource struct {
+	table       string
+	kind        string
+	name        string // searched and displayed: the name or path of the resource
+	slug        string // also searched (project slugs)
+	orgID       string
+	projectID   string
+	projectSlug string
+	bucketID    string
+	joins       string
+	watch       string // columns whose update changes the entry
+}
+
+var searchSources = []searchSource{
+	{
+		table: "projects", kind: domain.ResourceKindProject,
+		name: "t.name", slug: "t.slug", orgID: "t.org_id", projectID: "t.id", projectSlug: "t.slug", bucketID: "''",
+		watch: "name, slug",
+	},
+	{
+		table: "instances", kind: domain.ResourceKindInstance,
+		name: "t.name", slug: "''", orgID: "p.org_id", projectID: "t.project_id", projectSlug: "p.slug", bucketID: "''",
+		joins: "JOIN projects p ON p.id = t.project_id",
+		watch: "name",
+	},
+	{
+		table: "metadata", kind: domain.ResourceKindMetadata,
+		name: "t.path", slug: "''", orgID: "t.org_id", projectID: "''", projectSlug: "''", bucketID: "''",
+		watch: "path",
+	},
+	{
+		table: "buckets", kind: domain.ResourceKindBucket,
+		name: "t.name", slug: "''", orgID: "p.org_id", projectID: "t.project_id", projectSlug: "p.slug", bucketID: "t.id",
+		joins: "JOIN projects p ON p.id = t.project_id",
+		watch: "name",
+	},
+	{
+		table: "objects", kind: domain.ResourceKindObject,
+		name: "t.path", slug: "''", orgID: "p.org_id", projectID: "b.project_id", projectSlug: "p.slug", bucketID: "t.bucket_id",
+		joins: "JOIN buckets b ON b.id = t.bucket_id JOIN projects p ON p.id = b.project_id",
+		watch: "path, bucket_id",
+	},
+}
+
+// indexSelect selects the search_index entries of the source table
+func (s searchSource) indexSelect() string {
+	return fmt.Sprintf(`SELECT %s, %s, '%s', t.id, %s, %s, %s FROM %s t %s`,
+		s.name, s.slug, s.kind, s.orgID, s.projectID, s.bucketID, s.table, s.joins)
+}
+
+// triggers returns the statements that keep search_index in sync with the source table
+// Triggers also fire for rows removed by ON DELETE CASCADE, so deleting a project drops
+// the entries of its instances, buckets and objects too
+func (s searchSource) triggers() []string {
+	insert := `INSERT INTO search_index (name, slug, kind, resource_id, org_id, project_id, bucket_id) ` + s.indexSelect() + ` WHERE t.id = NEW.id`
+	remove := fmt.Sprintf(`DELETE FROM search_index WHERE kind = '%s' AND resource_id = OLD.id`, s.kind)
+	return []string{
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%s_insert AFTER INSERT ON %s BEGIN %s; END`, s.table, s.table, insert),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%s_update AFTER UPDATE OF %s ON %s BEGIN %s; %s; END`, s.table, s.watch, s.table, remove, insert),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%s_delete AFTER DELETE ON %s BEGIN %s; END`, s.table, s.table, remove),
+	}
+}
+
+// initSearchIndex creates the search_index FTS5 table and the triggers that maintain it
+// SQLite is only built with FTS5 with the sqlite_fts5 build tag; without it the triggers
+// are dropped (a database may have been created by a build with FTS5) and searches scan
+// the resource tables instead
+func (db *DB) initSearchIndex() error {
+	var fts5 int
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		return fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+	db.fts5 = fts5 == 1
+
+	if !db.fts5 {
+		for _, s := range searchSources {
+			for _, action := range []string{"insert", "update", "delete"} {
+				if _, err := db.Exec(fmt.Sprintf(`DROP TRIGGER IF EXISTS search_%s_%s`, s.table, action)); err != nil {
+					return fmt.Errorf("failed to drop search trigger: %w", err)
+				}
+			}
+		}
+		return nil
+	}
+
+	// The trigram tokenizer matches any substring of at least three characters
+	if _, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		name, slug,
+		kind UNINDEXED, resource_id UNINDEXED, org_id UNINDEXED, project_id UNINDEXED, bucket_id UNINDEXED,
+		tokenize = 'trigram'
+	)`); err != nil {
+		return fmt.Errorf("failed to create search_index table: %w", err)
+	}
+
+	// The index is current as long as all of its triggers exist. If any is missing (a new
+	// table, or the database was written by a build without FTS5) rebuild it from scratch
+	var triggers int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'search\_%' ESCAPE '\'`).Scan(&triggers); err != nil {
+		return fmt.Errorf("failed to count search triggers: %w", err)
+	}
+	if triggers == 3*len(searchSources) {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM search_index`); err != nil {
+		return fmt.Errorf("failed to clear search_index: %w", err)
+	}
+	for _, s := range searchSources {
+		if _, err := tx.Exec(`INSERT INTO search_index (name, slug, kind, resource_id, org_id, project_id, bucket_id) ` + s.indexSelect()); err != nil {
+			return fmt.Errorf("failed to index %s: %w", s.table, err)
+		}
+		for _, trigger := range s.triggers() {
+			if _, err := tx.Exec(trigger); err != nil {
+				return fmt.Errorf("failed to create search trigger on %s: %w", s.table, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchRepository handles searches and listings across the resources of an org
+type SearchRepository struct {
+	db *DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search returns the resources of an org whose name, path or slug contains the query
+// (case-insensitively), exact and prefix matches first
+func (r *SearchRepository) Search(opts domain.SearchOptions) ([]*domain.SearchResult, error) {
+	var source string
+	var args []interface{}
+	like := "%" + escapeLike(opts.Query) + "%"
+
+	switch {
+	case r.db.fts5 && utf8.RuneCountInString(opts.Query) >= 3:
+		// The query is a single FTS5 phrase, which the trigram tokenizer matches as a substring
+		source = `SELECT s.kind, s.resource_id AS id, s.name, s.project_id, COALESCE(p.slug, '') AS project_slug, s.bucket_id
+			FROM search_index s LEFT JOIN projects p ON p.id = s.project_id
+			WHERE search_index MATCH ? AND s.org_id = ?`
+		args = append(args, `"`+strings.ReplaceAll(opts.Query, `"`, `""`)+`"`, opts.OrgID)
+	case r.db.fts5:
+		// Too short for trigrams, scan the index
+		source = `SELECT s.kind, s.resource_id AS id, s.name, s.project_id, COALESCE(p.slug, '') AS project_slug, s.bucket_id
+			FROM search_index s LEFT JOIN projects p ON p.id = s.project_id
+			WHERE s.org_id = ? AND (s.name LIKE ? ESCAPE '\' OR s.slug LIKE ? ESCAPE '\')`
+		args = append(args, opts.OrgID, like, like)
+	default:
+		var selects []string
+		for _, s := range searchSources {
+			selects = append(selects, fmt.Sprintf(`SELECT '%s' AS kind, t.id AS id, %s AS name, %s AS project_id, %s AS project_slug, %s AS bucket_id
+				FROM %s t %s WHERE %s = ? AND (%s LIKE ? ESCAPE '\' OR %s LIKE ? ESCAPE '\')`,
+				s.kind, s.name, s.projectID, s.projectSlug, s.bucketID, s.table, s.joins, s.orgID, s.name, s.slug))
+			args = append(args, opts.OrgID, like, like)
+		}
+		source = strings.Join(selects, " UNION ALL ")
+	}
+
+	query := `SELECT kind, id, name, project_id, project_slug, bucket_id FROM (` + source + `)`
+	// Filtered before the limit, so results the caller cannot see don't take up the page
+	conditions, accessArgs := accessConditions(opts)
+	args = append(args, accessArgs...)
+	if opts.Kind != "" {
+		conditions = append(conditions, `kind = ?`)
+		args = append(args, opts.Kind)
+	}
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY CASE WHEN name = ? COLLATE NOCASE THEN 0 WHEN name LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, name, kind`
+	args = append(args, opts.Query, escapeLike(opts.Query)+"%")
+	if opts.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*domain.SearchResult{}
+	for rows.Next() {
+		result := &domain.SearchResult{}
+		if err := rows.Scan(&result.Kind, &result.ID, &result.Name, &result.ProjectID, &result.ProjectSlug, &result.BucketID); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// accessConditions returns the conditions on the kind and project_id columns of search
+// results that limit them to the kinds and projects of opts
+func accessConditions(opts domain.SearchOptions) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if opts.Kinds != nil {
+		conditions = append(conditions, `kind IN (`+placeholders(len(opts.Kinds))+`)`)
+		for _, kind := range opts.Kinds {
+			args = append(args, kind)
+		}
+	}
+	if opts.ProjectIDs != nil {
+		conditions = append(conditions, `(project_id = '' OR project_id IN (`+placeholders(len(opts.ProjectIDs))+`))`)
+		for _, id := range opts.ProjectIDs {
+			args = append(args, id)
+		}
+	}
+	return conditions, args
+}
+
+// placeholders returns n comma-separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Recent returns the most recently changed resources of an org, newest first
+// Only the OrgID, Limit, Kinds and ProjectIDs of opts are used
+func (r *SearchRepository) Recent(opts domain.SearchOptions) ([]*domain.RecentResource, error) {
+	var selects []string
+	var args []interface{}
+	for _, s := range searchSources {
+		selects = append(selects, fmt.Sprintf(`SELECT '%s' AS kind, t.id AS id, %s AS name, %s AS project_id, %s AS project_slug, %s AS bucket_id, t.updated_at AS updated_at
+			FROM %s t %s WHERE %s = ?`,
+			s.kind, s.name, s.projectID, s.projectSlug, s.bucketID, s.table, s.joins, s.orgID))
+		args = append(args, opts.OrgID)
+	}
+	query := `SELECT kind, id, name, project_id, project_slug, bucket_id, updated_at FROM (` + strings.Join(selects, " UNION ALL ") + `)`
+	conditions, accessArgs := accessConditions(opts)
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+		args = append(args, accessArgs...)
+	}
+	query += ` ORDER BY updated_at DESC, kind, name LIMIT ?`
+	args = append(args, opts.Limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := []*domain.RecentResource{}
+	for rows.Next() {
+		resource := &domain.RecentResource{}
+		if err := rows.Scan(&resource.Kind, &resource.ID, &resource.Name, &resource.ProjectID, &resource.ProjectSlug, &resource.BucketID, &resource.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent resource: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recent resources: %w", err)
+	}
+
+	return resources, nil
+}
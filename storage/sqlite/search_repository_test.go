@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchNames returns the kind and name of each search result
+func searchNames(t *testing.T, repo *SearchRepository, opts domain.SearchOptions) []string {
+	t.Helper()
+
+	if opts.OrgID == "" {
+		opts.OrgID = testOrgID
+	}
+	results, err := repo.Search(opts)
+	require.NoError(t, err)
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Kind+":"+result.Name)
+	}
+	return names
+}
+
+func TestSearchRepository_Search(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	createTestOrg(t, db)
+
+	projects := NewProjectRepository(db)
+	require.NoError(t, projects.Create(&domain.Project{ID: "p1", OrgID: testOrgID, Slug: "web-frontend", Name: "Frontend"}))
+	require.NoError(t, NewInstanceRepository(db).Create(&domain.Instance{ID: "i1", ProjectID: "p1", Name: "web-42", CPU: 1, MemoryMB: 512, Image: "ubuntu"}))
+	require.NoError(t, NewInstanceRepository(db).Create(&domain.Instance{ID: "i2", ProjectID: "p1", Name: "db-1", CPU: 1, MemoryMB: 512, Image: "ubuntu"}))
+	require.NoError(t, NewBucketRepository(db).Create(&domain.Bucket{ID: "web-assets", ProjectID: "p1", Name: "web-assets"}))
+	_, err := NewObjectRepository(db).Create(domain.CreateObjectRequest{BucketID: "web-assets", Path: "css/100%_width.css"})
+	require.NoError(t, err)
+	_, err = NewMetadataRepository(db).Create(domain.CreateMetadataRequest{OrgID: testOrgID, Path: "/config/WEB.yaml"})
+	require.NoError(t, err)
+
+	// Resources of other orgs are never returned
+	require.NoError(t, NewOrganizationRepository(db).Create(&domain.Organization{ID: "other-org", Slug: "other-org", Name: "Other"}))
+	require.NoError(t, projects.Create(&domain.Project{ID: "p2", OrgID: "other-org", Slug: "web", Name: "Web"}))
+
+	repo := NewSearchRepository(db)
+
+	tests := []struct {
+		name     string
+		opts     domain.SearchOptions
+		expected []string
+	}{
+		{
+			name:     "substring across kinds, case-insensitive",
+			opts:     domain.SearchOptions{Query: "web"},
+			expected: []string{"instance:web-42", "bucket:web-assets", "metadata:/config/WEB.yaml", "project:Frontend"},
+		},
+		{
+			name:     "exact match first",
+			opts:     domain.SearchOptions{Query: "web-42"},
+			expected: []string{"instance:web-42"},
+		},
+		{
+			name:     "filter by kind",
+			opts:     domain.SearchOptions{Query: "web", Kind: domain.ResourceKindInstance},
+			expected: []string{"instance:web-42"},
+		},
+		{
+			name:     "short query",
+			opts:     domain.SearchOptions{Query: "db"},
+			expected: []string{"instance:db-1"},
+		},
+		{
+			name:     "wildcards are literal",
+			opts:     domain.SearchOptions{Query: "0%_w"},
+			expected: []string{"object:css/100%_width.css"},
+		},
+		{
+			name:     "limit",
+			opts:     domain.SearchOptions{Query: "web", Limit: 2},
+			expected: []string{"instance:web-42", "bucket:web-assets"},
+		},
+		{
+			name:     "no match",
+			opts:     domain.SearchOptions{Query: "nothing"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, searchNames(t, repo, tt.opts))
+		})
+	}
+
+	results, err := repo.Search(domain.SearchOptions{OrgID: testOrgID, Query: "100%"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "p1", results[0].ProjectID)
+	assert.Equal(t, "web-frontend", results[0].ProjectSlug)
+	assert.Equal(t, "web-assets", results[0].BucketID)
+}
+
+func TestSearchRepository_FollowsChanges(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	createTestOrg(t, db)
+
+	repo := NewSearchRepository(db)
+	instances := NewInstanceRepository(db)
+	buckets := NewBucketRepository(db)
+	objects := NewObjectRepository(db)
+
+	require.NoError(t, NewProjectRepository(db).Create(&domain.Project{ID: "p1", OrgID: testOrgID, Slug: "proj", Name: "Project"}))
+	require.NoError(t, instances.Create(&domain.Instance{ID: "i1", ProjectID: "p1", Name: "old-name", CPU: 1, MemoryMB: 512, Image: "ubuntu"}))
+	require.NoError(t, buckets.Create(&domain.Bucket{ID: "logs", ProjectID: "p1", Name: "logs"}))
+	obj, err := objects.Create(domain.CreateObjectRequest{BucketID: "logs", Path: "2024/app.log"})
+	require.NoError(t, err)
+
+	// Updates
+	name := "new-name"
+	_, err = instances.Update("i1", domain.UpdateInstanceRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Empty(t, searchNames(t, repo, domain.SearchOptions{Query: "old-name"}))
+	assert.Equal(t, []string{"instance:new-name"}, searchNames(t, repo, domain.SearchOptions{Query: "new-name"}))
+
+	// Renaming a bucket moves its objects
+	_, err = buckets.Rename("logs", "archive")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket:archive"}, searchNames(t, repo, domain.SearchOptions{Query: "archive"}))
+	results, err := repo.Search(domain.SearchOptions{OrgID: testOrgID, Query: "app.log"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, obj.ID, results[0].ID)
+	assert.Equal(t, "archive", results[0].BucketID)
+
+	// Deleting a bucket drops its objects too
+	require.NoError(t, instances.Delete("i1"))
+	require.NoError(t, buckets.Delete("archive"))
+	for _, query := range []string{"new-name", "archive", "app.log"} {
+		assert.Empty(t, searchNames(t, repo, domain.SearchOptions{Query: query}), query)
+	}
+	assert.Equal(t, []string{"project:Project"}, searchNames(t, repo, domain.SearchOptions{Query: "proj"}))
+}
+
+func TestSearchRepository_Recent(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	createTestOrg(t, db)
+
+	projects := NewProjectRepository(db)
+	require.NoError(t, projects.Create(&domain.Project{ID: "p1", OrgID: testOrgID, Slug: "proj", Name: "Project"}))
+	require.NoError(t, NewInstanceRepository(db).Create(&domain.Instance{ID: "i1", ProjectID: "p1", Name: "web-1", CPU: 1, MemoryMB: 512, Image: "ubuntu"}))
+	require.NoError(t, NewBucketRepository(db).Create(&domain.Bucket{ID: "logs", ProjectID: "p1", Name: "logs"}))
+	name := "Renamed"
+	_, err := projects.Update("p1", domain.UpdateProjectRequest{Name: &name})
+	require.NoError(t, err)
+
+	repo := NewSearchRepository(db)
+	recent, err := repo.Recent(domain.SearchOptions{OrgID: testOrgID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, "project:Renamed", recent[0].Kind+":"+recent[0].Name)
+	assert.Equal(t, "bucket:logs", recent[1].Kind+":"+recent[1].Name)
+	assert.Equal(t, "proj", recent[1].ProjectSlug)
+	assert.False(t, recent[1].UpdatedAt.After(recent[0].UpdatedAt))
+
+	recent, err = repo.Recent(domain.SearchOptions{OrgID: "other-org", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+	// Kinds and projects are limited before the limit applies
+	require.NoError(t, projects.Create(&domain.Project{ID: "p2", OrgID: testOrgID, Slug: "other", Name: "Other"}))
+	recent, err = repo.Recent(domain.SearchOptions{OrgID: testOrgID, Limit: 1, Kinds: []string{domain.ResourceKindInstance}})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "instance:web-1", recent[0].Kind+":"+recent[0].Name)
+	recent, err = repo.Recent(domain.SearchOptions{OrgID: testOrgID, Limit: 10, Kinds: []string{domain.ResourceKindProject}, ProjectIDs: []string{"p1"}})
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "p1", recent[0].ID)
+	recent, err = repo.Recent(domain.SearchOptions{OrgID: testOrgID, Limit: 10, Kinds: []string{}})
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// SubnetRepository handles subnet data operations
+type SubnetRepository struct {
+	db *DB
+}
+
+// NewSubnetRepository creates a new subnet repository
+func NewSubnetRepository(db *DB) *SubnetRepository {
+	return &SubnetRepository{db: db}
+}
+
+// subnetColumns is the column list used by all subnet queries (matches scanSubnet)
+const subnetColumns = `id, project_id, network_id, name, region, cidr, gateway_ip, created_at, updated_at`
+
+// scanSubnet scans a subnet row selected with subnetColumns
+func scanSubnet(row rowScanner) (*domain.Subnet, error) {
+	subnet := &domain.Subnet{}
+	if err := row.Scan(&subnet.ID, &subnet.ProjectID, &subnet.NetworkID, &subnet.Name, &subnet.Region, &subnet.CIDR, &subnet.GatewayIP, &subnet.CreatedAt, &subnet.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return subnet, nil
+}
+
+// Create creates a new subnet
+func (r *SubnetRepository) Create(subnet *domain.Subnet) error {
+	now := time.Now()
+	subnet.CreatedAt = now
+	subnet.UpdatedAt = now
+
+	query := `INSERT INTO subnets (` + subnetColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, subnet.ID, subnet.ProjectID, subnet.NetworkID, subnet.Name, subnet.Region, subnet.CIDR, subnet.GatewayIP, subnet.CreatedAt, subnet.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: subnets.project_id, subnets.name") {
+			return domain.AlreadyExistsError("subnet", "name", subnet.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("network", "id", subnet.NetworkID)
+		}
+		return fmt.Errorf("failed to create subnet: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a subnet by ID
+func (r *SubnetRepository) GetByID(id string) (*domain.Subnet, error) {
+	query := `SELECT ` + subnetColumns + ` FROM subnets WHERE id = ?`
+
+	subnet, err := scanSubnet(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("subnet", id)
+		}
+		return nil, fmt.Errorf("failed to get subnet: %w", err)
+	}
+
+	return subnet, nil
+}
+
+// List retrieves the subnets of a project, optionally of one network
+func (r *SubnetRepository) List(opts domain.SubnetListOptions) ([]*domain.Subnet, error) {
+	query := `SELECT ` + subnetColumns + ` FROM subnets WHERE project_id = ?`
+	args := []interface{}{opts.ProjectID}
+	if opts.NetworkID != "" {
+		query += ` AND network_id = ?`
+		args = append(args, opts.NetworkID)
+	}
+	query += ` ORDER BY name`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subnets: %w", err)
+	}
+	defer rows.Close()
+
+	subnets := []*domain.Subnet{}
+	for rows.Next() {
+		subnet, err := scanSubnet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subnet: %w", err)
+		}
+		subnets = append(subnets, subnet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subnets: %w", err)
+	}
+
+	return subnets, nil
+}
+
+// Update updates a subnet
+func (r *SubnetRepository) Update(id string, req domain.UpdateSubnetRequest) (*domain.Subnet, error) {
+	existing, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	existing.UpdatedAt = time.Now()
+
+	_, err = r.db.Exec(`UPDATE subnets SET name = ?, updated_at = ? WHERE id = ?`, existing.Name, existing.UpdatedAt, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: subnets.project_id, subnets.name") {
+			return nil, domain.AlreadyExistsError("subnet", "name", existing.Name)
+		}
+		return nil, fmt.Errorf("failed to update subnet: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Delete deletes a subnet by ID
+func (r *SubnetRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM subnets WHERE id = ?`, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed: instances.subnet_id") {
+			return domain.ConflictError("subnet has instances", map[string]interface{}{"subnet_id": id})
+		}
+		return fmt.Errorf("failed to delete subnet: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		return domain.NotFoundError("subnet", id)
+	}
+
+	return nil
+}
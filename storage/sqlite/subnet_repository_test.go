@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubnetRepository_InstanceReferences(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	createTestOrg(t, db)
+
+	require.NoError(t, NewProjectRepository(db).Create(&domain.Project{ID: "p1", OrgID: testOrgID, Slug: "web", Name: "Web"}))
+	require.NoError(t, NewNetworkRepository(db).Create(&domain.Network{ID: "n1", ProjectID: "p1", Name: "vpc", CIDR: "10.0.0.0/16"}))
+	repo := NewSubnetRepository(db)
+	require.NoError(t, repo.Create(&domain.Subnet{ID: "s1", ProjectID: "p1", NetworkID: "n1", Name: "a", Region: "us-east-1", CIDR: "10.0.1.0/24", GatewayIP: "10.0.1.1"}))
+	instances := NewInstanceRepository(db)
+	instance := func(id, subnetID string) *domain.Instance {
+		return &domain.Instance{ID: id, ProjectID: "p1", Name: id, Region: "us-east-1", SubnetID: subnetID, CPU: 1, MemoryMB: 512, Image: "ubuntu-24.04", Status: domain.StatusRunning}
+	}
+
+	// instances.subnet_id behaves as a foreign key, and '' means no subnet
+	err := instances.Create(instance("i1", "missing"))
+	assert.True(t, domain.IsForeignKeyViolation(err), "expected foreign key violation, got %v", err)
+	require.NoError(t, instances.Create(instance("i1", "s1")))
+	require.NoError(t, instances.Create(instance("i2", "")))
+
+	err = repo.Delete("s1")
+	assert.True(t, domain.IsConflict(err), "expected conflict, got %v", err)
+	_, err = repo.GetByID("s1")
+	require.NoError(t, err)
+
+	require.NoError(t, instances.Delete("i1"))
+	require.NoError(t, repo.Delete("s1"))
+	_, err = repo.GetByID("s1")
+	assert.True(t, domain.IsNotFound(err), "expected not found, got %v", err)
+}
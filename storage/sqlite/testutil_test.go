@@ -3,6 +3,7 @@ package sqlite
 import (
 	"testing"
 
+	"github.com/hypertf/nahcloud/domain"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +18,17 @@ func setupTestDB(t *testing.T) *DB {
 	return db
 }
 
+// testOrgID is the ID of the organization created by createTestOrg
+const testOrgID = "test-org"
+
+// createTestOrg creates the organization that org-scoped test data belongs to
+func createTestOrg(t *testing.T, db *DB) {
+	t.Helper()
+
+	err := NewOrganizationRepository(db).Create(&domain.Organization{ID: testOrgID, Slug: testOrgID, Name: "Test Org"})
+	require.NoError(t, err, "Failed to create test organization")
+}
+
 // setupTestDBWithData creates a test database and populates it with sample data
 func setupTestDBWithData(t *testing.T) (*DB, map[string]interface{}) {
 	t.Helper()
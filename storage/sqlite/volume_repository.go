@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hypertf/nahcloud/domain"
+)
+
+// VolumeRepository handles volume data operations
+type VolumeRepository struct {
+	db *DB
+}
+
+// NewVolumeRepository creates a new volume repository
+func NewVolumeRepository(db *DB) *VolumeRepository {
+	return &VolumeRepository{db: db}
+}
+
+// volumeColumns is the column list used by all volume queries (matches scanVolume)
+const volumeColumns = `id, project_id, name, size_gb, type, region, zone, COALESCE(instance_id, ''), created_at, updated_at`
+
+// scanVolume scans a volume row selected with volumeColumns
+func scanVolume(row rowScanner) (*domain.Volume, error) {
+	volume := &domain.Volume{}
+	if err := row.Scan(&volume.ID, &volume.ProjectID, &volume.Name, &volume.SizeGB, &volume.Type, &volume.Region, &volume.Zone,
+		&volume.InstanceID, &volume.CreatedAt, &volume.UpdatedAt); err != nil {
+		return nil, err
+	}
+	setVolumeStatus(volume)
+	return volume, nil
+}
+
+// setVolumeStatus sets the status of a volume from the instance it is attached to
+func setVolumeStatus(volume *domain.Volume) {
+	volume.Status = domain.VolumeStatusAvailable
+	if volume.InstanceID != "" {
+		volume.Status = domain.VolumeStatusInUse
+	}
+}
+
+// Create creates a new volume, which is not attached to an instance
+func (r *VolumeRepository) Create(volume *domain.Volume) error {
+	now := time.Now()
+	volume.CreatedAt = now
+	volume.UpdatedAt = now
+
+	volume.InstanceID = ""
+
+	query := `INSERT INTO volumes (id, project_id, name, size_gb, type, region, zone, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, volume.ID, volume.ProjectID, volume.Name, volume.SizeGB, volume.Type, volume.Region, volume.Zone, volume.CreatedAt, volume.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: volumes.project_id, volumes.name") {
+			return domain.AlreadyExistsError("volume", "name", volume.Name)
+		}
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return domain.ForeignKeyViolationError("project", "id", volume.ProjectID)
+		}
+		return fmt.Errorf("failed to create volume: %w", err)
+	}
+
+	setVolumeStatus(volume)
+	return nil
+}
+
+// GetByID retrieves a volume by ID
+func (r *VolumeRepository) GetByID(id string) (*domain.Volume, error) {
+	query := `SELECT ` + volumeColumns + ` FROM volumes WHERE id = ?`
+
+	volume, err := scanVolume(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.NotFoundError("volume", id)
+		}
+		return nil, fmt.Errorf("failed to get volume: %w", err)
+	}
+
+	return volume, nil
+}
+
+// List retrieves volumes with optional filtering
+func (r *VolumeRepository) List(opts domain.VolumeListOptions) ([]*domain.Volume, error) {
+	var args []interface{}
+	var conditions []string
+
+	query := `SELECT ` + volumeColumns + ` FROM volumes`
+
+	if opts.ProjectID != "" {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, opts.ProjectID)
+	}
+	if opts.InstanceID != "" {
+		conditions = append(conditions, "instance_id = ?")
+		args = append(args, opts.InstanceID)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY name"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	defer rows.Close()
+
+	volumes := []*domain.Volume{}
+	for rows.Next() {
+		volume, err := scanVolume(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan volume: %w", err)
+		}
+		volumes = append(volumes, volume)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating volumes: %w", err)
+	}
+
+	return volumes, nil
+}
+
+// Update updates a volume
+func (r *VolumeRepository) Update(id string, req domain.UpdateVolumeRequest) (*domain.Volume, error) {
+	existing, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	if req.SizeGB != nil {
+		existing.SizeGB = *req.SizeGB
+	}
+	existing.UpdatedAt = time.Now()
+
+	_, err = r.db.Exec(`UPDATE volumes SET name = ?, size_gb = ?, updated_at = ? WHERE id = ?`, existing.Name, existing.SizeGB, existing.UpdatedAt, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: volumes.project_id, volumes.name") {
+			return nil, domain.AlreadyExistsError("volume", "name", existing.Name)
+		}
+		return nil, fmt.Errorf("failed to update volume: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Attach attaches a volume to an instance
+// It fails with a conflict if the volume is attached to another instance in the meantime
+func (r *VolumeRepository) Attach(id, instanceID string) (*domain.Volume, error) {
+	result, err := r.db.Exec(`UPDATE volumes SET instance_id = ?, updated_at = ? WHERE id = ? AND (instance_id IS NULL OR instance_id = ?)`,
+		instanceID, time.Now(), id, instanceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return nil, domain.ForeignKeyViolationError("instance", "id", instanceID)
+		}
+		return nil, fmt.Errorf("failed to attach volume: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	volume, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, domain.ConflictError("volume is attached to another instance", map[string]interface{}{
+			"volume_id":   id,
+			"instance_id": volume.InstanceID,
+		})
+	}
+
+	return volume, nil
+}
+
+// Detach detaches a volume from its instance, if it is attached
+func (r *VolumeRepository) Detach(id string) (*domain.Volume, error) {
+	if _, err := r.db.Exec(`UPDATE volumes SET instance_id = NULL, updated_at = ? WHERE id = ? AND instance_id IS NOT NULL`, time.Now(), id); err != nil {
+		return nil, fmt.Errorf("failed to detach volume: %w", err)
+	}
+	return r.GetByID(id)
+}
+
+// DetachAll detaches all volumes from an instance
+func (r *VolumeRepository) DetachAll(instanceID string) error {
+	if _, err := r.db.Exec(`UPDATE volumes SET instance_id = NULL, updated_at = ? WHERE instance_id = ?`, time.Now(), instanceID); err != nil {
+		return fmt.Errorf("failed to detach volumes: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a volume by ID, unless it is attached to an instance
+func (r *VolumeRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM volumes WHERE id = ? AND instance_id IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete volume: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rows == 0 {
+		volume, err := r.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return domain.ConflictError("volume is attached to an instance", map[string]interface{}{
+			"volume_id":   id,
+			"instance_id": volume.InstanceID,
+		})
+	}
+
+	return nil
+}
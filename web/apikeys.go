@@ -0,0 +1,183 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/web/static"
+)
+
+// ListAPIKeys handles GET /org/{org}/api-keys
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, err := h.getPageContext(r, org, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(org.ID)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var currentKeyID string
+	if session := sessionFromContext(r.Context()); session != nil {
+		currentKeyID = session.APIKeyID
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("api-keys").Parse(baseTemplate + apiKeysTemplate))
+	tmpl.Execute(w, map[string]interface{}{
+		"CSS":          template.CSS(static.CSS),
+		"Context":      ctx,
+		"Keys":         keys,
+		"CurrentKeyID": currentKeyID,
+	})
+}
+
+// NewAPIKeyForm handles GET /org/{org}/api-keys/new
+func (h *Handler) NewAPIKeyForm(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	projects, err := h.service.ListProjects(domain.ProjectListOptions{OrgID: org.ID})
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("new-api-key").Parse(newAPIKeyFormTemplate))
+	tmpl.Execute(w, map[string]interface{}{
+		"Org":      org,
+		"Scopes":   domain.Scopes,
+		"Projects": projects,
+	})
+}
+
+// CreateAPIKey handles POST /org/{org}/api-keys
+// The response replaces the form with the new token, which is never shown again
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderFormError(w, "Invalid form data")
+		return
+	}
+
+	req := domain.CreateAPIKeyRequest{
+		Name:     r.FormValue("name"),
+		Scopes:   r.Form["scopes"],
+		Projects: r.Form["projects"],
+	}
+	// The key expires at the start of the chosen day (UTC)
+	if expires := r.FormValue("expires_at"); expires != "" {
+		expiresAt, err := time.Parse("2006-01-02", expires)
+		if err != nil {
+			h.renderFormError(w, "Invalid expiry date")
+			return
+		}
+		req.ExpiresAt = &expiresAt
+	}
+
+	creator, err := h.sessionAPIKey(r, org)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(org.ID, req, creator)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	tmpl := template.Must(template.New("api-key-created").Parse(apiKeyCreatedTemplate))
+	tmpl.Execute(w, map[string]interface{}{"Org": org, "Key": key})
+}
+
+// RotateAPIKey handles POST /org/{org}/api-keys/{key_id}/rotate
+// The previous token keeps working for domain.DefaultRotationOverlap
+func (h *Handler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	creator, err := h.sessionAPIKey(r, org)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	key, err := h.service.RotateAPIKey(org.ID, mux.Vars(r)["key_id"], domain.RotateAPIKeyRequest{}, creator)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	tmpl := template.Must(template.New("api-key-created").Parse(apiKeyCreatedTemplate))
+	tmpl.Execute(w, map[string]interface{}{"Org": org, "Key": key, "Rotated": true})
+}
+
+// DeleteAPIKey handles DELETE /org/{org}/api-keys/{key_id}
+// Revoking the key the session signed in with also ends the session
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	creator, err := h.sessionAPIKey(r, org)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	keyID := mux.Vars(r)["key_id"]
+	if err := h.service.DeleteAPIKey(org.ID, keyID, creator); err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	if session := sessionFromContext(r.Context()); session != nil && session.APIKeyID == keyID {
+		clearSessionCookie(w, r)
+		redirect(w, r, "/login")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// sessionAPIKey returns the API key the console session signed in with
+// Keys created or revoked in the console are limited by its expiry like keys managed through the API
+func (h *Handler) sessionAPIKey(r *http.Request, org *domain.Organization) (*domain.APIKey, error) {
+	session := sessionFromContext(r.Context())
+	if session == nil {
+		return nil, nil
+	}
+	return h.service.GetAPIKey(org.ID, session.APIKeyID)
+}
@@ -0,0 +1,174 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/web/static"
+)
+
+// sessionCookieName is the name of the web console session cookie
+const sessionCookieName = "nah_session"
+
+type contextKey string
+
+const contextKeySession contextKey = "session"
+
+// sessionFromContext returns the console session stored by RequireSession
+func sessionFromContext(ctx context.Context) *domain.ConsoleSession {
+	session, _ := ctx.Value(contextKeySession).(*domain.ConsoleSession)
+	return session
+}
+
+// currentSession reads and validates the session cookie of a request
+func (h *Handler) currentSession(r *http.Request) (*domain.ConsoleSession, error) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil, domain.UnauthorizedError("not signed in")
+	}
+	return h.service.ValidateConsoleSession(cookie.Value)
+}
+
+// isSecureRequest reports whether the request reached us over HTTPS
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// redirectToLogin sends the browser to the login page (htmx requests get an HX-Redirect)
+func redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/login")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusFound)
+}
+
+// validCSRFToken checks the X-CSRF-Token header (set by htmx) or the csrf_token form field
+func validCSRFToken(r *http.Request, session *domain.ConsoleSession) bool {
+	token := r.Header.Get("X-CSRF-Token")
+	if token == "" {
+		token = r.FormValue("csrf_token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) == 1
+}
+
+// RequireSession is middleware for org-scoped console routes
+// It requires a valid session for the org in the URL and a CSRF token on
+// state-changing requests, sent as the X-CSRF-Token header or csrf_token form field
+func (h *Handler) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := h.currentSession(r)
+		if err != nil {
+			if domain.IsUnauthorized(err) {
+				redirectToLogin(w, r)
+				return
+			}
+			h.renderError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		org, err := h.service.GetOrganizationBySlug(mux.Vars(r)["org"])
+		if err != nil || org.ID != session.OrgID {
+			// Don't reveal whether other organizations exist
+			h.renderError(w, "Organization not found", http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if !validCSRFToken(r, session) {
+				h.renderFormError(w, "Invalid or missing CSRF token, please reload the page")
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeySession, session)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// LoginPage handles GET /login
+func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.currentSession(r); err == nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	h.renderLogin(w, "", http.StatusOK)
+}
+
+// Login handles POST /login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.renderLogin(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	session, token, err := h.service.CreateConsoleSession(r.FormValue("api_key"))
+	if err != nil {
+		if domain.IsUnauthorized(err) {
+			h.renderLogin(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if domain.IsForbidden(err) {
+			h.renderLogin(w, "This API key is restricted to some scopes or projects and cannot sign in to the console", http.StatusForbidden)
+			return
+		}
+		h.renderLogin(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// Logout handles POST /logout
+// Requires the CSRF token of the current session so other sites cannot sign users out
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if session, err := h.currentSession(r); err == nil && !validCSRFToken(r, session) {
+		h.renderError(w, "Invalid or missing CSRF token", http.StatusForbidden)
+		return
+	}
+
+	clearSessionCookie(w, r)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// clearSessionCookie tells the browser to drop the session cookie
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// renderLogin renders the login page with an optional error message
+func (h *Handler) renderLogin(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	tmpl := template.Must(template.New("login").Parse(loginTemplate))
+	tmpl.Execute(w, map[string]interface{}{
+		"CSS":     template.CSS(static.CSS),
+		"Message": message,
+	})
+}
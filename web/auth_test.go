@@ -0,0 +1,163 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/service"
+	"github.com/hypertf/nahcloud/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestHandler creates a handler on an in-memory database, with an org signed in to the console
+// It returns the handler, the session and its cookie value
+func setupTestHandler(t *testing.T) (*Handler, *service.Service, *domain.ConsoleSession, string) {
+	t.Helper()
+
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared&_fk=1"
+	db, err := sqlite.NewDB(dsn)
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	svc := service.NewService(
+		sqlite.NewOrganizationRepository(db),
+		sqlite.NewAPIKeyRepository(db),
+		sqlite.NewProjectRepository(db),
+		sqlite.NewInstanceRepository(db),
+		sqlite.NewMetadataRepository(db),
+		sqlite.NewBucketRepository(db),
+		sqlite.NewObjectRepository(db),
+	)
+	svc.SetSigningSecret([]byte("test-secret"))
+
+	for _, slug := range []string{"test-org", "other-org"} {
+		_, err := svc.CreateOrganization(domain.CreateOrganizationRequest{Slug: slug, Name: slug})
+		require.NoError(t, err)
+	}
+	org, err := svc.GetOrganizationBySlug("test-org")
+	require.NoError(t, err)
+	key, err := svc.CreateAPIKey(org.ID, domain.CreateAPIKeyRequest{Name: "console"}, nil)
+	require.NoError(t, err)
+	session, token, err := svc.CreateConsoleSession(key.Token)
+	require.NoError(t, err)
+
+	return NewHandler(svc), svc, session, token
+}
+
+func TestRequireSession(t *testing.T) {
+	h, svc, session, token := setupTestHandler(t)
+
+	router := mux.NewRouter()
+	orgRouter := router.PathPrefix("/org/{org}").Subrouter()
+	orgRouter.Use(h.RequireSession)
+	orgRouter.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, session.APIKeyID, sessionFromContext(r.Context()).APIKeyID)
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("GET", "POST")
+
+	request := func(method, path, cookie string, header http.Header, form url.Values) *httptest.ResponseRecorder {
+		var body *strings.Reader
+		if form != nil {
+			body = strings.NewReader(form.Encode())
+		} else {
+			body = strings.NewReader("")
+		}
+		req := httptest.NewRequest(method, path, body)
+		if form != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	csrf := func(token string) http.Header {
+		return http.Header{"X-Csrf-Token": {token}}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		org    string
+		cookie string
+		header http.Header
+		form   url.Values
+		status int
+	}{
+		{"read without csrf token", "GET", "test-org", token, nil, nil, http.StatusNoContent},
+		{"write with csrf header", "POST", "test-org", token, csrf(session.CSRFToken), nil, http.StatusNoContent},
+		{"write with csrf form field", "POST", "test-org", token, nil, url.Values{"csrf_token": {session.CSRFToken}}, http.StatusNoContent},
+		{"write without csrf token", "POST", "test-org", token, nil, nil, http.StatusBadRequest},
+		{"write with wrong csrf header", "POST", "test-org", token, csrf(session.CSRFToken + "0"), nil, http.StatusBadRequest},
+		{"write with wrong csrf form field", "POST", "test-org", token, nil, url.Values{"csrf_token": {session.CSRFToken[:len(session.CSRFToken)-1]}}, http.StatusBadRequest},
+		{"other organization", "GET", "other-org", token, nil, nil, http.StatusNotFound},
+		{"not signed in", "GET", "test-org", "", nil, nil, http.StatusFound},
+		{"forged session", "GET", "test-org", token + "2", nil, nil, http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(tt.method, "/org/"+tt.org+"/projects", tt.cookie, tt.header, tt.form)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusFound {
+				assert.Equal(t, "/login", rec.Header().Get("Location"))
+			}
+		})
+	}
+
+	// The CSRF token of another session is not accepted
+	other, _, err := svc.CreateConsoleSession(mustCreateConsoleKey(t, svc, session.OrgID))
+	require.NoError(t, err)
+	rec := request("POST", "/org/test-org/projects", token, csrf(other.CSRFToken), nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Deleting the API key signs its sessions out
+	require.NoError(t, svc.DeleteAPIKey(session.OrgID, session.APIKeyID, nil))
+	rec = request("POST", "/org/test-org/projects", token, csrf(session.CSRFToken), nil)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	rec = request("GET", "/org/test-org/projects", token, http.Header{"Hx-Request": {"true"}}, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "/login", rec.Header().Get("HX-Redirect"))
+}
+
+func TestLogout_RequiresCSRFToken(t *testing.T) {
+	h, _, session, token := setupTestHandler(t)
+
+	logout := func(csrfToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/logout", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: token})
+		req.Header.Set("X-CSRF-Token", csrfToken)
+		rec := httptest.NewRecorder()
+		h.Logout(rec, req)
+		return rec
+	}
+
+	rec := logout("wrong")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+
+	rec = logout(session.CSRFToken)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.Equal(t, -1, rec.Result().Cookies()[0].MaxAge)
+}
+
+// mustCreateConsoleKey creates a full-access API key in an org and returns its token
+func mustCreateConsoleKey(t *testing.T, svc *service.Service, orgID string) string {
+	t.Helper()
+
+	key, err := svc.CreateAPIKey(orgID, domain.CreateAPIKeyRequest{}, nil)
+	require.NoError(t, err)
+	return key.Token
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/hypertf/nahcloud/web/static"
+)
+
+// OrgDashboard handles GET /org/{org}
+func (h *Handler) OrgDashboard(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, err := h.getPageContext(r, org, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summary, err := h.service.GetOrgSummary(org.ID, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, resource := range summary.RecentChanges {
+		resource.URL = searchResultURL(org, &resource.SearchResult)
+	}
+
+	var currentKeyID string
+	if session := sessionFromContext(r.Context()); session != nil {
+		currentKeyID = session.APIKeyID
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("dashboard").Parse(baseTemplate + dashboardTemplate))
+	tmpl.Execute(w, map[string]interface{}{
+		"CSS":          template.CSS(static.CSS),
+		"Context":      ctx,
+		"Summary":      summary,
+		"CurrentKeyID": currentKeyID,
+	})
+}
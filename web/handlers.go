@@ -1,11 +1,18 @@
 package web
 
 import (
+	"bytes"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 	"github.com/hypertf/nahcloud/domain"
@@ -13,6 +20,13 @@ import (
 	"github.com/hypertf/nahcloud/web/static"
 )
 
+const (
+	// maxUploadSize limits files uploaded through the console
+	maxUploadSize = 32 << 20
+	// maxHexDumpSize limits how much of a binary object the viewer shows
+	maxHexDumpSize = 4096
+)
+
 // Handler handles web console requests
 type Handler struct {
 	service *service.Service
@@ -25,10 +39,11 @@ func NewHandler(svc *service.Service) *Handler {
 
 // PageContext contains common data for all pages
 type PageContext struct {
-	Org      *domain.Organization
-	Project  *domain.Project
-	Orgs     []*domain.Organization
-	Projects []*domain.Project
+	Org       *domain.Organization
+	Project   *domain.Project
+	Orgs      []*domain.Organization // Organizations the signed-in user can access
+	Projects  []*domain.Project
+	CSRFToken string
 }
 
 // resolveOrg gets the organization from the URL
@@ -62,26 +77,96 @@ func (h *Handler) resolveProject(r *http.Request) (*domain.Organization, *domain
 	return org, project, nil
 }
 
-// getPageContext builds the common page context
-func (h *Handler) getPageContext(org *domain.Organization, project *domain.Project) (*PageContext, error) {
-	orgs, err := h.service.ListOrganizations(domain.OrganizationListOptions{})
+// resolveInstance gets the instance from the URL and checks it belongs to the project in the URL
+func (h *Handler) resolveInstance(r *http.Request) (*domain.Organization, *domain.Project, *domain.Instance, error) {
+	org, project, err := h.resolveProject(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	id := mux.Vars(r)["id"]
+	instance, err := h.service.GetInstance(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if instance.ProjectID != project.ID {
+		return nil, nil, nil, domain.NotFoundError("instance", id)
+	}
+	return org, project, instance, nil
+}
+
+// resolveMetadata gets a metadata entry by ID and checks it belongs to the org
+func (h *Handler) resolveMetadata(org *domain.Organization, id string) (*domain.Metadata, error) {
+	if id == "" {
+		return nil, domain.InvalidInputError("Metadata ID is required", nil)
+	}
+	metadata, err := h.service.GetMetadata(id)
 	if err != nil {
 		return nil, err
 	}
+	if metadata.OrgID != org.ID {
+		return nil, domain.NotFoundError("metadata", id)
+	}
+	return metadata, nil
+}
+
+// resolveBucket gets the bucket from the URL, within the project in the URL
+func (h *Handler) resolveBucket(r *http.Request) (*domain.Organization, *domain.Project, *domain.Bucket, error) {
+	org, project, err := h.resolveProject(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
+	bucket, err := h.service.GetBucketByName(project.ID, mux.Vars(r)["bucket"])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return org, project, bucket, nil
+}
+
+// resolveObject gets the object from the URL and checks it belongs to the bucket in the URL
+func (h *Handler) resolveObject(r *http.Request) (*domain.Organization, *domain.Project, *domain.Bucket, *domain.Object, error) {
+	org, project, bucket, err := h.resolveBucket(r)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	id := mux.Vars(r)["objid"]
+	obj, err := h.service.GetObject(id)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if obj.BucketID != bucket.ID {
+		return nil, nil, nil, nil, domain.NotFoundError("object", id)
+	}
+	return org, project, bucket, obj, nil
+}
+
+// getPageContext builds the common page context
+// A session only grants access to its own organization, so that is the only one listed
+func (h *Handler) getPageContext(r *http.Request, org *domain.Organization, project *domain.Project) (*PageContext, error) {
+	var orgs []*domain.Organization
 	var projects []*domain.Project
 	if org != nil {
+		orgs = []*domain.Organization{org}
+		var err error
 		projects, err = h.service.ListProjects(domain.ProjectListOptions{OrgID: org.ID})
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	var csrfToken string
+	if session := sessionFromContext(r.Context()); session != nil {
+		csrfToken = session.CSRFToken
+	}
+
 	return &PageContext{
-		Org:      org,
-		Project:  project,
-		Orgs:     orgs,
-		Projects: projects,
+		Org:       org,
+		Project:   project,
+		Orgs:      orgs,
+		Projects:  projects,
+		CSRFToken: csrfToken,
 	}, nil
 }
 
@@ -91,23 +176,21 @@ func (h *Handler) ServeLogo(w http.ResponseWriter, r *http.Request) {
 	w.Write(static.Logo)
 }
 
-// Dashboard shows the main dashboard (redirects to default org/project)
+// Dashboard handles GET / (redirects to the signed-in org's dashboard)
 func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
-	orgs, err := h.service.ListOrganizations(domain.OrganizationListOptions{})
-	if err != nil || len(orgs) == 0 {
-		h.renderError(w, "No organizations found", http.StatusInternalServerError)
+	session, err := h.currentSession(r)
+	if err != nil {
+		redirectToLogin(w, r)
 		return
 	}
 
-	org := orgs[0]
-
-	projects, err := h.service.ListProjects(domain.ProjectListOptions{OrgID: org.ID})
-	if err != nil || len(projects) == 0 {
-		http.Redirect(w, r, fmt.Sprintf("/org/%s/projects", org.Slug), http.StatusFound)
+	org, err := h.service.GetOrganization(session.OrgID)
+	if err != nil {
+		h.renderError(w, "Organization not found", http.StatusNotFound)
 		return
 	}
 
-	http.Redirect(w, r, fmt.Sprintf("/org/%s/projects/%s/instances", org.Slug, projects[0].Slug), http.StatusFound)
+	http.Redirect(w, r, fmt.Sprintf("/org/%s", org.Slug), http.StatusFound)
 }
 
 // renderError renders a full page error
@@ -136,6 +219,16 @@ func (h *Handler) renderFormError(w http.ResponseWriter, message string) {
 	w.Write([]byte(html))
 }
 
+// redirect sends the browser to url after a form submission (htmx requests get an HX-Redirect)
+func redirect(w http.ResponseWriter, r *http.Request, url string) {
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", url)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
 // Project Handlers
 
 // ListProjects handles GET /web/org/{org}/projects
@@ -157,7 +250,7 @@ func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		defaultProject = projects[0]
 	}
 
-	ctx, err := h.getPageContext(org, defaultProject)
+	ctx, err := h.getPageContext(r, org, defaultProject)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -276,7 +369,7 @@ func (h *Handler) ListInstances(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, err := h.getPageContext(org, project)
+	ctx, err := h.getPageContext(r, org, project)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -310,7 +403,7 @@ func (h *Handler) NewInstanceForm(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, map[string]interface{}{
 		"Org":     org,
 		"Project": project,
-		"Regions": domain.ValidRegions,
+		"Regions": h.service.ListRegions(),
 	})
 }
 
@@ -351,16 +444,7 @@ func (h *Handler) CreateInstance(w http.ResponseWriter, r *http.Request) {
 
 // EditInstanceForm handles GET /web/org/{org}/projects/{project}/instances/{id}/edit
 func (h *Handler) EditInstanceForm(w http.ResponseWriter, r *http.Request) {
-	org, project, err := h.resolveProject(r)
-	if err != nil {
-		h.renderFormError(w, err.Error())
-		return
-	}
-
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	instance, err := h.service.GetInstance(id)
+	org, project, instance, err := h.resolveInstance(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -372,21 +456,18 @@ func (h *Handler) EditInstanceForm(w http.ResponseWriter, r *http.Request) {
 		"Org":      org,
 		"Project":  project,
 		"Instance": instance,
-		"Regions":  domain.ValidRegions,
+		"Regions":  h.service.ListRegions(),
 	})
 }
 
 // UpdateInstance handles PUT /web/org/{org}/projects/{project}/instances/{id}
 func (h *Handler) UpdateInstance(w http.ResponseWriter, r *http.Request) {
-	_, _, err := h.resolveProject(r)
+	_, _, instance, err := h.resolveInstance(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
 
-	vars := mux.Vars(r)
-	id := vars["id"]
-
 	if err := r.ParseForm(); err != nil {
 		h.renderFormError(w, "Invalid form data")
 		return
@@ -404,7 +485,7 @@ func (h *Handler) UpdateInstance(w http.ResponseWriter, r *http.Request) {
 		Status:   &status,
 	}
 
-	_, err = h.service.UpdateInstance(id, req)
+	_, err = h.service.UpdateInstance(instance.ID, req)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -415,10 +496,13 @@ func (h *Handler) UpdateInstance(w http.ResponseWriter, r *http.Request) {
 
 // DeleteInstance handles DELETE /web/org/{org}/projects/{project}/instances/{id}
 func (h *Handler) DeleteInstance(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+	_, _, instance, err := h.resolveInstance(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
 
-	if err := h.service.DeleteInstance(id); err != nil {
+	if err := h.service.DeleteInstance(instance.ID); err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
@@ -436,7 +520,7 @@ func (h *Handler) ListMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, err := h.getPageContext(org, nil)
+	ctx, err := h.getPageContext(r, org, nil)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -508,13 +592,7 @@ func (h *Handler) EditMetadataForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.renderFormError(w, "Metadata ID is required")
-		return
-	}
-
-	metadata, err := h.service.GetMetadata(id)
+	metadata, err := h.resolveMetadata(org, r.URL.Query().Get("id"))
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -527,7 +605,7 @@ func (h *Handler) EditMetadataForm(w http.ResponseWriter, r *http.Request) {
 
 // UpdateMetadata handles PUT /web/org/{org}/metadata/update
 func (h *Handler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
-	_, err := h.resolveOrg(r)
+	org, err := h.resolveOrg(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -538,12 +616,16 @@ func (h *Handler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.FormValue("id")
-	value := r.FormValue("value")
+	metadata, err := h.resolveMetadata(org, r.FormValue("id"))
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
 
+	value := r.FormValue("value")
 	req := domain.UpdateMetadataRequest{Value: &value}
 
-	_, err = h.service.UpdateMetadata(id, req)
+	_, err = h.service.UpdateMetadata(metadata.ID, req)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -554,13 +636,19 @@ func (h *Handler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
 
 // DeleteMetadata handles DELETE /web/org/{org}/metadata/delete
 func (h *Handler) DeleteMetadata(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.renderFormError(w, "Metadata ID is required")
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	metadata, err := h.resolveMetadata(org, r.URL.Query().Get("id"))
+	if err != nil {
+		h.renderFormError(w, err.Error())
 		return
 	}
 
-	if err := h.service.DeleteMetadata(id); err != nil {
+	if err := h.service.DeleteMetadata(metadata.ID); err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
@@ -578,7 +666,7 @@ func (h *Handler) ListStorage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, err := h.getPageContext(org, project)
+	ctx, err := h.getPageContext(r, org, project)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -638,24 +726,65 @@ func (h *Handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 	h.ListStorage(w, r)
 }
 
-// ListBucketObjects handles GET /web/org/{org}/projects/{project}/storage/{bucket}
-func (h *Handler) ListBucketObjects(w http.ResponseWriter, r *http.Request) {
-	org, project, err := h.resolveProject(r)
+// EditBucketForm handles GET /web/org/{org}/projects/{project}/storage/{bucket}/edit
+func (h *Handler) EditBucketForm(w http.ResponseWriter, r *http.Request) {
+	org, project, bucket, err := h.resolveBucket(r)
 	if err != nil {
-		h.renderError(w, err.Error(), http.StatusNotFound)
+		h.renderFormError(w, err.Error())
 		return
 	}
 
-	vars := mux.Vars(r)
-	bucketName := vars["bucket"]
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("edit-bucket").Parse(editBucketFormTemplate))
+	tmpl.Execute(w, map[string]interface{}{"Org": org, "Project": project, "Bucket": bucket})
+}
+
+// UpdateBucket handles PUT /web/org/{org}/projects/{project}/storage/{bucket}
+func (h *Handler) UpdateBucket(w http.ResponseWriter, r *http.Request) {
+	_, _, bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderFormError(w, "Invalid form data")
+		return
+	}
+
+	if _, err := h.service.RenameBucket(bucket.ID, r.FormValue("name")); err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	h.ListStorage(w, r)
+}
 
-	bucket, err := h.service.GetBucketByName(project.ID, bucketName)
+// DeleteBucket handles DELETE /web/org/{org}/projects/{project}/storage/{bucket}
+func (h *Handler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
+	_, _, bucket, err := h.resolveBucket(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteBucket(bucket.ID); err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListBucketObjects handles GET /web/org/{org}/projects/{project}/storage/{bucket}
+func (h *Handler) ListBucketObjects(w http.ResponseWriter, r *http.Request) {
+	org, project, bucket, err := h.resolveBucket(r)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	ctx, err := h.getPageContext(org, project)
+	ctx, err := h.getPageContext(r, org, project)
 	if err != nil {
 		h.renderError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -681,16 +810,7 @@ func (h *Handler) ListBucketObjects(w http.ResponseWriter, r *http.Request) {
 
 // NewObjectForm handles GET /web/org/{org}/projects/{project}/storage/{bucket}/objects/new
 func (h *Handler) NewObjectForm(w http.ResponseWriter, r *http.Request) {
-	org, project, err := h.resolveProject(r)
-	if err != nil {
-		h.renderFormError(w, err.Error())
-		return
-	}
-
-	vars := mux.Vars(r)
-	bucketName := vars["bucket"]
-
-	bucket, err := h.service.GetBucketByName(project.ID, bucketName)
+	org, project, bucket, err := h.resolveBucket(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
@@ -702,38 +822,52 @@ func (h *Handler) NewObjectForm(w http.ResponseWriter, r *http.Request) {
 		"Org":     org,
 		"Project": project,
 		"Bucket":  bucket,
+		"Prefix":  r.URL.Query().Get("prefix"),
 	})
 }
 
 // CreateObject handles POST /web/org/{org}/projects/{project}/storage/{bucket}/objects
+// The form is multipart: the file input is stored as-is, and the path defaults to the file name
 func (h *Handler) CreateObject(w http.ResponseWriter, r *http.Request) {
-	_, project, err := h.resolveProject(r)
+	_, _, bucket, err := h.resolveBucket(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
 
-	vars := mux.Vars(r)
-	bucketName := vars["bucket"]
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		h.renderFormError(w, fmt.Sprintf("Invalid upload (files are limited to %d MB)", maxUploadSize>>20))
+		return
+	}
 
-	bucket, err := h.service.GetBucketByName(project.ID, bucketName)
+	file, header, err := r.FormFile("file")
 	if err != nil {
-		h.renderFormError(w, err.Error())
+		h.renderFormError(w, "Choose a file to upload")
 		return
 	}
+	defer file.Close()
 
-	if err := r.ParseForm(); err != nil {
-		h.renderFormError(w, "Invalid form data")
+	content, err := io.ReadAll(file)
+	if err != nil {
+		h.renderFormError(w, "Failed to read uploaded file")
 		return
 	}
 
-	content := r.FormValue("content")
-	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	objectPath := r.FormValue("path")
+	if objectPath == "" || strings.HasSuffix(objectPath, "/") {
+		objectPath += header.Filename
+	}
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
 
 	req := domain.CreateObjectRequest{
-		BucketID: bucket.ID,
-		Path:     r.FormValue("path"),
-		Content:  encoded,
+		BucketID:    bucket.ID,
+		Path:        objectPath,
+		Content:     base64.StdEncoding.EncodeToString(content),
+		ContentType: contentType,
 	}
 
 	_, err = h.service.CreateObject(req)
@@ -746,40 +880,91 @@ func (h *Handler) CreateObject(w http.ResponseWriter, r *http.Request) {
 }
 
 // ViewObject handles GET /web/org/{org}/projects/{project}/storage/{bucket}/objects/{objid}
+// Text is shown as-is, images are previewed inline and anything else is shown as a hex dump
 func (h *Handler) ViewObject(w http.ResponseWriter, r *http.Request) {
-	org, project, err := h.resolveProject(r)
+	org, project, bucket, obj, err := h.resolveObject(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
 
-	vars := mux.Vars(r)
-	bucketName := vars["bucket"]
-	objID := vars["objid"]
+	decoded, err := base64.StdEncoding.DecodeString(obj.Content)
+	if err != nil {
+		decoded = []byte(obj.Content)
+	}
+	contentType := objectContentType(obj, decoded)
+
+	data := map[string]interface{}{
+		"Org":         org,
+		"Project":     project,
+		"Bucket":      bucket,
+		"Object":      obj,
+		"ContentType": contentType,
+		"Size":        len(decoded),
+	}
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		data["ImageURL"] = template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(decoded))
+	case utf8.Valid(decoded) && !bytes.ContainsRune(decoded, 0):
+		data["Text"] = string(decoded)
+	default:
+		dump := decoded
+		if len(dump) > maxHexDumpSize {
+			dump = dump[:maxHexDumpSize]
+			data["Truncated"] = maxHexDumpSize
+		}
+		data["Hex"] = hex.Dump(dump)
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("view-object").Parse(viewObjectTemplate))
+	tmpl.Execute(w, data)
+}
 
-	bucket, err := h.service.GetBucketByName(project.ID, bucketName)
+// DownloadObject handles GET /web/org/{org}/projects/{project}/storage/{bucket}/objects/{objid}/download
+func (h *Handler) DownloadObject(w http.ResponseWriter, r *http.Request) {
+	_, _, _, obj, err := h.resolveObject(r)
 	if err != nil {
-		h.renderFormError(w, err.Error())
+		h.renderError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	obj, err := h.service.GetObject(objID)
+	decoded, err := base64.StdEncoding.DecodeString(obj.Content)
+	if err != nil {
+		decoded = []byte(obj.Content)
+	}
+
+	// Always download rather than render, so uploaded HTML can't run in the console's origin
+	w.Header().Set("Content-Type", objectContentType(obj, decoded))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(obj.Path)}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.Itoa(len(decoded)))
+	w.Write(decoded)
+}
+
+// DeleteObject handles DELETE /web/org/{org}/projects/{project}/storage/{bucket}/objects/{objid}
+func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	_, _, _, obj, err := h.resolveObject(r)
 	if err != nil {
 		h.renderFormError(w, err.Error())
 		return
 	}
 
-	decoded, _ := base64.StdEncoding.DecodeString(obj.Content)
+	if err := h.service.DeleteObject(obj.ID); err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
 
-	w.Header().Set("Content-Type", "text/html")
-	tmpl := template.Must(template.New("view-object").Parse(viewObjectTemplate))
-	tmpl.Execute(w, map[string]interface{}{
-		"Org":            org,
-		"Project":        project,
-		"Bucket":         bucket,
-		"Object":         obj,
-		"DecodedContent": string(decoded),
-		"Size":           len(decoded),
-	})
+	w.WriteHeader(http.StatusOK)
 }
 
+// objectContentType returns the stored content type of an object, or sniffs it from the content
+func objectContentType(obj *domain.Object, content []byte) string {
+	if obj.ContentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(obj.ContentType); err == nil {
+			return mediaType
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	return mediaType
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/web/static"
+)
+
+// Search handles GET /org/{org}/search?q=...
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, err := h.getPageContext(r, org, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	data := map[string]interface{}{
+		"CSS":     template.CSS(static.CSS),
+		"Context": ctx,
+		"Query":   query,
+	}
+	if query != "" {
+		results, err := h.service.Search(domain.SearchOptions{OrgID: org.ID, Query: query}, nil)
+		if err != nil {
+			if !domain.IsInvalidInput(err) {
+				h.renderError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			data["Error"] = err.Error()
+		}
+		for _, result := range results {
+			result.URL = searchResultURL(org, result)
+		}
+		data["Results"] = results
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("search").Parse(baseTemplate + searchTemplate))
+	tmpl.Execute(w, data)
+}
+
+// searchResultURL returns the console page of a search result
+// Instances have no page of their own, so they link to their row in the project's list
+func searchResultURL(org *domain.Organization, result *domain.SearchResult) string {
+	orgURL := "/org/" + url.PathEscape(org.Slug)
+	projectURL := orgURL + "/projects/" + url.PathEscape(result.ProjectSlug)
+	switch result.Kind {
+	case domain.ResourceKindProject:
+		return projectURL + "/instances"
+	case domain.ResourceKindInstance:
+		return projectURL + "/instances#row-" + result.ID
+	case domain.ResourceKindBucket:
+		return projectURL + "/storage/" + url.PathEscape(result.BucketID)
+	case domain.ResourceKindObject:
+		return projectURL + "/storage/" + url.PathEscape(result.BucketID) + "/objects/" + url.PathEscape(result.ID)
+	case domain.ResourceKindMetadata:
+		return orgURL + "/metadata?prefix=" + url.QueryEscape(result.Name)
+	}
+	return ""
+}
@@ -21,6 +21,46 @@ const errorTemplate = `<!DOCTYPE html>
 </body>
 </html>`
 
+const loginTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <title>Sign in - NahCloud</title>
+    <link rel="icon" type="image/png" href="/static/logo.png">
+    <link rel="preconnect" href="https://fonts.googleapis.com">
+    <link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
+    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@400;500;600;700&display=swap" rel="stylesheet">
+    <style>{{.CSS}}</style>
+</head>
+<body class="bg-slate-50 text-slate-800 font-sans min-h-screen flex items-center justify-center">
+    <div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden w-full max-w-lg">
+        <div class="px-6 py-5 border-b border-slate-200 flex items-center gap-3">
+            <img src="/static/logo.png" alt="NahCloud" class="w-10 h-10 rounded-lg">
+            <div>
+                <h1 class="text-xl font-bold text-[#2878B5]">NahCloud</h1>
+                <span class="text-xs text-slate-500 font-medium">Console</span>
+            </div>
+        </div>
+        <form method="post" action="/login">
+            <div class="p-6">
+                {{if .Message}}
+                <div class="flex items-center gap-2 p-3 bg-red-50 border border-red-200 rounded-lg mb-4">
+                    <span class="text-sm text-red-700">{{.Message}}</span>
+                </div>
+                {{end}}
+                <div class="mb-5">
+                    <label class="block text-sm font-medium mb-1.5" for="api_key">API key</label>
+                    <input type="password" id="api_key" name="api_key" placeholder="nah_api_..." required autofocus class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+                    <p class="text-xs text-slate-400 mt-1">You will be signed in to the organization the key belongs to.</p>
+                </div>
+            </div>
+            <div class="px-6 py-4 border-t border-slate-200 flex justify-end gap-3 bg-slate-50">
+                <button type="submit" class="btn btn-primary">Sign in</button>
+            </div>
+        </form>
+    </div>
+</body>
+</html>`
+
 const baseTemplate = `<!DOCTYPE html>
 <html>
 <head>
@@ -42,7 +82,7 @@ const baseTemplate = `<!DOCTYPE html>
     <link href="https://fonts.googleapis.com/css2?family=Inter:wght@400;500;600;700&display=swap" rel="stylesheet">
     <style>{{.CSS}}</style>
 </head>
-<body class="bg-slate-50 text-slate-800 font-sans min-h-screen">
+<body class="bg-slate-50 text-slate-800 font-sans min-h-screen" hx-headers='{"X-CSRF-Token": "{{.Context.CSRFToken}}"}'>
     <div class="flex min-h-screen">
         <aside class="w-60 bg-white border-r border-slate-200 py-6 fixed h-screen overflow-y-auto">
             <div class="px-6 pb-6 border-b border-slate-200 mb-4">
@@ -64,8 +104,20 @@ const baseTemplate = `<!DOCTYPE html>
                 </select>
             </div>
             {{end}}
+            {{if .Context.Org}}
+            <form method="get" action="/org/{{.Context.Org.Slug}}/search" class="px-6 pb-4 mb-2">
+                <label class="block text-xs text-slate-500 font-medium mb-1.5" for="search-box">Search</label>
+                <input type="search" id="search-box" name="q" placeholder="Name or path..." class="w-full px-3 py-2 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all bg-white">
+            </form>
+            {{end}}
             <nav class="px-3">
                 {{if .Context.Org}}
+                <a href="/org/{{.Context.Org.Slug}}" class="flex items-center gap-3 px-4 py-3 text-slate-500 rounded-lg font-medium text-sm hover:bg-slate-50 hover:text-slate-800 transition-all mb-1">
+                    <svg class="w-5 h-5 opacity-70" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M3 12l2-2m0 0l7-7 7 7M5 10v10a1 1 0 001 1h3m10-11l2 2m-2-2v10a1 1 0 01-1 1h-3m-6 0a1 1 0 001-1v-4a1 1 0 011-1h2a1 1 0 011 1v4a1 1 0 001 1m-6 0h6"></path>
+                    </svg>
+                    Dashboard
+                </a>
                 <a href="/org/{{.Context.Org.Slug}}/projects" class="flex items-center gap-3 px-4 py-3 text-slate-500 rounded-lg font-medium text-sm hover:bg-slate-50 hover:text-slate-800 transition-all mb-1">
                     <svg class="w-5 h-5 opacity-70" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                         <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M3 7v10a2 2 0 002 2h14a2 2 0 002-2V9a2 2 0 00-2-2h-6l-2-2H5a2 2 0 00-2 2z"></path>
@@ -94,8 +146,30 @@ const baseTemplate = `<!DOCTYPE html>
                     </svg>
                     Metadata
                 </a>
+                <a href="/org/{{.Context.Org.Slug}}/tfstate" class="flex items-center gap-3 px-4 py-3 text-slate-500 rounded-lg font-medium text-sm hover:bg-slate-50 hover:text-slate-800 transition-all mb-1">
+                    <svg class="w-5 h-5 opacity-70" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M9 12h6m-6 4h6m2 5H7a2 2 0 01-2-2V5a2 2 0 012-2h5.586a1 1 0 01.707.293l5.414 5.414a1 1 0 01.293.707V19a2 2 0 01-2 2z"></path>
+                    </svg>
+                    Terraform State
+                </a>
+                <a href="/org/{{.Context.Org.Slug}}/api-keys" class="flex items-center gap-3 px-4 py-3 text-slate-500 rounded-lg font-medium text-sm hover:bg-slate-50 hover:text-slate-800 transition-all mb-1">
+                    <svg class="w-5 h-5 opacity-70" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M15 7a2 2 0 012 2m4 0a6 6 0 01-7.743 5.743L11 17H9v2H7v2H4a1 1 0 01-1-1v-2.586a1 1 0 01.293-.707l5.964-5.964A6 6 0 1121 9z"></path>
+                    </svg>
+                    API Keys
+                </a>
                 {{end}}
             </nav>
+            {{if .Context.Org}}
+            <div class="px-6 py-4 border-t border-slate-200">
+                <label class="block text-xs text-slate-500 font-medium mb-1.5">Organization</label>
+                <p class="text-sm font-medium mb-2">{{.Context.Org.Name}}</p>
+                <form method="post" action="/logout">
+                    <input type="hidden" name="csrf_token" value="{{.Context.CSRFToken}}">
+                    <button type="submit" class="btn btn-secondary btn-sm">Sign out</button>
+                </form>
+            </div>
+            {{end}}
         </aside>
         <main class="flex-1 ml-60 p-8">
             <div id="content" class="max-w-6xl">
@@ -113,6 +187,61 @@ const baseTemplate = `<!DOCTYPE html>
     <style>
     #modal[style*="block"] { display: flex !important; }
     </style>
+    {{if .Context.Org}}
+    <script>
+        // Live updates: a tbody marked with data-live re-syncs its rows from data-live-url
+        // whenever a resource of its kind (and project or bucket, if set) changes
+        (function() {
+            if (!window.EventSource) return;
+            var timers = {};
+            var source = new EventSource('/org/{{.Context.Org.Slug}}/events');
+            source.addEventListener('change', function(e) {
+                var evt = JSON.parse(e.data);
+                document.querySelectorAll('tbody[data-live]').forEach(function(tbody) {
+                    if (tbody.dataset.live !== evt.kind) return;
+                    if (tbody.dataset.liveProject && tbody.dataset.liveProject !== evt.project_id) return;
+                    if (tbody.dataset.liveBucket && tbody.dataset.liveBucket !== evt.bucket_id) return;
+                    // Coalesce bursts of changes (e.g. a terraform apply) into one refresh
+                    clearTimeout(timers[tbody.id]);
+                    timers[tbody.id] = setTimeout(function() { syncRows(tbody); }, 250);
+                });
+            });
+
+            function syncRows(tbody) {
+                fetch(tbody.dataset.liveUrl, {credentials: 'same-origin'}).then(function(res) {
+                    return res.ok ? res.text() : null;
+                }).then(function(html) {
+                    var fresh = html && new DOMParser().parseFromString(html, 'text/html').getElementById(tbody.id);
+                    if (!fresh) return;
+                    var rows = Array.from(fresh.rows);
+                    var hasRowIDs = function(list) { return list.every(function(row) { return row.id; }); };
+                    if (!hasRowIDs(rows) || !hasRowIDs(Array.from(tbody.rows))) {
+                        // Switching to or from the "nothing found" row
+                        tbody.innerHTML = fresh.innerHTML;
+                        htmx.process(tbody);
+                        return;
+                    }
+                    var keep = {};
+                    rows.forEach(function(row, i) {
+                        keep[row.id] = true;
+                        var node = document.getElementById(row.id);
+                        if (node && node.outerHTML === row.outerHTML) {
+                            if (tbody.rows[i] !== node) tbody.insertBefore(node, tbody.rows[i] || null);
+                            return;
+                        }
+                        if (node) node.remove();
+                        node = document.importNode(row, true);
+                        tbody.insertBefore(node, tbody.rows[i] || null);
+                        htmx.process(node);
+                    });
+                    Array.from(tbody.rows).forEach(function(row) {
+                        if (!keep[row.id]) row.remove();
+                    });
+                });
+            }
+        })();
+    </script>
+    {{end}}
 
     <script>
         document.body.addEventListener('htmx:afterSwap', function(e) {
@@ -144,9 +273,9 @@ const projectsTemplate = `{{define "content"}}
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
             </tr>
         </thead>
-        <tbody>
+        <tbody id="project-rows" data-live="project" data-live-url="/org/{{.Context.Org.Slug}}/projects">
             {{range .Projects}}
-            <tr class="hover:bg-slate-50">
+            <tr class="hover:bg-slate-50" id="row-{{.ID}}">
                 <td class="px-6 py-4 border-b border-slate-100">
                     <a href="/org/{{$.Context.Org.Slug}}/projects/{{.Slug}}/instances" class="font-medium text-[#2878B5] hover:underline">{{.Slug}}</a>
                 </td>
@@ -239,9 +368,9 @@ const instancesTemplate = `{{define "content"}}
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
             </tr>
         </thead>
-        <tbody>
+        <tbody id="instance-rows" data-live="instance" data-live-project="{{.Context.Project.ID}}" data-live-url="/org/{{.Context.Org.Slug}}/projects/{{.Context.Project.Slug}}/instances">
             {{range .Instances}}
-            <tr class="hover:bg-slate-50">
+            <tr class="hover:bg-slate-50" id="row-{{.ID}}">
                 <td class="px-6 py-4 border-b border-slate-100">
                     <a href="#" hx-get="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/instances/{{.ID}}/edit" hx-target="#modal-content" class="font-medium text-[#2878B5] hover:underline">{{.Name}}</a>
                 </td>
@@ -294,7 +423,7 @@ const newInstanceFormTemplate = `
         <div class="mb-5">
             <label class="block text-sm font-medium mb-1.5" for="region">Region</label>
             <select id="region" name="region" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all bg-white">
-                {{range .Regions}}<option value="{{.}}">{{.}}</option>{{end}}
+                {{range .Regions}}<option value="{{.Name}}">{{.Name}}{{if .Down}} (down){{end}}</option>{{end}}
             </select>
         </div>
         <div class="grid grid-cols-2 gap-4 mb-5">
@@ -309,7 +438,7 @@ const newInstanceFormTemplate = `
         </div>
         <div class="mb-5">
             <label class="block text-sm font-medium mb-1.5" for="image">Image</label>
-            <input type="text" id="image" name="image" value="ubuntu:20.04" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+            <input type="text" id="image" name="image" value="ubuntu-24.04" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
         </div>
         <div class="mb-5">
             <label class="block text-sm font-medium mb-1.5" for="status">Initial Status</label>
@@ -391,12 +520,13 @@ const metadataTemplate = `{{define "content"}}
         <thead>
             <tr>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Path</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Size</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Value</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Updated At</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
             </tr>
         </thead>
-        <tbody>
+        <tbody id="metadata-rows" data-live="metadata" data-live-url="/org/{{.Context.Org.Slug}}/metadata?prefix={{.Prefix}}">
             {{range .Metadata}}
             <tr class="hover:bg-slate-50" id="row-{{.ID}}">
                 <td class="px-6 py-4 border-b border-slate-100"><code class="bg-slate-100 px-2 py-0.5 rounded text-sm">{{.Path}}</code></td>
@@ -483,11 +613,12 @@ const storageTemplate = `{{define "content"}}
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Bucket Name</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Created At</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Updated At</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
             </tr>
         </thead>
-        <tbody>
+        <tbody id="bucket-rows" data-live="bucket" data-live-project="{{.Context.Project.ID}}" data-live-url="/org/{{.Context.Org.Slug}}/projects/{{.Context.Project.Slug}}/storage">
             {{range .Buckets}}
-            <tr class="hover:bg-slate-50">
+            <tr class="hover:bg-slate-50" id="row-{{.ID}}">
                 <td class="px-6 py-4 border-b border-slate-100">
                     <a href="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{.Name}}" class="flex items-center gap-3 text-[#2878B5] hover:underline">
                         <svg class="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
@@ -498,10 +629,16 @@ const storageTemplate = `{{define "content"}}
                 </td>
                 <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.CreatedAt.Format "2006-01-02 15:04:05"}}</td>
                 <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.UpdatedAt.Format "2006-01-02 15:04:05"}}</td>
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <div class="flex gap-2">
+                        <button class="btn btn-secondary btn-sm" hx-get="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{.Name}}/edit" hx-target="#modal-content">Rename</button>
+                        <button class="btn btn-danger btn-sm" hx-delete="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{.Name}}" hx-target="closest tr" hx-confirm="Delete bucket {{.Name}} and all of its objects?">Delete</button>
+                    </div>
+                </td>
             </tr>
             {{else}}
             <tr>
-                <td colspan="3" class="px-6 py-8 text-center text-slate-500">No buckets found</td>
+                <td colspan="4" class="px-6 py-8 text-center text-slate-500">No buckets found</td>
             </tr>
             {{end}}
         </tbody>
@@ -528,6 +665,26 @@ const newBucketFormTemplate = `
     </div>
 </form>`
 
+const editBucketFormTemplate = `
+<div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
+    <h3 class="text-lg font-semibold">Rename Bucket</h3>
+    <button class="w-8 h-8 flex items-center justify-center rounded-lg text-slate-400 hover:bg-slate-100 hover:text-slate-600 transition-all" onclick="document.getElementById('modal').style.display='none'">&times;</button>
+</div>
+<form hx-put="/org/{{.Org.Slug}}/projects/{{.Project.Slug}}/storage/{{.Bucket.Name}}" hx-target="#content" hx-on::after-request="if(event.detail.xhr.status >= 200 && event.detail.xhr.status < 300) document.getElementById('modal').style.display='none'">
+    <div class="p-6">
+        <div id="form-error" class="mb-4"></div>
+        <div class="mb-5">
+            <label class="block text-sm font-medium mb-1.5" for="name">Bucket Name</label>
+            <input type="text" id="name" name="name" value="{{.Bucket.Name}}" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+            <p class="text-xs text-slate-400 mt-1">Objects, lifecycle rules, quotas and notifications move to the new name. Presigned URLs for the old name stop working.</p>
+        </div>
+    </div>
+    <div class="px-6 py-4 border-t border-slate-200 flex justify-end gap-3 bg-slate-50">
+        <button type="button" class="btn btn-secondary" onclick="document.getElementById('modal').style.display='none'">Cancel</button>
+        <button type="submit" class="btn btn-primary">Rename Bucket</button>
+    </div>
+</form>`
+
 const bucketObjectsTemplate = `{{define "content"}}
 <div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
     <div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
@@ -540,7 +697,7 @@ const bucketObjectsTemplate = `{{define "content"}}
             </a>
             <h2 class="text-lg font-semibold">{{.Bucket.Name}}</h2>
         </div>
-        <button class="btn btn-primary" hx-get="/org/{{.Context.Org.Slug}}/projects/{{.Context.Project.Slug}}/storage/{{.Bucket.Name}}/objects/new" hx-target="#modal-content">
+        <button class="btn btn-primary" hx-get="/org/{{.Context.Org.Slug}}/projects/{{.Context.Project.Slug}}/storage/{{.Bucket.Name}}/objects/new?prefix={{.Prefix}}" hx-target="#modal-content">
             <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                 <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 16v1a3 3 0 003 3h10a3 3 0 003-3v-1m-4-8l-4-4m0 0L8 8m4-4v12"></path>
             </svg>
@@ -557,13 +714,14 @@ const bucketObjectsTemplate = `{{define "content"}}
         <thead>
             <tr>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Path</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Size</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Updated At</th>
                 <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
             </tr>
         </thead>
-        <tbody>
+        <tbody id="object-rows" data-live="object" data-live-bucket="{{.Bucket.ID}}" data-live-url="/org/{{.Context.Org.Slug}}/projects/{{.Context.Project.Slug}}/storage/{{.Bucket.Name}}?prefix={{.Prefix}}">
             {{range .Objects}}
-            <tr class="hover:bg-slate-50">
+            <tr class="hover:bg-slate-50" id="row-{{.ID}}">
                 <td class="px-6 py-4 border-b border-slate-100">
                     <div class="flex items-center gap-3">
                         <svg class="w-4 h-4 text-slate-400" fill="none" stroke="currentColor" viewBox="0 0 24 24">
@@ -572,14 +730,19 @@ const bucketObjectsTemplate = `{{define "content"}}
                         <code class="bg-slate-100 px-2 py-0.5 rounded text-sm">{{.Path}}</code>
                     </div>
                 </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.Size}} bytes</td>
                 <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.UpdatedAt.Format "2006-01-02 15:04:05"}}</td>
                 <td class="px-6 py-4 border-b border-slate-100">
-                    <button class="btn btn-secondary btn-sm" hx-get="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{$.Bucket.Name}}/objects/{{.ID}}" hx-target="#modal-content">View</button>
+                    <div class="flex gap-2">
+                        <button class="btn btn-secondary btn-sm" hx-get="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{$.Bucket.Name}}/objects/{{.ID}}" hx-target="#modal-content">View</button>
+                        <a class="btn btn-secondary btn-sm" href="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{$.Bucket.Name}}/objects/{{.ID}}/download">Download</a>
+                        <button class="btn btn-danger btn-sm" hx-delete="/org/{{$.Context.Org.Slug}}/projects/{{$.Context.Project.Slug}}/storage/{{$.Bucket.Name}}/objects/{{.ID}}" hx-target="closest tr" hx-confirm="Are you sure you want to delete this object?">Delete</button>
+                    </div>
                 </td>
             </tr>
             {{else}}
             <tr>
-                <td colspan="3" class="px-6 py-8 text-center text-slate-500">No objects found</td>
+                <td colspan="4" class="px-6 py-8 text-center text-slate-500">No objects found</td>
             </tr>
             {{end}}
         </tbody>
@@ -592,18 +755,18 @@ const newObjectFormTemplate = `
     <h3 class="text-lg font-semibold">Upload Object to {{.Bucket.Name}}</h3>
     <button class="w-8 h-8 flex items-center justify-center rounded-lg text-slate-400 hover:bg-slate-100 hover:text-slate-600 transition-all" onclick="document.getElementById('modal').style.display='none'">&times;</button>
 </div>
-<form hx-post="/org/{{.Org.Slug}}/projects/{{.Project.Slug}}/storage/{{.Bucket.Name}}/objects" hx-target="#content" hx-on::after-request="if(event.detail.xhr.status >= 200 && event.detail.xhr.status < 300) document.getElementById('modal').style.display='none'">
+<form hx-post="/org/{{.Org.Slug}}/projects/{{.Project.Slug}}/storage/{{.Bucket.Name}}/objects" hx-encoding="multipart/form-data" hx-target="#content" hx-on::after-request="if(event.detail.xhr.status >= 200 && event.detail.xhr.status < 300) document.getElementById('modal').style.display='none'">
     <div class="p-6">
         <div id="form-error" class="mb-4"></div>
         <div class="mb-5">
-            <label class="block text-sm font-medium mb-1.5" for="path">Object Path</label>
-            <input type="text" id="path" name="path" placeholder="folder/file.txt" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+            <label class="block text-sm font-medium mb-1.5" for="file">File</label>
+            <input type="file" id="file" name="file" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
         </div>
         <div class="mb-5">
-            <label class="block text-sm font-medium mb-1.5" for="content">Content</label>
-            <textarea id="content" name="content" rows="8" placeholder="Enter file content..." required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all resize-none font-mono"></textarea>
+            <label class="block text-sm font-medium mb-1.5" for="path">Object Path</label>
+            <input type="text" id="path" name="path" value="{{.Prefix}}" placeholder="folder/file.txt" class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+            <p class="text-xs text-slate-400 mt-1">Leave empty, or end with a slash, to use the file name.</p>
         </div>
-        <p class="text-xs text-slate-500">The content will be base64-encoded and stored.</p>
     </div>
     <div class="px-6 py-4 border-t border-slate-200 flex justify-end gap-3 bg-slate-50">
         <button type="button" class="btn btn-secondary" onclick="document.getElementById('modal').style.display='none'">Cancel</button>
@@ -622,12 +785,546 @@ const viewObjectTemplate = `
             <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Size</span>
             <span class="font-medium">{{.Size}} bytes</span>
         </div>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Content Type</span>
+            <span class="font-medium">{{.ContentType}}</span>
+        </div>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Version</span>
+            <span class="font-medium">{{.Object.Version}}</span>
+        </div>
     </div>
     <div>
+        {{if .ImageURL}}
+        <span class="block text-xs uppercase tracking-wider text-slate-500 mb-2">Preview</span>
+        <div class="bg-slate-50 border border-slate-200 rounded-lg p-4 flex justify-center">
+            <img src="{{.ImageURL}}" alt="{{.Object.Path}}" class="max-h-[50vh]">
+        </div>
+        {{else if .Hex}}
+        <span class="block text-xs uppercase tracking-wider text-slate-500 mb-2">Content (hex{{if .Truncated}}, first {{.Truncated}} bytes{{end}})</span>
+        <pre class="bg-slate-50 border border-slate-200 rounded-lg p-4 overflow-auto max-h-[50vh] text-sm font-mono">{{.Hex}}</pre>
+        {{else}}
         <span class="block text-xs uppercase tracking-wider text-slate-500 mb-2">Content</span>
-        <pre class="bg-slate-50 border border-slate-200 rounded-lg p-4 overflow-auto max-h-[50vh] text-sm font-mono whitespace-pre-wrap break-words">{{.DecodedContent}}</pre>
+        <pre class="bg-slate-50 border border-slate-200 rounded-lg p-4 overflow-auto max-h-[50vh] text-sm font-mono whitespace-pre-wrap break-words">{{.Text}}</pre>
+        {{end}}
     </div>
 </div>
-<div class="px-6 py-4 border-t border-slate-200 flex justify-end bg-slate-50">
+<div class="px-6 py-4 border-t border-slate-200 flex justify-end gap-3 bg-slate-50">
+    <a class="btn btn-secondary" href="/org/{{.Org.Slug}}/projects/{{.Project.Slug}}/storage/{{.Bucket.Name}}/objects/{{.Object.ID}}/download">Download</a>
     <button class="btn btn-secondary" onclick="document.getElementById('modal').style.display='none'">Close</button>
 </div>`
+
+const apiKeysTemplate = `{{define "content"}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+    <div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
+        <h2 class="text-lg font-semibold">API Keys</h2>
+        <button class="btn btn-primary" hx-get="/org/{{.Context.Org.Slug}}/api-keys/new" hx-target="#modal-content">
+            <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 4v16m8-8H4"></path>
+            </svg>
+            New API Key
+        </button>
+    </div>
+    <div id="form-error" class="px-6 py-0"></div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Name</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">ID</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Access</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Expires</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Created At</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Last Used</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Actions</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Keys}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100 font-medium">
+                    {{.Name}}
+                    {{if eq .ID $.CurrentKeyID}}<span class="inline-flex items-center px-2.5 py-1 rounded-full text-xs font-medium bg-emerald-50 text-emerald-600">This session</span>{{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500"><code class="text-sm">{{.ID}}</code></td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500 text-sm">
+                    {{if .Scopes}}{{range .Scopes}}<code class="block">{{.}}</code>{{end}}{{else}}Full access{{end}}
+                    {{if .ProjectIDs}}<div class="mt-1">{{len .ProjectIDs}} project(s)</div>{{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .ExpiresAt}}{{.ExpiresAt.Format "2006-01-02 15:04:05"}}{{else}}Never{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">
+                    {{.CreatedAt.Format "2006-01-02 15:04:05"}}
+                    {{if .RotatedAt}}<div class="text-xs mt-1">Rotated {{.RotatedAt.Format "2006-01-02 15:04:05"}}</div>{{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .LastUsedAt}}{{.LastUsedAt.Format "2006-01-02 15:04:05"}}{{else}}Never{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <button class="btn btn-secondary btn-sm" hx-post="/org/{{$.Context.Org.Slug}}/api-keys/{{.ID}}/rotate" hx-target="#modal-content" hx-confirm="Issue a new token for {{.Name}}? The current token keeps working for one hour.">Rotate</button>
+                    <button class="btn btn-danger btn-sm" hx-delete="/org/{{$.Context.Org.Slug}}/api-keys/{{.ID}}" hx-target="closest tr" hx-confirm="{{if eq .ID $.CurrentKeyID}}This is the key you signed in with. Revoking it signs you out. Continue?{{else}}Revoke API key {{.Name}}? Anything using it will stop working.{{end}}">Revoke</button>
+                </td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="7" class="px-6 py-8 text-center text-slate-500">No API keys found</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}`
+
+const newAPIKeyFormTemplate = `
+<div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
+    <h3 class="text-lg font-semibold">New API Key</h3>
+    <button class="w-8 h-8 flex items-center justify-center rounded-lg text-slate-400 hover:bg-slate-100 hover:text-slate-600 transition-all" onclick="document.getElementById('modal').style.display='none'">&times;</button>
+</div>
+<form hx-post="/org/{{.Org.Slug}}/api-keys" hx-target="#modal-content">
+    <div class="p-6">
+        <div id="form-error" class="mb-4"></div>
+        <div class="mb-5">
+            <label class="block text-sm font-medium mb-1.5" for="name">Name</label>
+            <input type="text" id="name" name="name" placeholder="ci-pipeline" required class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+        </div>
+        <div class="mb-5">
+            <label class="block text-sm font-medium mb-1.5">Scopes</label>
+            <p class="text-xs text-slate-500 mb-2">Leave all unchecked for full access. Write scopes include read.</p>
+            <div class="grid grid-cols-2 gap-2">
+                {{range .Scopes}}
+                <label class="flex items-center gap-2 text-sm"><input type="checkbox" name="scopes" value="{{.}}"> <code>{{.}}</code></label>
+                {{end}}
+            </div>
+        </div>
+        {{if .Projects}}
+        <div class="mb-5">
+            <label class="block text-sm font-medium mb-1.5">Projects</label>
+            <p class="text-xs text-slate-500 mb-2">Leave all unchecked to allow every project.</p>
+            <div class="grid grid-cols-2 gap-2">
+                {{range .Projects}}
+                <label class="flex items-center gap-2 text-sm"><input type="checkbox" name="projects" value="{{.ID}}"> {{.Name}}</label>
+                {{end}}
+            </div>
+        </div>
+        {{end}}
+        <div class="mb-5">
+            <label class="block text-sm font-medium mb-1.5" for="expires_at">Expires</label>
+            <input type="date" id="expires_at" name="expires_at" class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+        </div>
+        <p class="text-xs text-slate-500">Keys with scopes or projects can only be used with the API, not to sign in to the console.</p>
+    </div>
+    <div class="px-6 py-4 border-t border-slate-200 flex justify-end gap-3 bg-slate-50">
+        <button type="button" class="btn btn-secondary" onclick="document.getElementById('modal').style.display='none'">Cancel</button>
+        <button type="submit" class="btn btn-primary">Create API Key</button>
+    </div>
+</form>`
+
+const apiKeyCreatedTemplate = `
+<div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
+    <h3 class="text-lg font-semibold">{{if .Rotated}}API Key Rotated{{else}}API Key Created{{end}}</h3>
+    <button class="w-8 h-8 flex items-center justify-center rounded-lg text-slate-400 hover:bg-slate-100 hover:text-slate-600 transition-all" onclick="window.location.reload()">&times;</button>
+</div>
+<div class="p-6">
+    <p class="text-sm mb-4">Copy the {{if .Rotated}}new {{end}}token for <span class="font-medium">{{.Key.Name}}</span> now. It is only shown once and cannot be recovered.</p>
+    {{if .Rotated}}<p class="text-sm text-slate-500 mb-4">The previous token keeps working until {{.Key.PreviousTokenExpiresAt.Format "2006-01-02 15:04:05"}}.</p>{{end}}
+    <div class="flex items-center gap-2">
+        <input type="text" id="api-key-token" value="{{.Key.Token}}" readonly onclick="this.select()" class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg bg-slate-50 font-mono">
+        <button type="button" class="btn btn-secondary" onclick="navigator.clipboard.writeText(document.getElementById('api-key-token').value); this.textContent='Copied'">Copy</button>
+    </div>
+</div>
+<div class="px-6 py-4 border-t border-slate-200 flex justify-end bg-slate-50">
+    <button class="btn btn-primary" onclick="window.location.reload()">Done</button>
+</div>`
+
+const tfStatesTemplate = `{{define "content"}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Terraform State</h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">State</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Serial</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Lineage</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Terraform</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Resources</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Lock</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Updated At</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .States}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <a href="/org/{{$.Context.Org.Slug}}/tfstate/{{.ID}}" class="font-medium text-[#2878B5] hover:underline">{{.ID}}</a>
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100">{{if .Lineage}}{{.Serial}}{{else}}-{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500 max-w-xs truncate"><code class="text-sm">{{if .Lineage}}{{.Lineage}}{{else}}-{{end}}</code></td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .TerraformVersion}}{{.TerraformVersion}}{{else}}-{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100">{{.Resources}}</td>
+                <td class="px-6 py-4 border-b border-slate-100">
+                    {{if .Lock}}
+                    <span class="inline-flex items-center gap-1.5 px-2.5 py-1 rounded-full text-xs font-medium bg-red-50 text-red-600">
+                        <span class="w-1.5 h-1.5 rounded-full bg-red-500"></span>
+                        Locked{{if .Lock.Who}} by {{.Lock.Who}}{{end}}
+                    </span>
+                    {{else}}
+                    <span class="inline-flex items-center gap-1.5 px-2.5 py-1 rounded-full text-xs font-medium bg-emerald-50 text-emerald-600">
+                        <span class="w-1.5 h-1.5 rounded-full bg-emerald-500"></span>
+                        Unlocked
+                    </span>
+                    {{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .Size}}{{.UpdatedAt.Format "2006-01-02 15:04:05"}}{{else}}-{{end}}</td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="7" class="px-6 py-8 text-center text-slate-500">No Terraform states found. Point Terraform's http backend at <code>/v1/orgs/{{.Context.Org.Slug}}/tfstate/{name}</code> to store one here.</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}`
+
+const tfStateTemplate = `{{define "content"}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200 flex justify-between items-center">
+        <div class="flex items-center gap-3">
+            <a href="/org/{{.Context.Org.Slug}}/tfstate" class="btn btn-secondary btn-sm">
+                <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M10 19l-7-7m0 0l7-7m-7 7h18"></path>
+                </svg>
+                Back
+            </a>
+            <h2 class="text-lg font-semibold">{{.ID}}</h2>
+        </div>
+        <div class="flex gap-2">
+            {{if .Lock}}
+            <button class="btn btn-danger" hx-post="/org/{{.Context.Org.Slug}}/tfstate/{{.ID}}/unlock" hx-confirm="Force-unlock {{.ID}}? Only do this if no Terraform run is using the state.">Force Unlock</button>
+            {{end}}
+            {{if .Exists}}
+            <a class="btn btn-secondary" href="/org/{{.Context.Org.Slug}}/tfstate/{{.ID}}/download">Download</a>
+            {{end}}
+        </div>
+    </div>
+    <div class="p-6">
+        <div id="form-error" class="mb-4"></div>
+        <div class="flex gap-6 mb-4">
+            {{with .State}}
+            <div>
+                <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Serial</span>
+                <span class="font-medium">{{.Serial}}</span>
+            </div>
+            <div>
+                <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Lineage</span>
+                <code class="text-sm">{{.Lineage}}</code>
+            </div>
+            <div>
+                <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Terraform</span>
+                <span class="font-medium">{{.TerraformVersion}}</span>
+            </div>
+            {{end}}
+            <div>
+                <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Lock</span>
+                {{if .Lock}}
+                <span class="inline-flex items-center gap-1.5 px-2.5 py-1 rounded-full text-xs font-medium bg-red-50 text-red-600">
+                    <span class="w-1.5 h-1.5 rounded-full bg-red-500"></span>
+                    Locked
+                </span>
+                {{else}}
+                <span class="inline-flex items-center gap-1.5 px-2.5 py-1 rounded-full text-xs font-medium bg-emerald-50 text-emerald-600">
+                    <span class="w-1.5 h-1.5 rounded-full bg-emerald-500"></span>
+                    Unlocked
+                </span>
+                {{end}}
+            </div>
+        </div>
+        {{with .Lock}}
+        <div class="p-3 bg-red-50 border border-red-200 rounded-lg text-sm text-red-700 mb-4">
+            {{if .ID}}Lock <code>{{.ID}}</code>{{else}}Locked{{end}}{{if .Operation}} for {{.Operation}}{{end}}{{if .Who}} by {{.Who}}{{end}}{{if not .Created.IsZero}} since {{.Created.Format "2006-01-02 15:04:05"}}{{end}}{{if .Info}} - {{.Info}}{{end}}
+        </div>
+        {{end}}
+        {{if not .Exists}}
+        <p class="text-sm text-slate-500">This state is locked but has not been written yet.</p>
+        {{end}}
+        {{with .ParseError}}
+        <p class="text-sm text-red-700">The state is not valid Terraform state JSON: {{.}}</p>
+        {{end}}
+    </div>
+</div>
+
+{{with .State}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Resources</h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Address</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Provider</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Instances</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Resources}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100"><code class="bg-slate-100 px-2 py-0.5 rounded text-sm">{{.Address}}</code></td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.Provider}}</td>
+                <td class="px-6 py-4 border-b border-slate-100">{{len .Instances}}</td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="3" class="px-6 py-8 text-center text-slate-500">No resources</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}
+
+{{if .Outputs}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Outputs</h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Name</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Value</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Type</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Outputs}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100 font-medium">{{.Name}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 max-w-xs truncate">{{if .Sensitive}}<span class="text-slate-500">(sensitive)</span>{{else}}<code class="text-sm">{{.Value}}</code>{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500"><code class="text-sm">{{.Type}}</code></td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}
+
+{{with .JSON}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">State JSON</h2>
+    </div>
+    <div class="p-6">
+        <pre class="bg-slate-50 border border-slate-200 rounded-lg p-4 overflow-auto max-h-[50vh] text-sm font-mono">{{.}}</pre>
+    </div>
+</div>
+{{end}}
+{{end}}`
+
+const searchTemplate = `{{define "content"}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Search</h2>
+    </div>
+    <div class="px-6 py-4 border-b border-slate-200">
+        <form method="get" action="/org/{{.Context.Org.Slug}}/search" class="max-w-sm">
+            <label class="block text-sm font-medium mb-1.5" for="search-query">Projects, instances, buckets, objects and metadata</label>
+            <input type="search" id="search-query" name="q" value="{{.Query}}" placeholder="Name or path..." autofocus class="w-full px-3.5 py-2.5 text-sm border border-slate-200 rounded-lg focus:outline-none focus:border-[#2878B5] focus:ring-2 focus:ring-[#2878B5]/10 transition-all">
+        </form>
+        {{if .Error}}<p class="text-sm text-red-600 mt-1">{{.Error}}</p>{{end}}
+    </div>
+    {{if .Query}}
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Name</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Type</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Project</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Results}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <a href="{{.URL}}" class="font-medium text-[#2878B5] hover:underline">{{.Name}}</a>
+                    {{if eq .Kind "object"}}<span class="text-slate-500 text-sm">in {{.BucketID}}</span>{{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <span class="inline-flex items-center px-2.5 py-1 rounded-full text-xs font-medium bg-slate-100 text-slate-600">{{.Kind}}</span>
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .ProjectSlug}}{{.ProjectSlug}}{{else}}-{{end}}</td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="3" class="px-6 py-8 text-center text-slate-500">No resources match "{{.Query}}"</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{end}}
+</div>
+{{end}}`
+
+const dashboardTemplate = `{{define "content"}}
+{{with .Summary}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">{{$.Context.Org.Name}}</h2>
+    </div>
+    <div class="p-6 flex gap-6">
+        <a href="/org/{{$.Context.Org.Slug}}/projects">
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Projects</span>
+            <span class="text-2xl font-semibold">{{.Projects}}</span>
+        </a>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Instances</span>
+            <span class="text-2xl font-semibold">{{.Instances.Total}}</span>
+        </div>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Buckets</span>
+            <span class="text-2xl font-semibold">{{.Buckets}}</span>
+        </div>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Objects</span>
+            <span class="text-2xl font-semibold">{{.Storage.Objects}}</span>
+        </div>
+        <div>
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Stored</span>
+            <span class="text-2xl font-semibold">{{.Storage.Bytes}}</span> <span class="text-slate-500">bytes</span>
+        </div>
+        <a href="/org/{{$.Context.Org.Slug}}/tfstate">
+            <span class="block text-xs uppercase tracking-wider text-slate-500 mb-1">Terraform States</span>
+            <span class="text-2xl font-semibold">{{.TFStates}}</span>
+        </a>
+    </div>
+</div>
+
+{{if .Instances.Total}}
+<div class="grid grid-cols-2 gap-4 mb-5">
+    <div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+        <table class="w-full">
+            <thead>
+                <tr>
+                    <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Region</th>
+                    <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Instances</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range $region, $count := .Instances.ByRegion}}
+                <tr class="hover:bg-slate-50">
+                    <td class="px-6 py-4 border-b border-slate-100">{{$region}}</td>
+                    <td class="px-6 py-4 border-b border-slate-100">{{$count}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+    </div>
+    <div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+        <table class="w-full">
+            <thead>
+                <tr>
+                    <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Status</th>
+                    <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Instances</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range $status, $count := .Instances.ByStatus}}
+                <tr class="hover:bg-slate-50">
+                    <td class="px-6 py-4 border-b border-slate-100">{{$status}}</td>
+                    <td class="px-6 py-4 border-b border-slate-100">{{$count}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+    </div>
+</div>
+{{end}}
+
+{{if .TFStateLocks}}
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Held State Locks</h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">State</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Held By</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Operation</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Since</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .TFStateLocks}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <a href="/org/{{$.Context.Org.Slug}}/tfstate/{{.ID}}" class="font-medium text-[#2878B5] hover:underline">{{.ID}}</a>
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100">{{if .Lock.Who}}{{.Lock.Who}}{{else}}-{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .Lock.Operation}}{{.Lock.Operation}}{{else}}-{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .Lock.Created.IsZero}}-{{else}}{{.Lock.Created.Format "2006-01-02 15:04:05"}}{{end}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}
+
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden mb-5">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold">Recent Changes</h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Name</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Type</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Project</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Updated At</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .RecentChanges}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <a href="{{.URL}}" class="font-medium text-[#2878B5] hover:underline">{{.Name}}</a>
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <span class="inline-flex items-center px-2.5 py-1 rounded-full text-xs font-medium bg-slate-100 text-slate-600">{{.Kind}}</span>
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .ProjectSlug}}{{.ProjectSlug}}{{else}}-{{end}}</td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{.UpdatedAt.Format "2006-01-02 15:04:05"}}</td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="4" class="px-6 py-8 text-center text-slate-500">Nothing here yet</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+
+<div class="bg-white rounded-xl shadow-sm border border-slate-200 overflow-hidden">
+    <div class="px-6 py-5 border-b border-slate-200">
+        <h2 class="text-lg font-semibold"><a href="/org/{{$.Context.Org.Slug}}/api-keys" class="hover:underline">API Keys</a></h2>
+    </div>
+    <table class="w-full">
+        <thead>
+            <tr>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Name</th>
+                <th class="text-left px-6 py-3 text-xs font-semibold uppercase tracking-wider text-slate-500 bg-slate-50 border-b border-slate-200">Last Used</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .APIKeys}}
+            <tr class="hover:bg-slate-50">
+                <td class="px-6 py-4 border-b border-slate-100">
+                    <span class="font-medium">{{if .Name}}{{.Name}}{{else}}-{{end}}</span>
+                    {{if eq .ID $.CurrentKeyID}}<span class="inline-flex items-center px-2.5 py-1 rounded-full text-xs font-medium bg-emerald-50 text-emerald-600">This session</span>{{end}}
+                </td>
+                <td class="px-6 py-4 border-b border-slate-100 text-slate-500">{{if .LastUsedAt}}{{.LastUsedAt.Format "2006-01-02 15:04:05"}}{{else}}Never{{end}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</div>
+{{end}}
+{{end}}`
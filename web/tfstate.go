@@ -0,0 +1,171 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/hypertf/nahcloud/domain"
+	"github.com/hypertf/nahcloud/web/static"
+)
+
+// tfStateOutputView is a state output prepared for display
+type tfStateOutputView struct {
+	Name      string
+	Value     string
+	Type      string
+	Sensitive bool
+}
+
+// ListTFStates handles GET /org/{org}/tfstate
+func (h *Handler) ListTFStates(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, err := h.getPageContext(r, org, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	states, err := h.service.ListTFStates(org.ID)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("tfstates").Parse(baseTemplate + tfStatesTemplate))
+	tmpl.Execute(w, map[string]interface{}{
+		"CSS":     template.CSS(static.CSS),
+		"Context": ctx,
+		"States":  states,
+	})
+}
+
+// ViewTFState handles GET /org/{org}/tfstate/{id}
+// A state that is locked but not written yet is shown with only its lock
+func (h *Handler) ViewTFState(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	raw, err := h.service.GetTFState(org.ID, id)
+	if err != nil && !domain.IsNotFound(err) {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rawLock, lock, lockErr := h.service.GetTFStateLock(org.ID, id)
+	if lockErr != nil && !domain.IsNotFound(lockErr) {
+		h.renderError(w, lockErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil && lockErr != nil {
+		h.renderError(w, domain.NotFoundError("tfstate", id).Error(), http.StatusNotFound)
+		return
+	}
+	if lockErr == nil && lock == nil {
+		// Lock payload isn't valid JSON, show it as-is
+		lock = &domain.TFStateLock{Info: rawLock}
+	}
+
+	ctx, err := h.getPageContext(r, org, nil)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"CSS":     template.CSS(static.CSS),
+		"Context": ctx,
+		"ID":      id,
+		"Lock":    lock,
+		"Exists":  raw != "",
+	}
+	if raw != "" {
+		var state domain.TFState
+		if err := json.Unmarshal([]byte(raw), &state); err != nil {
+			data["ParseError"] = err.Error()
+		} else {
+			data["State"] = state
+			data["Outputs"] = tfStateOutputs(state)
+		}
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(raw), "", "  "); err != nil {
+			data["JSON"] = raw
+		} else {
+			data["JSON"] = pretty.String()
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl := template.Must(template.New("tfstate").Parse(baseTemplate + tfStateTemplate))
+	tmpl.Execute(w, data)
+}
+
+// DownloadTFState handles GET /org/{org}/tfstate/{id}/download
+func (h *Handler) DownloadTFState(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	raw, err := h.service.GetTFState(org.ID, id)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": id + ".tfstate"}))
+	w.Write([]byte(raw))
+}
+
+// UnlockTFState handles POST /org/{org}/tfstate/{id}/unlock
+// This is the console's "terraform force-unlock": it removes the lock whoever holds it
+func (h *Handler) UnlockTFState(w http.ResponseWriter, r *http.Request) {
+	org, err := h.resolveOrg(r)
+	if err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if _, _, err := h.service.UnlockTFState(org.ID, id); err != nil {
+		h.renderFormError(w, err.Error())
+		return
+	}
+
+	redirect(w, r, "/org/"+org.Slug+"/tfstate/"+id)
+}
+
+// tfStateOutputs returns the outputs of a state sorted by name, with values rendered as JSON
+func tfStateOutputs(state domain.TFState) []tfStateOutputView {
+	outputs := make([]tfStateOutputView, 0, len(state.Outputs))
+	for name, output := range state.Outputs {
+		view := tfStateOutputView{Name: name, Sensitive: output.Sensitive}
+		if value, err := json.Marshal(output.Value); err == nil {
+			view.Value = string(value)
+		}
+		if output.Type != nil {
+			if typ, err := json.Marshal(output.Type); err == nil {
+				view.Type = string(typ)
+			}
+		}
+		outputs = append(outputs, view)
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs
+}